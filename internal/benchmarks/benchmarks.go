@@ -0,0 +1,166 @@
+// Package benchmarks describes the series gold savings are compared against and
+// how money placed in each of them grows between two dates.
+package benchmarks
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+const (
+	BIRate = "bi_rate"
+	CPI    = "cpi"
+	LQ45   = "lq45"
+	USDIDR = "usd_idr"
+)
+
+// Benchmark is a series money could have been placed in instead of gold. The value
+// of a rate benchmark is an interest rate in percent a year, effective from its date
+// until the next value; every other value is a price or index level.
+type Benchmark struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Rate        bool   `json:"rate"`
+}
+
+// All lists the supported benchmarks
+var All = []Benchmark{
+	{Key: BIRate, Name: "BI rate deposit", Description: "Deposit earning the Bank Indonesia policy rate, compounded daily before tax", Rate: true},
+	{Key: CPI, Name: "Inflation (CPI)", Description: "Money keeping pace with the consumer price index"},
+	{Key: LQ45, Name: "LQ45", Description: "Index fund tracking the LQ45 stock index, without dividends"},
+	{Key: USDIDR, Name: "US dollar", Description: "Rupiah exchanged for US dollars"},
+}
+
+// Find returns the benchmark with the given key
+func Find(key string) (Benchmark, bool) {
+	for _, benchmark := range All {
+		if benchmark.Key == key {
+			return benchmark, true
+		}
+	}
+	return Benchmark{}, false
+}
+
+// Point is the value of a benchmark on a day
+type Point struct {
+	Date  time.Time
+	Value decimal.Decimal
+}
+
+// Value is an imported point of a benchmark
+type Value struct {
+	Benchmark string
+	Point
+}
+
+// Growth returns how much one rupiah placed in the benchmark on from is worth on to,
+// using points ordered by date. Each day uses the latest point on or before it, so a
+// series must start on or before from; after its last point the last value holds.
+func Growth(benchmark Benchmark, points []Point, from, to time.Time) (float64, error) {
+	if to.Before(from) {
+		return 0, errors.New("benchmarks: end date is before start date")
+	}
+
+	start := latest(points, from)
+	if start < 0 {
+		return 0, fmt.Errorf("no %s value on or before %s", benchmark.Key, from.Format("2006-01-02"))
+	}
+
+	if !benchmark.Rate {
+		startValue := points[start].Value.Float64()
+		if startValue <= 0 {
+			return 0, fmt.Errorf("%s value on %s must be positive", benchmark.Key, points[start].Date.Format("2006-01-02"))
+		}
+		return points[latest(points, to)].Value.Float64() / startValue, nil
+	}
+
+	// Compound daily at the rate in effect, one segment per rate change
+	growth := 1.0
+	day := from
+	for i := start; i < len(points) && day.Before(to); i++ {
+		segmentEnd := to
+		if i+1 < len(points) && points[i+1].Date.Before(to) {
+			segmentEnd = points[i+1].Date
+		}
+		days := segmentEnd.Sub(day).Hours() / 24
+		if days > 0 {
+			growth *= math.Pow(1+points[i].Value.Float64()/100/365, days)
+			day = segmentEnd
+		}
+	}
+
+	return growth, nil
+}
+
+// latest returns the index of the last point on or before day, -1 when there is none
+func latest(points []Point, day time.Time) int {
+	return sort.Search(len(points), func(i int) bool { return points[i].Date.After(day) }) - 1
+}
+
+// ParseCSV reads values from a file with the header date,benchmark,value, e.g.
+// "2024-01-02,bi_rate,6.00". Columns may be in any order and ";" may separate them.
+func ParseCSV(r io.Reader) ([]Value, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimPrefix(string(content), "\uFEFF")
+	cr := csv.NewReader(strings.NewReader(text))
+	if firstLine := strings.SplitN(text, "\n", 2)[0]; strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		cr.Comma = ';'
+	}
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+	if len(records) < 2 {
+		return nil, errors.New("CSV file has no values")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "benchmark", "value"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("CSV file is missing column: " + name)
+		}
+	}
+
+	values := make([]Value, 0, len(records)-1)
+	for i, record := range records[1:] {
+		// Row numbers match the spreadsheet, where the header is row 1
+		line := i + 2
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date format, use YYYY-MM-DD", line)
+		}
+
+		key := strings.ToLower(strings.TrimSpace(record[columns["benchmark"]]))
+		benchmark, ok := Find(key)
+		if !ok {
+			return nil, fmt.Errorf("row %d: unknown benchmark %q", line, key)
+		}
+
+		value, err := decimal.Parse(record[columns["value"]])
+		if err != nil || value.IsNegative() || (!benchmark.Rate && value.IsZero()) {
+			return nil, fmt.Errorf("row %d: invalid %s value", line, key)
+		}
+
+		values = append(values, Value{Benchmark: key, Point: Point{Date: date, Value: value}})
+	}
+
+	return values, nil
+}
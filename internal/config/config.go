@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -10,8 +11,9 @@ import (
 
 type Config struct {
 	// Server
-	Port string
-	Env  string
+	Port       string
+	Env        string
+	APIBaseURL string
 
 	// Database
 	DatabaseURL string
@@ -38,6 +40,32 @@ type Config struct {
 	SMTPUsername string
 	SMTPPassword string
 	EmailFrom    string
+
+	// Account deletion
+	AccountDeletionGracePeriod time.Duration
+
+	// Data export
+	ExportLinkExpiry time.Duration
+
+	// Admin
+	AdminEmails []string
+
+	// Exchange rates
+	ExchangeRateProvider     string
+	ExchangeRateAPIURL       string
+	ExchangeRateFixturesPath string
+	ExchangeRateSyncInterval time.Duration
+
+	// Push notifications
+	PushProvider       string
+	FCMCredentialsFile string
+	APNsKeyFile        string
+	APNsKeyID          string
+	APNsTeamID         string
+	APNsTopic          string
+	APNsProduction     bool
+	VAPIDPrivateKey    string
+	VAPIDSubject       string
 }
 
 func Load() *Config {
@@ -56,9 +84,25 @@ func Load() *Config {
 		refreshExpiry = 168 * time.Hour
 	}
 
+	deletionGracePeriod, err := time.ParseDuration(getEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h"))
+	if err != nil {
+		deletionGracePeriod = 30 * 24 * time.Hour
+	}
+
+	exportLinkExpiry, err := time.ParseDuration(getEnv("EXPORT_LINK_EXPIRY", "72h"))
+	if err != nil {
+		exportLinkExpiry = 72 * time.Hour
+	}
+
+	exchangeRateSyncInterval, err := time.ParseDuration(getEnv("EXCHANGE_RATE_SYNC_INTERVAL", "24h"))
+	if err != nil {
+		exchangeRateSyncInterval = 24 * time.Hour
+	}
+
 	return &Config{
 		Port:                getEnv("PORT", "8080"),
 		Env:                 getEnv("ENV", "development"),
+		APIBaseURL:          getEnv("API_BASE_URL", "http://localhost:8080"),
 		DatabaseURL:         getEnv("DATABASE_URL", ""),
 		JWTSecret:           getEnv("JWT_SECRET", ""),
 		JWTExpiry:           jwtExpiry,
@@ -75,6 +119,25 @@ func Load() *Config {
 		SMTPUsername:        getEnv("SMTP_USERNAME", ""),
 		SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
 		EmailFrom:           getEnv("EMAIL_FROM", "noreply@emasgo.com"),
+
+		AccountDeletionGracePeriod: deletionGracePeriod,
+		ExportLinkExpiry:           exportLinkExpiry,
+		AdminEmails:                splitList(getEnv("ADMIN_EMAILS", "")),
+
+		ExchangeRateProvider:     getEnv("EXCHANGE_RATE_PROVIDER", ""),
+		ExchangeRateAPIURL:       getEnv("EXCHANGE_RATE_API_URL", "https://api.frankfurter.app"),
+		ExchangeRateFixturesPath: getEnv("EXCHANGE_RATE_FIXTURES_PATH", "./fixtures/exchange_rates"),
+		ExchangeRateSyncInterval: exchangeRateSyncInterval,
+
+		PushProvider:       getEnv("PUSH_PROVIDER", ""),
+		FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
+		APNsKeyFile:        getEnv("APNS_KEY_FILE", ""),
+		APNsKeyID:          getEnv("APNS_KEY_ID", ""),
+		APNsTeamID:         getEnv("APNS_TEAM_ID", ""),
+		APNsTopic:          getEnv("APNS_TOPIC", ""),
+		APNsProduction:     getEnv("APNS_PRODUCTION", "false") == "true",
+		VAPIDPrivateKey:    getEnv("VAPID_PRIVATE_KEY", ""),
+		VAPIDSubject:       getEnv("VAPID_SUBJECT", ""),
 	}
 }
 
@@ -85,3 +148,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// splitList parses a comma-separated environment value, skipping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
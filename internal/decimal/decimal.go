@@ -0,0 +1,314 @@
+// Package decimal provides an exact fixed-point number for rupiah amounts and gram
+// weights, so values read from Postgres DECIMAL columns are never converted to float.
+// Transactions, pockets, savings goals and the analytics models use it. The records of
+// recurring plans, installments, zakat and pledges still hold float64; their amounts
+// are rounded into decimals where they are summed for the dashboard.
+//
+// Rounding policy: amounts are kept to MoneyPlaces (2) and weights to WeightPlaces (3)
+// decimal places, matching the DECIMAL(15,2) and DECIMAL(10,3) columns. Intermediate
+// results carry four decimal places and are rounded half away from zero, the same as
+// Postgres ROUND on numeric. Round to the column's precision only once, when a value
+// is stored or compared with one that was.
+//
+// Values range up to about ±922 trillion. Multiplying and dividing return an error
+// when the result falls outside that range; ratios that are not stored are taken as
+// floats with Ratio, which cannot overflow.
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Places is the number of decimal places a Decimal holds
+	Places = 4
+
+	MoneyPlaces  = 2
+	WeightPlaces = 3
+
+	factor = 10000
+)
+
+// Decimal is a fixed-point number with four decimal places. The zero value is 0.
+type Decimal struct {
+	units int64
+}
+
+var Zero = Decimal{}
+
+// New returns value × 10^-places, e.g. New(123456789, 2) is 1234567.89
+func New(value int64, places int) (Decimal, error) {
+	return roundRat(new(big.Rat).SetFrac(big.NewInt(value), pow10(places)))
+}
+
+func NewFromInt(value int64) Decimal {
+	return Decimal{units: value * factor}
+}
+
+// NewFromFloat converts a float using its shortest decimal representation, so a
+// value decoded from JSON such as 1234567.89 is taken exactly as written
+func NewFromFloat(value float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(value, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return d
+}
+
+// Parse reads a decimal string such as "1234567.89", rounding to four places
+func Parse(s string) (Decimal, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Zero, fmt.Errorf("decimal: invalid value %q", s)
+	}
+	return roundRat(r)
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{units: d.units + other.units}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{units: d.units - other.units}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units}
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+func (d Decimal) Mul(other Decimal) (Decimal, error) {
+	return roundRat(new(big.Rat).Mul(d.rat(), other.rat()))
+}
+
+// MulInt multiplies by a whole number, e.g. a monthly amount by a number of months
+func (d Decimal) MulInt(n int64) Decimal {
+	return Decimal{units: d.units * n}
+}
+
+// Div divides by other. Dividing by zero returns zero, like the CASE ... ELSE 0
+// guards in the analytics queries.
+func (d Decimal) Div(other Decimal) (Decimal, error) {
+	if other.units == 0 {
+		return Zero, nil
+	}
+	return roundRat(new(big.Rat).Quo(d.rat(), other.rat()))
+}
+
+// DivInt divides by a whole number, returning zero for n == 0
+func (d Decimal) DivInt(n int64) (Decimal, error) {
+	if n == 0 {
+		return Zero, nil
+	}
+	return roundRat(new(big.Rat).Quo(d.rat(), new(big.Rat).SetInt64(n)))
+}
+
+// Ratio returns d / other rounded to four decimal places as a float, for percentages
+// and other ratios that are not stored. It is zero when other is zero.
+func (d Decimal) Ratio(other Decimal) float64 {
+	if other.units == 0 {
+		return 0
+	}
+	units := roundScaled(new(big.Rat).SetFrac(big.NewInt(d.units), big.NewInt(other.units)))
+	ratio, _ := new(big.Rat).SetFrac(units, big.NewInt(factor)).Float64()
+	return ratio
+}
+
+// Round rounds half away from zero to the given number of decimal places
+func (d Decimal) Round(places int) Decimal {
+	if places >= Places {
+		return d
+	}
+	step := pow10(Places - places).Int64()
+	return Decimal{units: roundDiv(d.units, step) * step}
+}
+
+// Ceil rounds up to the given number of decimal places
+func (d Decimal) Ceil(places int) Decimal {
+	if places >= Places {
+		return d
+	}
+	step := pow10(Places - places).Int64()
+	q := d.units / step
+	if d.units%step > 0 {
+		q++
+	}
+	return Decimal{units: q * step}
+}
+
+// Floor rounds down to the given number of decimal places
+func (d Decimal) Floor(places int) Decimal {
+	return d.Neg().Ceil(places).Neg()
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) Equal(other Decimal) bool       { return d.units == other.units }
+func (d Decimal) LessThan(other Decimal) bool    { return d.units < other.units }
+func (d Decimal) GreaterThan(other Decimal) bool { return d.units > other.units }
+func (d Decimal) IsZero() bool                   { return d.units == 0 }
+func (d Decimal) IsPositive() bool               { return d.units > 0 }
+func (d Decimal) IsNegative() bool               { return d.units < 0 }
+
+// Min returns the smaller of d and other
+func (d Decimal) Min(other Decimal) Decimal {
+	if other.units < d.units {
+		return other
+	}
+	return d
+}
+
+// Max returns the larger of d and other
+func (d Decimal) Max(other Decimal) Decimal {
+	if other.units > d.units {
+		return other
+	}
+	return d
+}
+
+// Float64 converts to a float for ratios and percentages, which are not stored
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / factor
+}
+
+// String formats the value without trailing zeros, e.g. "1234567.89"
+func (d Decimal) String() string {
+	s := d.StringFixed(Places)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// StringFixed formats the value with exactly the given number of decimal places
+func (d Decimal) StringFixed(places int) string {
+	if places > Places {
+		places = Places
+	}
+	r := d.Round(places).units
+
+	sign := ""
+	if r < 0 {
+		sign = "-"
+		r = -r
+	}
+
+	whole := strconv.FormatInt(r/factor, 10)
+	if places <= 0 {
+		return sign + whole
+	}
+	frac := fmt.Sprintf("%04d", r%factor)[:places]
+	return sign + whole + "." + frac
+}
+
+// MarshalJSON writes a JSON number, as the float fields did before
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a quoted decimal string
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := Parse(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan reads a Postgres numeric, which lib/pq returns as text
+func (d *Decimal) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+	case []byte:
+		*d, err = Parse(string(v))
+	case string:
+		*d, err = Parse(v)
+	case int64:
+		*d = NewFromInt(v)
+	case float64:
+		*d = NewFromFloat(v)
+	default:
+		err = fmt.Errorf("decimal: cannot scan %T", src)
+	}
+	return err
+}
+
+// Value stores the value as text so Postgres parses it exactly
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+var errOverflow = errors.New("decimal: value out of range")
+
+func (d Decimal) rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.units), big.NewInt(factor))
+}
+
+func roundRat(r *big.Rat) (Decimal, error) {
+	q := roundScaled(r)
+	if !q.IsInt64() {
+		return Zero, errOverflow
+	}
+	return Decimal{units: q.Int64()}, nil
+}
+
+// roundScaled returns r in units of 10^-4, rounded half away from zero
+func roundScaled(r *big.Rat) *big.Int {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt64(factor))
+	num, den := scaled.Num(), scaled.Denom()
+
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// roundDiv divides rounding half away from zero
+func roundDiv(value, divisor int64) int64 {
+	q, m := value/divisor, value%divisor
+	if m < 0 {
+		m = -m
+	}
+	if m*2 >= divisor {
+		if value < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
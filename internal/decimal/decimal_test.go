@@ -0,0 +1,284 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"1234567.89", "1234567.89"},
+		{"1234567.8900", "1234567.89"},
+		{" 12.5 ", "12.5"},
+		{"0.0481", "0.0481"},
+		{"-0.5", "-0.5"},
+		{"-1045000", "-1045000"},
+		// Beyond four places the value is rounded half away from zero
+		{"0.00005", "0.0001"},
+		{"0.00004", "0"},
+		{"-0.00005", "-0.0001"},
+		{"1/3", "0.3333"},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1,5", "1.2.3"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestStringFixed(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+	}{
+		{"1234567.89", 2, "1234567.89"},
+		{"12", 2, "12.00"},
+		{"0.0485", 3, "0.049"},
+		{"-0.0485", 3, "-0.049"},
+		{"2.5", 0, "3"},
+		{"1.23", 6, "1.2300"},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).StringFixed(tt.places); got != tt.want {
+			t.Errorf("%s.StringFixed(%d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+	}{
+		{"1.005", MoneyPlaces, "1.01"},
+		{"1.0049", MoneyPlaces, "1"},
+		{"-1.005", MoneyPlaces, "-1.01"},
+		{"-1.0049", MoneyPlaces, "-1"},
+		{"0.0485", WeightPlaces, "0.049"},
+		{"0.0484", WeightPlaces, "0.048"},
+		{"-0.0485", WeightPlaces, "-0.049"},
+		{"1049.5", 0, "1050"},
+		{"-1049.5", 0, "-1050"},
+		{"0.1234", Places, "0.1234"},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).Round(tt.places).String(); got != tt.want {
+			t.Errorf("%s.Round(%d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestCeil(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+	}{
+		{"1.001", MoneyPlaces, "1.01"},
+		{"1.01", MoneyPlaces, "1.01"},
+		{"-1.009", MoneyPlaces, "-1"},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).Ceil(tt.places).String(); got != tt.want {
+			t.Errorf("%s.Ceil(%d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestFloor(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+	}{
+		{"0.9569", WeightPlaces, "0.956"},
+		{"2.01", WeightPlaces, "2.01"},
+		{"-1.001", MoneyPlaces, "-1.01"},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.in).Floor(tt.places).String(); got != tt.want {
+			t.Errorf("%s.Floor(%d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"1.235", "1052500", "1299837.5"},
+		{"0.0481", "1045000", "50264.5"},
+		// Products beyond four places are rounded once, half away from zero
+		{"0.0125", "0.0125", "0.0002"},
+		{"0.0001", "0.5", "0.0001"},
+		{"-0.0001", "0.5", "-0.0001"},
+		{"-2.5", "1045000", "-2612500"},
+		{"-0.5", "-0.5", "0.25"},
+	}
+
+	for _, tt := range tests {
+		got, err := mustParse(t, tt.a).Mul(mustParse(t, tt.b))
+		if err != nil {
+			t.Errorf("%s × %s: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s × %s = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"1299837.5", "1.235", "1052500"},
+		{"49951", "0.0478", "1045000"},
+		{"100000", "3", "33333.3333"},
+		{"200000", "3", "66666.6667"},
+		{"-200000", "3", "-66666.6667"},
+		{"1", "-8", "-0.125"},
+		// Dividing by zero gives zero, like the analytics queries
+		{"1045000", "0", "0"},
+	}
+
+	for _, tt := range tests {
+		got, err := mustParse(t, tt.a).Div(mustParse(t, tt.b))
+		if err != nil {
+			t.Errorf("%s / %s: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s / %s = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	got, err := mustParse(t, "18").DivInt(24)
+	if err != nil || got.String() != "0.75" {
+		t.Errorf("18.DivInt(24) = %s, %v; want 0.75", got, err)
+	}
+	got, err = mustParse(t, "18").DivInt(0)
+	if err != nil || !got.IsZero() {
+		t.Errorf("18.DivInt(0) = %s, %v; want 0", got, err)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"50", "200", 0.25},
+		{"-1", "3", -0.3333},
+		{"2", "3", 0.6667},
+		{"1", "0", 0},
+		// A ratio too large for a Decimal still has a float value
+		{"900000000000000", "0.0001", 9e18},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.a).Ratio(mustParse(t, tt.b)); got != tt.want {
+			t.Errorf("%s.Ratio(%s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	max := Decimal{units: math.MaxInt64}
+	large := mustParse(t, "900000000000000")
+
+	if _, err := Parse("1000000000000000"); err != errOverflow {
+		t.Errorf("Parse of 10^15: err = %v, want %v", err, errOverflow)
+	}
+	if _, err := New(math.MaxInt64, 0); err != errOverflow {
+		t.Errorf("New(MaxInt64, 0): err = %v, want %v", err, errOverflow)
+	}
+	if _, err := large.Mul(NewFromInt(2)); err != errOverflow {
+		t.Errorf("%s × 2: err = %v, want %v", large, err, errOverflow)
+	}
+	if _, err := large.Mul(large.Neg()); err != errOverflow {
+		t.Errorf("%s × -%s: err = %v, want %v", large, large, err, errOverflow)
+	}
+	if _, err := large.Div(mustParse(t, "0.5")); err != errOverflow {
+		t.Errorf("%s / 0.5: err = %v, want %v", large, err, errOverflow)
+	}
+	if _, err := max.DivInt(1); err != nil {
+		t.Errorf("max / 1: %v", err)
+	}
+
+	// Values within the range still multiply exactly
+	got, err := large.Mul(mustParse(t, "1.0000"))
+	if err != nil || !got.Equal(large) {
+		t.Errorf("%s × 1 = %s, %v", large, got, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	d, err := New(123456789, 2)
+	if err != nil || d.String() != "1234567.89" {
+		t.Errorf("New(123456789, 2) = %s, %v; want 1234567.89", d, err)
+	}
+	d, err = New(-5, 5)
+	if err != nil || d.String() != "-0.0001" {
+		t.Errorf("New(-5, 5) = %s, %v; want -0.0001", d, err)
+	}
+}
+
+func TestNewFromFloat(t *testing.T) {
+	if got := NewFromFloat(1234567.89).String(); got != "1234567.89" {
+		t.Errorf("NewFromFloat(1234567.89) = %s", got)
+	}
+	if got := NewFromFloat(0.1 + 0.2).String(); got != "0.3" {
+		t.Errorf("NewFromFloat(0.1 + 0.2) = %s, want 0.3", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Amount Decimal `json:"amount"`
+		Weight Decimal `json:"weight"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 1234567.89, "weight": "0.125"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Amount.String() != "1234567.89" || v.Weight.String() != "0.125" {
+		t.Errorf("decoded %s and %s", v.Amount, v.Weight)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":1234567.89,"weight":0.125}` {
+		t.Errorf("encoded %s", data)
+	}
+}
@@ -0,0 +1,119 @@
+// Package exchangerates reads daily exchange rates from rate files and providers.
+// Rates are quoted as rupiah per one unit of the foreign currency, e.g. USD 16250.5,
+// so amounts stored in rupiah are converted by dividing by the rate.
+package exchangerates
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// Base is the currency every amount is stored in
+const Base = "IDR"
+
+// Supported lists the display currencies users can choose
+var Supported = []string{"IDR", "USD", "EUR", "SGD", "MYR"}
+
+// IsSupported reports whether currency is one of the supported display currencies
+func IsSupported(currency string) bool {
+	for _, supported := range Supported {
+		if supported == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// Rate is the rupiah price of one unit of a currency on a day
+type Rate struct {
+	Date     time.Time
+	Currency string
+	Rate     decimal.Decimal
+}
+
+// Provider fetches daily rates from an external source
+type Provider interface {
+	// Name is stored as the source of the fetched rates
+	Name() string
+	// Fetch returns the rates of each currency for the days between from and to
+	// inclusive. Days without a published rate, such as weekends, are missing.
+	Fetch(currencies []string, from, to time.Time) ([]Rate, error)
+}
+
+// ParseCSV reads rates from a file with the header date,currency,rate, e.g.
+// "2024-01-02,USD,15439.00". Columns may be in any order and ";" may separate them.
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimPrefix(string(content), "\uFEFF")
+	cr := csv.NewReader(strings.NewReader(text))
+	if firstLine := strings.SplitN(text, "\n", 2)[0]; strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		cr.Comma = ';'
+	}
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+	if len(records) < 2 {
+		return nil, errors.New("CSV file has no rates")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "currency", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New("CSV file is missing column: " + name)
+		}
+	}
+
+	rates := make([]Rate, 0, len(records)-1)
+	for i, record := range records[1:] {
+		// Row numbers match the spreadsheet, where the header is row 1
+		line := i + 2
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date format, use YYYY-MM-DD", line)
+		}
+
+		currency := strings.ToUpper(strings.TrimSpace(record[columns["currency"]]))
+		if !IsSupported(currency) || currency == Base {
+			return nil, fmt.Errorf("row %d: unsupported currency %q", line, currency)
+		}
+
+		rate, err := decimal.Parse(record[columns["rate"]])
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("row %d: rate must be a positive number", line)
+		}
+
+		rates = append(rates, Rate{Date: date, Currency: currency, Rate: rate})
+	}
+
+	return rates, nil
+}
+
+// NewProvider returns the configured provider. An empty name disables fetching.
+func NewProvider(name, apiURL, fixturesPath string) (Provider, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "frankfurter":
+		return NewFrankfurterProvider(apiURL), nil
+	case "fixture":
+		return FixtureProvider{Path: fixturesPath}, nil
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider %q", name)
+	}
+}
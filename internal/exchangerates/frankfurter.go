@@ -0,0 +1,120 @@
+package exchangerates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// FrankfurterProvider fetches the European Central Bank reference rates published
+// by the Frankfurter API, which include the rupiah
+type FrankfurterProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewFrankfurterProvider(baseURL string) *FrankfurterProvider {
+	return &FrankfurterProvider{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *FrankfurterProvider) Name() string { return "frankfurter" }
+
+func (p *FrankfurterProvider) Fetch(currencies []string, from, to time.Time) ([]Rate, error) {
+	var rates []Rate
+	for _, currency := range currencies {
+		url := fmt.Sprintf("%s/%s..%s?from=%s&to=%s", p.BaseURL, from.Format("2006-01-02"), to.Format("2006-01-02"), currency, Base)
+
+		resp, err := p.Client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("frankfurter: %s rates returned status %d", currency, resp.StatusCode)
+		}
+
+		parsed, err := parseTimeseries(resp.Body, currency, from, to)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, parsed...)
+	}
+
+	return rates, nil
+}
+
+// FixtureProvider serves rates from Frankfurter responses saved as <CURRENCY>.json
+// in a directory, for development and test environments without network access
+type FixtureProvider struct {
+	Path string
+}
+
+func (p FixtureProvider) Name() string { return "fixture" }
+
+func (p FixtureProvider) Fetch(currencies []string, from, to time.Time) ([]Rate, error) {
+	var rates []Rate
+	for _, currency := range currencies {
+		file, err := os.Open(filepath.Join(p.Path, currency+".json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseTimeseries(file, currency, from, to)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, parsed...)
+	}
+
+	return rates, nil
+}
+
+// timeseries is the Frankfurter response of /{from}..{to}?from=USD&to=IDR
+type timeseries struct {
+	Base  string                                `json:"base"`
+	Rates map[string]map[string]decimal.Decimal `json:"rates"`
+}
+
+func parseTimeseries(r io.Reader, currency string, from, to time.Time) ([]Rate, error) {
+	var series timeseries
+	if err := json.NewDecoder(r).Decode(&series); err != nil {
+		return nil, fmt.Errorf("frankfurter: invalid %s response: %v", currency, err)
+	}
+	if series.Base != currency {
+		return nil, fmt.Errorf("frankfurter: expected %s rates, got %s", currency, series.Base)
+	}
+
+	var rates []Rate
+	for day, quotes := range series.Rates {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			return nil, fmt.Errorf("frankfurter: invalid date %q", day)
+		}
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+
+		rate, ok := quotes[Base]
+		if !ok || !rate.IsPositive() {
+			continue
+		}
+		rates = append(rates, Rate{Date: date, Currency: currency, Rate: rate})
+	}
+
+	return rates, nil
+}
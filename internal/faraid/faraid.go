@@ -0,0 +1,186 @@
+// Package faraid computes Islamic inheritance (faraid) shares for the common heirs:
+// spouse, children and parents. Shares are exact fractions of the estate.
+package faraid
+
+import (
+	"errors"
+	"math/big"
+)
+
+const (
+	HeirHusband  = "husband"
+	HeirWife     = "wife"
+	HeirSon      = "son"
+	HeirDaughter = "daughter"
+	HeirFather   = "father"
+	HeirMother   = "mother"
+
+	// BasisFard is a fixed Quranic share, BasisAsabah the residue, BasisFardAsabah both
+	BasisFard       = "fard"
+	BasisAsabah     = "asabah"
+	BasisFardAsabah = "fard+asabah"
+)
+
+// Heirs lists the surviving heirs of the deceased
+type Heirs struct {
+	Husband   bool
+	Wives     int
+	Sons      int
+	Daughters int
+	Father    bool
+	Mother    bool
+}
+
+// Share is the portion of the estate for one class of heir, shared equally by Count people
+type Share struct {
+	Heir     string
+	Count    int
+	Basis    string
+	Fraction *big.Rat
+}
+
+// Result is the full distribution. Awl is set when fixed shares exceeded the estate and were
+// scaled down; Radd when a surplus was returned to the non-spouse fixed-share heirs.
+// Unallocated is any surplus no modelled heir can take (it passes to more distant relatives
+// or the Baitul Mal).
+type Result struct {
+	Shares      []Share
+	Awl         bool
+	Radd        bool
+	Unallocated *big.Rat
+}
+
+// Distribute applies the faraid rules to the given heirs
+func Distribute(h Heirs) (*Result, error) {
+	if h.Husband && h.Wives > 0 {
+		return nil, errors.New("the deceased cannot leave both a husband and a wife")
+	}
+	if h.Wives < 0 || h.Wives > 4 {
+		return nil, errors.New("number of wives must be between 0 and 4")
+	}
+	if h.Sons < 0 || h.Daughters < 0 {
+		return nil, errors.New("number of children cannot be negative")
+	}
+	if !h.Husband && h.Wives == 0 && h.Sons == 0 && h.Daughters == 0 && !h.Father && !h.Mother {
+		return nil, errors.New("at least one heir is required")
+	}
+
+	hasChildren := h.Sons > 0 || h.Daughters > 0
+	var shares []Share
+
+	// Spouse
+	spouseFraction := new(big.Rat)
+	if h.Husband {
+		spouseFraction = big.NewRat(1, 2)
+		if hasChildren {
+			spouseFraction = big.NewRat(1, 4)
+		}
+		shares = append(shares, Share{Heir: HeirHusband, Count: 1, Basis: BasisFard, Fraction: spouseFraction})
+	}
+	if h.Wives > 0 {
+		spouseFraction = big.NewRat(1, 4)
+		if hasChildren {
+			spouseFraction = big.NewRat(1, 8)
+		}
+		shares = append(shares, Share{Heir: HeirWife, Count: h.Wives, Basis: BasisFard, Fraction: spouseFraction})
+	}
+
+	// Mother: 1/6 with children, otherwise 1/3; with a spouse and the father but no
+	// children she takes 1/3 of what remains after the spouse (al-'Umariyyatain)
+	if h.Mother {
+		fraction := big.NewRat(1, 3)
+		if hasChildren {
+			fraction = big.NewRat(1, 6)
+		} else if h.Father && spouseFraction.Sign() > 0 {
+			remainder := new(big.Rat).Sub(big.NewRat(1, 1), spouseFraction)
+			fraction = remainder.Mul(remainder, big.NewRat(1, 3))
+		}
+		shares = append(shares, Share{Heir: HeirMother, Count: 1, Basis: BasisFard, Fraction: fraction})
+	}
+
+	// Father: 1/6 with children, plus the residue when there are only daughters,
+	// and only the residue without children
+	fatherIndex := -1
+	if h.Father {
+		share := Share{Heir: HeirFather, Count: 1, Basis: BasisAsabah, Fraction: new(big.Rat)}
+		if hasChildren {
+			share.Basis = BasisFard
+			share.Fraction = big.NewRat(1, 6)
+			if h.Sons == 0 {
+				share.Basis = BasisFardAsabah
+			}
+		}
+		fatherIndex = len(shares)
+		shares = append(shares, share)
+	}
+
+	// Daughters without sons take 1/2 alone or 2/3 together
+	if h.Daughters > 0 && h.Sons == 0 {
+		fraction := big.NewRat(1, 2)
+		if h.Daughters > 1 {
+			fraction = big.NewRat(2, 3)
+		}
+		shares = append(shares, Share{Heir: HeirDaughter, Count: h.Daughters, Basis: BasisFard, Fraction: fraction})
+	}
+
+	result := &Result{Unallocated: new(big.Rat)}
+
+	total := new(big.Rat)
+	for _, s := range shares {
+		total.Add(total, s.Fraction)
+	}
+
+	one := big.NewRat(1, 1)
+	if total.Cmp(one) > 0 {
+		// 'Awl: every fixed share is reduced proportionally
+		for i := range shares {
+			shares[i].Fraction = new(big.Rat).Quo(shares[i].Fraction, total)
+		}
+		result.Awl = true
+		result.Shares = shares
+		return result, nil
+	}
+
+	residue := new(big.Rat).Sub(one, total)
+
+	switch {
+	case h.Sons > 0:
+		// Sons take the residue, with daughters receiving half a son's portion
+		units := int64(2*h.Sons + h.Daughters)
+		sonsFraction := new(big.Rat).Mul(residue, big.NewRat(int64(2*h.Sons), units))
+		shares = append(shares, Share{Heir: HeirSon, Count: h.Sons, Basis: BasisAsabah, Fraction: sonsFraction})
+		if h.Daughters > 0 {
+			daughtersFraction := new(big.Rat).Sub(residue, sonsFraction)
+			shares = append(shares, Share{Heir: HeirDaughter, Count: h.Daughters, Basis: BasisAsabah, Fraction: daughtersFraction})
+		}
+	case fatherIndex >= 0:
+		shares[fatherIndex].Fraction = new(big.Rat).Add(shares[fatherIndex].Fraction, residue)
+	case residue.Sign() > 0:
+		// Radd: the surplus returns to fixed-share heirs other than the spouse
+		raddBase := new(big.Rat)
+		for _, s := range shares {
+			if !isSpouse(s.Heir) {
+				raddBase.Add(raddBase, s.Fraction)
+			}
+		}
+		if raddBase.Sign() == 0 {
+			result.Unallocated = residue
+			break
+		}
+		for i, s := range shares {
+			if isSpouse(s.Heir) {
+				continue
+			}
+			extra := new(big.Rat).Mul(residue, new(big.Rat).Quo(s.Fraction, raddBase))
+			shares[i].Fraction = new(big.Rat).Add(s.Fraction, extra)
+		}
+		result.Radd = true
+	}
+
+	result.Shares = shares
+	return result, nil
+}
+
+func isSpouse(heir string) bool {
+	return heir == HeirHusband || heir == HeirWife
+}
@@ -0,0 +1,201 @@
+package faraid
+
+import (
+	"math/big"
+	"testing"
+)
+
+func rat(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		t.Fatalf("invalid fraction %q", s)
+	}
+	return r
+}
+
+type wantShare struct {
+	heir     string
+	count    int
+	basis    string
+	fraction string
+}
+
+func TestDistribute(t *testing.T) {
+	tests := []struct {
+		name        string
+		heirs       Heirs
+		want        []wantShare
+		awl         bool
+		radd        bool
+		unallocated string
+	}{
+		{
+			name:        "husband without children",
+			heirs:       Heirs{Husband: true},
+			want:        []wantShare{{HeirHusband, 1, BasisFard, "1/2"}},
+			unallocated: "1/2",
+		},
+		{
+			name:  "husband with a son",
+			heirs: Heirs{Husband: true, Sons: 1},
+			want: []wantShare{
+				{HeirHusband, 1, BasisFard, "1/4"},
+				{HeirSon, 1, BasisAsabah, "3/4"},
+			},
+		},
+		{
+			name:  "wife with a son and a daughter",
+			heirs: Heirs{Wives: 1, Sons: 1, Daughters: 1},
+			want: []wantShare{
+				{HeirWife, 1, BasisFard, "1/8"},
+				{HeirSon, 1, BasisAsabah, "7/12"},
+				{HeirDaughter, 1, BasisAsabah, "7/24"},
+			},
+		},
+		{
+			name:  "two wives and the father",
+			heirs: Heirs{Wives: 2, Father: true},
+			want: []wantShare{
+				{HeirWife, 2, BasisFard, "1/4"},
+				{HeirFather, 1, BasisAsabah, "3/4"},
+			},
+		},
+		{
+			name:  "parents",
+			heirs: Heirs{Father: true, Mother: true},
+			want: []wantShare{
+				{HeirMother, 1, BasisFard, "1/3"},
+				{HeirFather, 1, BasisAsabah, "2/3"},
+			},
+		},
+		{
+			name:  "parents with a son",
+			heirs: Heirs{Father: true, Mother: true, Sons: 1},
+			want: []wantShare{
+				{HeirMother, 1, BasisFard, "1/6"},
+				{HeirFather, 1, BasisFard, "1/6"},
+				{HeirSon, 1, BasisAsabah, "2/3"},
+			},
+		},
+		{
+			name:  "father with a daughter",
+			heirs: Heirs{Father: true, Daughters: 1},
+			want: []wantShare{
+				{HeirFather, 1, BasisFardAsabah, "1/2"},
+				{HeirDaughter, 1, BasisFard, "1/2"},
+			},
+		},
+		{
+			name:  "'umariyyatain with the husband",
+			heirs: Heirs{Husband: true, Father: true, Mother: true},
+			want: []wantShare{
+				{HeirHusband, 1, BasisFard, "1/2"},
+				{HeirMother, 1, BasisFard, "1/6"},
+				{HeirFather, 1, BasisAsabah, "1/3"},
+			},
+		},
+		{
+			name:  "'umariyyatain with the wife",
+			heirs: Heirs{Wives: 1, Father: true, Mother: true},
+			want: []wantShare{
+				{HeirWife, 1, BasisFard, "1/4"},
+				{HeirMother, 1, BasisFard, "1/4"},
+				{HeirFather, 1, BasisAsabah, "1/2"},
+			},
+		},
+		{
+			// 3 + 2 + 2 + 8 twelfths make 15, so the estate is shared in fifteenths
+			name:  "'awl",
+			heirs: Heirs{Husband: true, Daughters: 2, Father: true, Mother: true},
+			want: []wantShare{
+				{HeirHusband, 1, BasisFard, "3/15"},
+				{HeirMother, 1, BasisFard, "2/15"},
+				{HeirFather, 1, BasisFardAsabah, "2/15"},
+				{HeirDaughter, 2, BasisFard, "8/15"},
+			},
+			awl: true,
+		},
+		{
+			name:  "radd",
+			heirs: Heirs{Mother: true, Daughters: 1},
+			want: []wantShare{
+				{HeirMother, 1, BasisFard, "1/4"},
+				{HeirDaughter, 1, BasisFard, "3/4"},
+			},
+			radd: true,
+		},
+		{
+			// The wife keeps her share; the surplus goes to the daughters only
+			name:  "radd with a spouse",
+			heirs: Heirs{Wives: 1, Daughters: 2},
+			want: []wantShare{
+				{HeirWife, 1, BasisFard, "1/8"},
+				{HeirDaughter, 2, BasisFard, "7/8"},
+			},
+			radd: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Distribute(tt.heirs)
+			if err != nil {
+				t.Fatalf("Distribute: %v", err)
+			}
+			if result.Awl != tt.awl || result.Radd != tt.radd {
+				t.Errorf("awl = %v, radd = %v; want %v, %v", result.Awl, result.Radd, tt.awl, tt.radd)
+			}
+
+			if len(result.Shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d: %v", len(result.Shares), len(tt.want), result.Shares)
+			}
+			for i, want := range tt.want {
+				got := result.Shares[i]
+				if got.Heir != want.heir || got.Count != want.count || got.Basis != want.basis {
+					t.Errorf("share %d: %s ×%d (%s), want %s ×%d (%s)",
+						i, got.Heir, got.Count, got.Basis, want.heir, want.count, want.basis)
+				}
+				if got.Fraction.Cmp(rat(t, want.fraction)) != 0 {
+					t.Errorf("share %d (%s): %s, want %s", i, got.Heir, got.Fraction.RatString(), want.fraction)
+				}
+			}
+
+			unallocated := "0"
+			if tt.unallocated != "" {
+				unallocated = tt.unallocated
+			}
+			if result.Unallocated.Cmp(rat(t, unallocated)) != 0 {
+				t.Errorf("unallocated = %s, want %s", result.Unallocated.RatString(), unallocated)
+			}
+
+			// The shares and what is left unallocated always make up the whole estate
+			total := new(big.Rat).Set(result.Unallocated)
+			for _, share := range result.Shares {
+				total.Add(total, share.Fraction)
+			}
+			if total.Cmp(big.NewRat(1, 1)) != 0 {
+				t.Errorf("shares add up to %s, want 1", total.RatString())
+			}
+		})
+	}
+}
+
+func TestDistributeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		heirs Heirs
+	}{
+		{"no heirs", Heirs{}},
+		{"husband and wife", Heirs{Husband: true, Wives: 1}},
+		{"five wives", Heirs{Wives: 5}},
+		{"negative wives", Heirs{Wives: -1, Sons: 1}},
+		{"negative children", Heirs{Father: true, Sons: -1}},
+	}
+
+	for _, tt := range tests {
+		if _, err := Distribute(tt.heirs); err == nil {
+			t.Errorf("%s: Distribute succeeded, want an error", tt.name)
+		}
+	}
+}
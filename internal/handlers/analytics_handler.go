@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/decimal"
 	"nabung-emas-api/internal/middleware"
 	"nabung-emas-api/internal/services"
 	"nabung-emas-api/internal/utils"
@@ -24,15 +25,20 @@ func (h *AnalyticsHandler) GetDashboard(c echo.Context) error {
 		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 	}
 
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
 	// Parse current gold price if provided
-	var currentGoldPrice *float64
+	var currentGoldPrice *decimal.Decimal
 	if priceStr := c.QueryParam("current_gold_price"); priceStr != "" {
-		if price, err := strconv.ParseFloat(priceStr, 64); err == nil {
+		if price, err := decimal.Parse(priceStr); err == nil {
 			currentGoldPrice = &price
 		}
 	}
 
-	dashboard, err := h.service.GetDashboard(userID, currentGoldPrice)
+	dashboard, err := h.service.GetDashboard(userID, currency, currentGoldPrice)
 	if err != nil {
 		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch dashboard data")
 	}
@@ -46,15 +52,20 @@ func (h *AnalyticsHandler) GetPortfolio(c echo.Context) error {
 		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 	}
 
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
 	// Parse current gold price if provided
-	var currentGoldPrice *float64
+	var currentGoldPrice *decimal.Decimal
 	if priceStr := c.QueryParam("current_gold_price"); priceStr != "" {
-		if price, err := strconv.ParseFloat(priceStr, 64); err == nil {
+		if price, err := decimal.Parse(priceStr); err == nil {
 			currentGoldPrice = &price
 		}
 	}
 
-	portfolio, err := h.service.GetPortfolio(userID, currentGoldPrice)
+	portfolio, err := h.service.GetPortfolio(userID, currency, currentGoldPrice)
 	if err != nil {
 		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch portfolio analytics")
 	}
@@ -68,6 +79,11 @@ func (h *AnalyticsHandler) GetMonthlyPurchases(c echo.Context) error {
 		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 	}
 
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
 	months, _ := strconv.Atoi(c.QueryParam("months"))
 	pocketID := c.QueryParam("pocket_id")
 
@@ -76,7 +92,7 @@ func (h *AnalyticsHandler) GetMonthlyPurchases(c echo.Context) error {
 		pocketIDPtr = &pocketID
 	}
 
-	analytics, err := h.service.GetMonthlyPurchases(userID, months, pocketIDPtr)
+	analytics, err := h.service.GetMonthlyPurchases(userID, currency, months, pocketIDPtr)
 	if err != nil {
 		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch monthly purchase analytics")
 	}
@@ -90,7 +106,12 @@ func (h *AnalyticsHandler) GetBrandDistribution(c echo.Context) error {
 		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 	}
 
-	distribution, err := h.service.GetBrandDistribution(userID)
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	distribution, err := h.service.GetBrandDistribution(userID, currency)
 	if err != nil {
 		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch brand distribution")
 	}
@@ -98,6 +119,103 @@ func (h *AnalyticsHandler) GetBrandDistribution(c echo.Context) error {
 	return utils.SuccessResponse(c, http.StatusOK, "Success", distribution)
 }
 
+func (h *AnalyticsHandler) GetPremiums(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	premiums, err := h.service.GetPremiums(userID, currency, c.QueryParam("source"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch premium analytics")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", premiums)
+}
+
+func (h *AnalyticsHandler) GetPerformance(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	// Without a current gold price, the latest stored price is used
+	var currentGoldPrice *decimal.Decimal
+	if priceStr := c.QueryParam("current_gold_price"); priceStr != "" {
+		price, err := decimal.Parse(priceStr)
+		if err != nil || !price.IsPositive() {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "current_gold_price must be a positive number")
+		}
+		currentGoldPrice = &price
+	}
+
+	performance, err := h.service.GetPerformance(userID, currency, currentGoldPrice, c.QueryParam("source"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", performance)
+}
+
+func (h *AnalyticsHandler) CompareBenchmarks(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	// Without a current gold price, the latest stored price is used
+	var currentGoldPrice *decimal.Decimal
+	if priceStr := c.QueryParam("current_gold_price"); priceStr != "" {
+		price, err := decimal.Parse(priceStr)
+		if err != nil || !price.IsPositive() {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "current_gold_price must be a positive number")
+		}
+		currentGoldPrice = &price
+	}
+
+	comparison, err := h.service.CompareBenchmarks(userID, currency, c.QueryParam("benchmarks"), currentGoldPrice, c.QueryParam("source"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", comparison)
+}
+
+// GetDCAReport compares purchase timing with stored gold prices, in rupiah
+func (h *AnalyticsHandler) GetDCAReport(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var pocketID *string
+	if value := c.QueryParam("pocket_id"); value != "" {
+		pocketID = &value
+	}
+
+	report, err := h.service.GetDCAReport(userID, pocketID, c.QueryParam("source"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch DCA report")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", report)
+}
+
 func (h *AnalyticsHandler) GetTrends(c echo.Context) error {
 	userID := middleware.GetUserID(c)
 	if userID == "" {
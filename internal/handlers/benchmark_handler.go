@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type BenchmarkHandler struct {
+	service *services.BenchmarkService
+}
+
+func NewBenchmarkHandler(service *services.BenchmarkService) *BenchmarkHandler {
+	return &BenchmarkHandler{service: service}
+}
+
+func (h *BenchmarkHandler) GetAll(c echo.Context) error {
+	series, err := h.service.GetAll()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch benchmarks")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", series)
+}
+
+func (h *BenchmarkHandler) GetValues(c echo.Context) error {
+	var startDate, endDate *string
+	if value := c.QueryParam("start_date"); value != "" {
+		startDate = &value
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		endDate = &value
+	}
+
+	values, err := h.service.GetValues(c.Param("key"), startDate, endDate)
+	if err != nil {
+		if err.Error() == "benchmark not found" {
+			return utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		}
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", values)
+}
+
+// Import stores the values of an uploaded date,benchmark,value CSV file
+func (h *BenchmarkHandler) Import(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "CSV file is required")
+	}
+	if fileHeader.Size > 5<<20 {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "CSV file must be at most 5MB")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read CSV file")
+	}
+	defer file.Close()
+
+	result, err := h.service.Import(file)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Benchmark values imported successfully", result)
+}
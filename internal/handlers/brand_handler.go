@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type BrandHandler struct {
+	service *services.BrandService
+}
+
+func NewBrandHandler(service *services.BrandService) *BrandHandler {
+	return &BrandHandler{service: service}
+}
+
+// GetAll lists the brands available for new records
+func (h *BrandHandler) GetAll(c echo.Context) error {
+	brands, err := h.service.GetAll(true)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch brands")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", brands)
+}
+
+// AdminGetAll lists every brand, including deactivated ones
+func (h *BrandHandler) AdminGetAll(c echo.Context) error {
+	brands, err := h.service.GetAll(false)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch brands")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", brands)
+}
+
+func (h *BrandHandler) GetByID(c echo.Context) error {
+	id := c.Param("id")
+
+	brand, err := h.service.GetByID(id)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Brand not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", brand)
+}
+
+func (h *BrandHandler) Create(c echo.Context) error {
+	var req models.CreateBrandRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	brand, err := h.service.Create(&req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Brand created successfully", brand)
+}
+
+func (h *BrandHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req models.UpdateBrandRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	brand, err := h.service.Update(id, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Brand updated successfully", brand)
+}
+
+func (h *BrandHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+
+	if err := h.service.Delete(id); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Brand deleted successfully", nil)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type DeviceHandler struct {
+	service *services.PushService
+}
+
+func NewDeviceHandler(service *services.PushService) *DeviceHandler {
+	return &DeviceHandler{service: service}
+}
+
+func (h *DeviceHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	devices, err := h.service.GetDevices(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch devices")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", devices)
+}
+
+// Register adds the device for push notifications, or updates it when its token is
+// already registered
+func (h *DeviceHandler) Register(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	device, err := h.service.RegisterDevice(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Device registered successfully", device)
+}
+
+func (h *DeviceHandler) Delete(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.DeleteDevice(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Device not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Device removed successfully", nil)
+}
+
+// GetVAPIDPublicKey returns the application server key for browser push subscriptions
+func (h *DeviceHandler) GetVAPIDPublicKey(c echo.Context) error {
+	key, err := h.service.VAPIDPublicKey()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", map[string]string{"public_key": key})
+}
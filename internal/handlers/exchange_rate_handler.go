@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type ExchangeRateHandler struct {
+	service *services.ExchangeRateService
+}
+
+func NewExchangeRateHandler(service *services.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+// GetAll lists the stored daily rates of a currency, in rupiah per unit
+func (h *ExchangeRateHandler) GetAll(c echo.Context) error {
+	currency := c.QueryParam("currency")
+	if currency == "" {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "currency is required")
+	}
+
+	var startDate, endDate *string
+	if value := c.QueryParam("start_date"); value != "" {
+		startDate = &value
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		endDate = &value
+	}
+
+	rates, err := h.service.GetAll(currency, startDate, endDate)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", rates)
+}
+
+// Import stores the rates of an uploaded date,currency,rate CSV file
+func (h *ExchangeRateHandler) Import(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "CSV file is required")
+	}
+	if fileHeader.Size > 5<<20 {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "CSV file must be at most 5MB")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read CSV file")
+	}
+	defer file.Close()
+
+	result, err := h.service.Import(file)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Exchange rates imported successfully", result)
+}
+
+// Sync fetches rates for a date range from the configured provider
+func (h *ExchangeRateHandler) Sync(c echo.Context) error {
+	var req models.SyncExchangeRatesRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	from, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "invalid start date format, use YYYY-MM-DD")
+	}
+	to := time.Now()
+	if req.EndDate != "" {
+		to, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "invalid end date format, use YYYY-MM-DD")
+		}
+	}
+
+	result, err := h.service.Sync(from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Exchange rates synced successfully", result)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type ExportHandler struct {
+	service *services.ExportService
+}
+
+func NewExportHandler(service *services.ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+func (h *ExportHandler) Request(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	export, err := h.service.Request(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusAccepted, "Export started. We will email you when it is ready.", export)
+}
+
+func (h *ExportHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	exports, err := h.service.GetAll(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch exports")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", exports)
+}
+
+func (h *ExportHandler) GetByID(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	export, err := h.service.GetByID(id, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Export not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", export)
+}
+
+// Download serves an export archive. It is authorized by the signed token in the
+// link instead of a bearer token, so the link can be opened from an email.
+func (h *ExportHandler) Download(c echo.Context) error {
+	id := c.Param("id")
+	token := c.QueryParam("token")
+	if token == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Missing download token")
+	}
+
+	f, filename, err := h.service.OpenDownload(id, token)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+	}
+	defer f.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Stream(http.StatusOK, "application/zip", f)
+}
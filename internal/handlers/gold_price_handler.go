@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type GoldPriceHandler struct {
+	service *services.GoldPriceService
+}
+
+func NewGoldPriceHandler(service *services.GoldPriceService) *GoldPriceHandler {
+	return &GoldPriceHandler{service: service}
+}
+
+// Store saves daily gold prices and evaluates price alerts against the newest ones
+func (h *GoldPriceHandler) Store(c echo.Context) error {
+	var req models.StoreGoldPricesRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	result, err := h.service.Store(&req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Gold prices stored successfully", result)
+}
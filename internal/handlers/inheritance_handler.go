@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type InheritanceHandler struct {
+	service *services.InheritanceService
+}
+
+func NewInheritanceHandler(service *services.InheritanceService) *InheritanceHandler {
+	return &InheritanceHandler{service: service}
+}
+
+// Simulate returns the faraid distribution as JSON, or as printable text with ?format=text
+func (h *InheritanceHandler) Simulate(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.FaraidRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	distribution, err := h.service.Simulate(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	if c.QueryParam("format") == "text" {
+		return c.String(http.StatusOK, distribution.Summary)
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", distribution)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type InstallmentHandler struct {
+	service *services.InstallmentService
+}
+
+func NewInstallmentHandler(service *services.InstallmentService) *InstallmentHandler {
+	return &InstallmentHandler{service: service}
+}
+
+func (h *InstallmentHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	status := c.QueryParam("status")
+
+	var statusPtr *string
+	if status != "" {
+		statusPtr = &status
+	}
+
+	contracts, err := h.service.GetAll(userID, statusPtr)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch installment contracts")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", contracts)
+}
+
+func (h *InstallmentHandler) GetByID(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	contract, err := h.service.GetByID(id, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Installment contract not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", contract)
+}
+
+func (h *InstallmentHandler) Create(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.CreateInstallmentRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	contract, err := h.service.Create(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Installment contract created successfully", contract)
+}
+
+func (h *InstallmentHandler) RecordPayment(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	var req models.RecordInstallmentPaymentRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	contract, err := h.service.RecordPayment(id, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	message := "Installment payment recorded successfully"
+	if contract.Status == models.InstallmentStatusCompleted {
+		message = "Installment contract fully paid, gold added to pocket"
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, message, contract)
+}
+
+func (h *InstallmentHandler) Cancel(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.Cancel(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Installment contract cancelled successfully", nil)
+}
+
+func (h *InstallmentHandler) Delete(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.Delete(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Installment contract deleted successfully", nil)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type InventoryHandler struct {
+	service *services.InventoryService
+}
+
+func NewInventoryHandler(service *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{service: service}
+}
+
+func (h *InventoryHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	// Parse query parameters
+	q := c.QueryParam("q")
+	brand := c.QueryParam("brand")
+	pocketID := c.QueryParam("pocket_id")
+	location := c.QueryParam("storage_location")
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	items, total, err := h.service.Search(userID, &q, &brand, &pocketID, &location, page, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch inventory")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	return utils.PaginatedResponse(c, items, page, limit, total)
+}
+
+func (h *InventoryHandler) GetLocations(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	locations, err := h.service.GetLocations(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch storage locations")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", locations)
+}
+
+func (h *InventoryHandler) GetByID(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	item, err := h.service.GetByID(id, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Inventory item not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", item)
+}
+
+func (h *InventoryHandler) Create(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.CreateInventoryItemRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	item, err := h.service.Create(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Inventory item created successfully", item)
+}
+
+func (h *InventoryHandler) CreateFromTransaction(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	var req models.CreateTransactionInventoryRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	items, err := h.service.CreateFromTransaction(id, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Inventory items created successfully", items)
+}
+
+func (h *InventoryHandler) Update(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	var req models.UpdateInventoryItemRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	item, err := h.service.Update(id, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Inventory item updated successfully", item)
+}
+
+func (h *InventoryHandler) Delete(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.Delete(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Inventory item deleted successfully", nil)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// GetAll lists notifications newest first. Pass the returned next_cursor as cursor to
+// fetch the following page.
+func (h *NotificationHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	unreadOnly := c.QueryParam("unread") == "true"
+
+	list, err := h.service.GetAll(userID, c.QueryParam("cursor"), limit, unreadOnly)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", list)
+}
+
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.MarkRead(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Notification not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", nil)
+}
+
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	count, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to mark notifications as read")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "All notifications marked as read", map[string]int{"marked": count})
+}
+
+func (h *NotificationHandler) GetPreferences(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	preferences, err := h.service.GetPreferences(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch notification preferences")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", preferences)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	preferences, err := h.service.UpdatePreferences(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update notification preferences")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Notification preferences updated successfully", preferences)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type PledgeHandler struct {
+	service *services.PledgeService
+}
+
+func NewPledgeHandler(service *services.PledgeService) *PledgeHandler {
+	return &PledgeHandler{service: service}
+}
+
+func (h *PledgeHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	status := c.QueryParam("status")
+
+	var statusPtr *string
+	if status != "" {
+		statusPtr = &status
+	}
+
+	pledges, err := h.service.GetAll(userID, statusPtr)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch pledges")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", pledges)
+}
+
+func (h *PledgeHandler) GetByID(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	pledge, err := h.service.GetByID(id, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Pledge not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", pledge)
+}
+
+func (h *PledgeHandler) Create(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.CreatePledgeRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	pledge, err := h.service.Create(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Pledge created successfully", pledge)
+}
+
+func (h *PledgeHandler) Update(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	var req models.UpdatePledgeRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	pledge, err := h.service.Update(id, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pledge updated successfully", pledge)
+}
+
+func (h *PledgeHandler) Delete(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.Delete(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pledge deleted successfully", nil)
+}
+
+func (h *PledgeHandler) Redeem(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	var req models.RedeemPledgeRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	pledge, err := h.service.Redeem(id, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pledge redeemed successfully", pledge)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/decimal"
 	"nabung-emas-api/internal/middleware"
 	"nabung-emas-api/internal/models"
 	"nabung-emas-api/internal/services"
@@ -132,9 +133,9 @@ func (h *PocketHandler) GetStats(c echo.Context) error {
 	id := c.Param("id")
 
 	// Parse current gold price if provided
-	var currentGoldPrice *float64
+	var currentGoldPrice *decimal.Decimal
 	if priceStr := c.QueryParam("current_gold_price"); priceStr != "" {
-		if price, err := strconv.ParseFloat(priceStr, 64); err == nil {
+		if price, err := decimal.Parse(priceStr); err == nil {
 			currentGoldPrice = &price
 		}
 	}
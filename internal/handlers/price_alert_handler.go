@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type PriceAlertHandler struct {
+	service *services.PriceAlertService
+}
+
+func NewPriceAlertHandler(service *services.PriceAlertService) *PriceAlertHandler {
+	return &PriceAlertHandler{service: service}
+}
+
+func (h *PriceAlertHandler) GetAll(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	alerts, err := h.service.GetAll(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch alerts")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", alerts)
+}
+
+func (h *PriceAlertHandler) GetByID(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	alert, err := h.service.GetByID(id, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Alert not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", alert)
+}
+
+func (h *PriceAlertHandler) Create(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.CreatePriceAlertRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	alert, err := h.service.Create(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Alert created successfully", alert)
+}
+
+func (h *PriceAlertHandler) Update(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	var req models.UpdatePriceAlertRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	alert, err := h.service.Update(id, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Alert updated successfully", alert)
+}
+
+func (h *PriceAlertHandler) Delete(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.Delete(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Alert deleted successfully", nil)
+}
+
+// GetEvents lists the alerts that fired for the user with in-app delivery
+func (h *PriceAlertHandler) GetEvents(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	events, total, err := h.service.GetEvents(userID, page, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch alert events")
+	}
+
+	return utils.PaginatedResponse(c, events, page, limit, total)
+}
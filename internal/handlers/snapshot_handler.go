@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type SnapshotHandler struct {
+	service *services.SnapshotService
+}
+
+func NewSnapshotHandler(service *services.SnapshotService) *SnapshotHandler {
+	return &SnapshotHandler{service: service}
+}
+
+func (h *SnapshotHandler) GetValueHistory(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	currency, err := h.service.DisplayCurrency(userID, c.QueryParam("currency"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	var pocketID, startDate, endDate *string
+	if value := c.QueryParam("pocket_id"); value != "" {
+		if _, err := uuid.Parse(value); err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid pocket ID")
+		}
+		pocketID = &value
+	}
+	if value := c.QueryParam("start_date"); value != "" {
+		startDate = &value
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		endDate = &value
+	}
+
+	history, err := h.service.GetValueHistory(userID, currency, pocketID, startDate, endDate, c.QueryParam("interval"))
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", history)
+}
+
+// Backfill recomputes the snapshots of a date range for all users
+func (h *SnapshotHandler) Backfill(c echo.Context) error {
+	var req models.BackfillSnapshotsRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	from, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "invalid start date format, use YYYY-MM-DD")
+	}
+	to := time.Now().AddDate(0, 0, -1)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if req.EndDate != "" {
+		to, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "invalid end date format, use YYYY-MM-DD")
+		}
+	}
+
+	result, err := h.service.Backfill(from, to)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Snapshots backfilled successfully", result)
+}
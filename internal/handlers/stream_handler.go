@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+const (
+	// streamHeartbeatInterval keeps idle connections from being closed by proxies
+	streamHeartbeatInterval = 25 * time.Second
+	// streamRetry is how long browsers wait before reconnecting, in milliseconds
+	streamRetry = 5000
+)
+
+type StreamHandler struct {
+	service     *services.StreamService
+	authService *services.AuthService
+}
+
+func NewStreamHandler(service *services.StreamService, authService *services.AuthService) *StreamHandler {
+	return &StreamHandler{service: service, authService: authService}
+}
+
+// Stream sends the user's live events as server-sent events until the client
+// disconnects. It starts with the current portfolio value; a client reconnecting with
+// a Last-Event-ID header, or the last_event_id query parameter, first receives the
+// events it missed, or a reset event when they can no longer be replayed. The stream
+// ends when its access token expires, or at the next heartbeat after the token is
+// revoked or the account deleted, and the client has to reconnect with a new token.
+func (h *StreamHandler) Stream(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		var err error
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || lastID < 0 {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid last event ID")
+		}
+	}
+
+	// Subscribe before replaying, so no event falls between the two
+	subscription := h.service.Subscribe(userID)
+	defer h.service.Unsubscribe(subscription)
+
+	var missed []models.StreamEvent
+	complete := true
+	if lastID > 0 {
+		var err error
+		missed, complete, err = h.service.Replay(userID, lastID)
+		if err != nil {
+			return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load missed events")
+		}
+	}
+
+	portfolio, err := h.service.PortfolioEvent(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load portfolio")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Stops nginx from buffering the stream
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", streamRetry); err != nil {
+		return nil
+	}
+
+	// Replayed events may arrive again from the subscription
+	replayed := make(map[int64]bool, len(missed))
+	if !complete {
+		if err := writeStreamEvent(res, models.StreamEvent{Type: models.StreamEventReset, Data: []byte("{}")}); err != nil {
+			return nil
+		}
+	}
+	for _, event := range missed {
+		if err := writeStreamEvent(res, event); err != nil {
+			return nil
+		}
+		replayed[event.ID] = true
+	}
+	if err := writeStreamEvent(res, *portfolio); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	expiry := time.NewTimer(time.Until(middleware.GetTokenExpiry(c)))
+	defer expiry.Stop()
+	accessToken := middleware.GetAccessToken(c)
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Fell too far behind; the client reconnects and resumes
+				log.Printf("Closed event stream of user %s falling behind", userID)
+				return nil
+			}
+			if replayed[event.ID] {
+				continue
+			}
+			if err := writeStreamEvent(res, event); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-expiry.C:
+			return nil
+		case <-heartbeat.C:
+			if err := h.authService.CheckToken(accessToken, userID); err != nil {
+				log.Printf("Closed event stream of user %s: %v", userID, err)
+				return nil
+			}
+			if _, err := io.WriteString(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeStreamEvent writes the event in the server-sent events format. Only stored
+// events carry an id, so clients resume from the last of those.
+func writeStreamEvent(w io.Writer, event models.StreamEvent) error {
+	if event.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+	return err
+}
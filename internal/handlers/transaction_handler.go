@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/importers"
 	"nabung-emas-api/internal/middleware"
 	"nabung-emas-api/internal/models"
 	"nabung-emas-api/internal/services"
@@ -54,6 +59,11 @@ func (h *TransactionHandler) GetAll(c echo.Context) error {
 		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch transactions")
 	}
 
+	// Amounts are shown in the user's currency at the rate of each transaction date
+	if err := h.service.ToDisplayCurrency(userID, c.QueryParam("currency"), transactions); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -64,6 +74,186 @@ func (h *TransactionHandler) GetAll(c echo.Context) error {
 	return utils.PaginatedResponse(c, transactions, page, limit, total)
 }
 
+func (h *TransactionHandler) Export(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = services.ExportFormatCSV
+	}
+
+	var contentType string
+	switch format {
+	case services.ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case services.ExportFormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return utils.ErrorResponse(c, http.StatusBadRequest, "format must be one of: csv xlsx")
+	}
+
+	// Filters are checked here: once the response has started an error can no longer be returned
+	var pocketID, brand, startDate, endDate *string
+	if value := c.QueryParam("pocket_id"); value != "" {
+		if _, err := uuid.Parse(value); err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "Invalid pocket ID")
+		}
+		pocketID = &value
+	}
+	if value := c.QueryParam("brand"); value != "" {
+		brand = &value
+	}
+	if value := c.QueryParam("start_date"); value != "" {
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "invalid start date format, use YYYY-MM-DD")
+		}
+		startDate = &value
+	}
+	if value := c.QueryParam("end_date"); value != "" {
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "invalid end date format, use YYYY-MM-DD")
+		}
+		endDate = &value
+	}
+
+	export, err := h.service.PrepareExport(userID, format)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	filename := "transactions-" + time.Now().Format("20060102") + "." + format
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	res.WriteHeader(http.StatusOK)
+
+	// Rows are streamed as they are read, so errors after this point can only be logged
+	if err := h.service.Export(userID, export, pocketID, brand, startDate, endDate, res); err != nil {
+		log.Printf("Error exporting transactions for user %s: %v", userID, err)
+	}
+
+	return nil
+}
+
+func (h *TransactionHandler) Import(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "CSV file is required")
+	}
+	if fileHeader.Size > 5<<20 {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "CSV file must be at most 5MB")
+	}
+
+	opts := models.TransactionImportOptions{
+		DateFormat:       c.FormValue("date_format"),
+		DecimalSeparator: c.FormValue("decimal_separator"),
+	}
+	opts.DryRun, _ = strconv.ParseBool(c.FormValue("dry_run"))
+	opts.SkipDuplicates, _ = strconv.ParseBool(c.FormValue("skip_duplicates"))
+	if mapping := c.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			return utils.ErrorResponse(c, http.StatusBadRequest, "mapping must be a JSON object")
+		}
+	}
+	if err := c.Validate(&opts); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read CSV file")
+	}
+	defer file.Close()
+
+	result, err := h.service.Import(userID, file, &opts)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	if opts.DryRun {
+		return utils.SuccessResponse(c, http.StatusOK, "Dry run completed, nothing was imported", result)
+	}
+
+	if len(result.Errors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Import failed validation, nothing was imported",
+			Data:    result,
+		})
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Transactions imported successfully", result)
+}
+
+func (h *TransactionHandler) GetImportPlatforms(c echo.Context) error {
+	var platforms []map[string]string
+	for _, importer := range importers.All() {
+		platforms = append(platforms, map[string]string{
+			"platform": importer.Platform(),
+			"name":     importer.Name(),
+			"brand":    importer.Brand(),
+		})
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", platforms)
+}
+
+func (h *TransactionHandler) ImportPlatform(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	importer, ok := importers.Get(c.Param("platform"))
+	if !ok {
+		return utils.ErrorResponse(c, http.StatusNotFound, "Unsupported platform")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Statement file is required")
+	}
+	if fileHeader.Size > 5<<20 {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Statement file must be at most 5MB")
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+	skipDuplicates, _ := strconv.ParseBool(c.FormValue("skip_duplicates"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, "Failed to read statement file")
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportPlatform(userID, importer, c.FormValue("pocket_id"), file, dryRun, skipDuplicates)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	if dryRun {
+		return utils.SuccessResponse(c, http.StatusOK, "Dry run completed, nothing was imported", result)
+	}
+
+	if len(result.Errors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Message: "Import failed validation, nothing was imported",
+			Data:    result,
+		})
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Transactions imported successfully", result)
+}
+
 func (h *TransactionHandler) GetByID(c echo.Context) error {
 	userID := middleware.GetUserID(c)
 	if userID == "" {
@@ -77,7 +267,12 @@ func (h *TransactionHandler) GetByID(c echo.Context) error {
 		return utils.ErrorResponse(c, http.StatusNotFound, "Transaction not found")
 	}
 
-	return utils.SuccessResponse(c, http.StatusOK, "Success", transaction)
+	converted := []models.Transaction{*transaction}
+	if err := h.service.ToDisplayCurrency(userID, c.QueryParam("currency"), converted); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", converted[0])
 }
 
 func (h *TransactionHandler) Create(c echo.Context) error {
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"nabung-emas-api/internal/middleware"
@@ -82,3 +83,30 @@ func (h *UserHandler) UploadAvatar(c echo.Context) error {
 	// For now, return not implemented
 	return utils.ErrorResponse(c, http.StatusNotImplemented, "Avatar upload not yet implemented")
 }
+
+func (h *UserHandler) DeleteAccount(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.DeleteAccountRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	// Extract token from "Bearer <token>" so it can be revoked
+	parts := strings.Split(c.Request().Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization header format")
+	}
+
+	purgeAt, err := h.userService.DeleteAccount(userID, parts[1], &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Account scheduled for deletion. Log in before the purge date to restore it.", map[string]interface{}{
+		"purge_at": purgeAt,
+	})
+}
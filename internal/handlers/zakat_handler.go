@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/utils"
+)
+
+type ZakatHandler struct {
+	service *services.ZakatService
+}
+
+func NewZakatHandler(service *services.ZakatService) *ZakatHandler {
+	return &ZakatHandler{service: service}
+}
+
+func (h *ZakatHandler) GetReport(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	date := c.QueryParam("date")
+
+	// Parse gold price override if provided
+	var goldPrice *float64
+	if priceStr := c.QueryParam("gold_price"); priceStr != "" {
+		if price, err := strconv.ParseFloat(priceStr, 64); err == nil {
+			goldPrice = &price
+		}
+	}
+
+	report, err := h.service.GetReport(userID, &date, goldPrice)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", report)
+}
+
+func (h *ZakatHandler) GetPayments(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	payments, err := h.service.GetPayments(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch zakat payments")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Success", payments)
+}
+
+func (h *ZakatHandler) CreatePayment(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req models.CreateZakatPaymentRequest
+	if err := utils.BindAndValidate(c, &req); err != nil {
+		return utils.HandleError(c, err)
+	}
+
+	payment, err := h.service.CreatePayment(userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Zakat payment recorded successfully", payment)
+}
+
+func (h *ZakatHandler) DeletePayment(c echo.Context) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+	}
+
+	id := c.Param("id")
+
+	if err := h.service.DeletePayment(id, userID); err != nil {
+		return utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Zakat payment deleted successfully", nil)
+}
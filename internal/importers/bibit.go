@@ -0,0 +1,66 @@
+package importers
+
+import (
+	"io"
+	"regexp"
+
+	"nabung-emas-api/internal/utils"
+)
+
+// BibitImporter parses the text of Bibit Emas PDF statements, where each
+// purchase is a line such as:
+//
+//	05 Jan 2024 Beli Emas 0,1250 gr @ Rp1.045.000 Rp130.625
+type BibitImporter struct{}
+
+func (BibitImporter) Platform() string { return "bibit" }
+func (BibitImporter) Name() string     { return "Bibit Emas" }
+func (BibitImporter) Brand() string    { return "Custom" }
+
+var bibitLine = regexp.MustCompile(`(?i)^(\d{1,2} [a-z]+\.? \d{4})(?: \d{2}:\d{2})? (?:beli|pembelian) emas ([\d.,]+) ?(?:gr|gram|g) @ ?rp ?([\d.,]+)(?: rp ?([\d.,]+))?`)
+
+func (BibitImporter) Parse(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for i, line := range readLines(string(data)) {
+		match := bibitLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		date, err := parseDate(match[1], "02 Jan 2006", "2 Jan 2006")
+		if err != nil {
+			return nil, err
+		}
+		weight, err := utils.ParseNumber(match[2], ",")
+		if err != nil {
+			return nil, err
+		}
+		price, err := utils.ParseNumber(match[3], ",")
+		if err != nil {
+			return nil, err
+		}
+		var total float64
+		if match[4] != "" {
+			if total, err = utils.ParseNumber(match[4], ","); err != nil {
+				return nil, err
+			}
+		}
+
+		entry, err := newEntry(i+1, date, weight, price, total, "")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, errNoEntries
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,214 @@
+// Package importers parses statement exports of Indonesian digital gold
+// platforms into gold purchases that can be imported as transactions.
+package importers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// Entry is a single gold purchase read from a platform statement. Amount is what the
+// statement says was paid, which platforms round their own way.
+type Entry struct {
+	Line         int
+	Date         time.Time
+	Weight       decimal.Decimal
+	PricePerGram decimal.Decimal
+	Amount       decimal.Decimal
+	Reference    string
+}
+
+// Importer parses the statement export of one platform
+type Importer interface {
+	// Platform is the identifier used in the API, e.g. "pegadaian"
+	Platform() string
+	// Name is the display name, also used as the default pocket name
+	Name() string
+	// Brand is the transaction brand the platform's gold is recorded under
+	Brand() string
+	// Parse reads buy entries from a CSV export or the text of a PDF statement.
+	// Sells, transfers and failed orders are skipped.
+	Parse(r io.Reader) ([]Entry, error)
+}
+
+var registry = []Importer{
+	PegadaianImporter{},
+	TokopediaImporter{},
+	PluangImporter{},
+	BibitImporter{},
+}
+
+// Get returns the importer of a platform
+func Get(platform string) (Importer, bool) {
+	for _, importer := range registry {
+		if importer.Platform() == platform {
+			return importer, true
+		}
+	}
+	return nil, false
+}
+
+// All returns every supported importer
+func All() []Importer {
+	return registry
+}
+
+var errNoEntries = errors.New("no gold purchases found in statement")
+
+// readCSV reads all records, detecting whether "," or ";" is the separator.
+// The header row is returned with lowercased, trimmed column names.
+func readCSV(content string) ([]string, [][]string, error) {
+	content = strings.TrimPrefix(content, "\uFEFF")
+	firstLine := content
+	if i := strings.IndexAny(content, "\r\n"); i >= 0 {
+		firstLine = content[:i]
+	}
+
+	cr := csv.NewReader(strings.NewReader(content))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, nil, errors.New("invalid CSV file: " + err.Error())
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	return header, records[1:], nil
+}
+
+// column returns the index of the first header containing any of the names
+func column(header []string, names ...string) int {
+	for _, name := range names {
+		for i, h := range header {
+			if strings.Contains(h, name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// columns resolves required columns, failing with the first missing one
+func columns(header []string, required map[string][]string) (map[string]int, error) {
+	resolved := make(map[string]int, len(required))
+	for key, names := range required {
+		idx := column(header, names...)
+		if idx < 0 {
+			return nil, errors.New("statement is missing column: " + names[0])
+		}
+		resolved[key] = idx
+	}
+	return resolved, nil
+}
+
+func field(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// readLines returns the trimmed, whitespace-normalized lines of a text statement
+func readLines(content string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, strings.Join(strings.Fields(scanner.Text()), " "))
+	}
+	return lines
+}
+
+// looksLikeCSV reports whether the first non-empty line contains a separated header
+func looksLikeCSV(content string, headerHint string) bool {
+	for _, line := range readLines(content) {
+		if line == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+		return strings.Contains(lower, headerHint) && strings.ContainsAny(line, ",;")
+	}
+	return false
+}
+
+var indonesianMonths = map[string]string{
+	"januari": "Jan", "jan": "Jan",
+	"februari": "Feb", "feb": "Feb",
+	"maret": "Mar", "mar": "Mar",
+	"april": "Apr", "apr": "Apr",
+	"mei": "May", "may": "May",
+	"juni": "Jun", "jun": "Jun",
+	"juli": "Jul", "jul": "Jul",
+	"agustus": "Aug", "agu": "Aug", "agt": "Aug", "aug": "Aug",
+	"september": "Sep", "sep": "Sep", "sept": "Sep",
+	"oktober": "Oct", "okt": "Oct", "oct": "Oct",
+	"november": "Nov", "nov": "Nov", "nop": "Nov",
+	"desember": "Dec", "des": "Dec", "dec": "Dec",
+}
+
+// parseDate parses a statement date, accepting Indonesian or English month names
+func parseDate(raw string, layouts ...string) (time.Time, error) {
+	raw = strings.TrimSpace(strings.ReplaceAll(raw, ",", ""))
+
+	words := strings.Fields(raw)
+	for i, word := range words {
+		if month, ok := indonesianMonths[strings.ToLower(strings.TrimSuffix(word, "."))]; ok {
+			words[i] = month
+		}
+	}
+	normalized := strings.Join(words, " ")
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	return time.Time{}, errors.New("unrecognized date: " + raw)
+}
+
+// newEntry builds an entry, deriving the price per gram from the amount paid or the
+// amount paid from the price per gram when the statement lists only one of them.
+// Both are derived from the weight as listed, before it is rounded to the stored
+// precision; Pegadaian lists four decimal places.
+func newEntry(line int, date time.Time, weight, pricePerGram, amount float64, reference string) (Entry, error) {
+	exactWeight := decimal.NewFromFloat(weight)
+	price := decimal.NewFromFloat(pricePerGram)
+	paid := decimal.NewFromFloat(amount)
+
+	var err error
+	if price.IsZero() {
+		if price, err = paid.Div(exactWeight); err != nil {
+			return Entry{}, err
+		}
+	}
+	if paid.IsZero() {
+		if paid, err = exactWeight.Mul(price); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	return Entry{
+		Line:         line,
+		Date:         date,
+		Weight:       exactWeight.Round(decimal.WeightPlaces),
+		PricePerGram: price.Round(decimal.MoneyPlaces),
+		Amount:       paid.Round(decimal.MoneyPlaces),
+		Reference:    reference,
+	}, nil
+}
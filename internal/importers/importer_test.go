@@ -0,0 +1,179 @@
+package importers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+type wantEntry struct {
+	line         int
+	date         time.Time
+	weight       string
+	pricePerGram string
+	amount       string
+	reference    string
+}
+
+func TestParseStatements(t *testing.T) {
+	tests := []struct {
+		platform string
+		file     string
+		want     []wantEntry
+	}{
+		{
+			platform: "pegadaian",
+			file:     "pegadaian.csv",
+			want: []wantEntry{
+				// Four-decimal weights are rounded to the stored precision, the
+				// amount paid follows the weight as listed
+				{2, date(2024, 1, 5), "0.048", "1045000", "49951", "TOP UP TABUNGAN EMAS"},
+				{3, date(2024, 1, 12), "0.1", "1046000", "104600", "TOP UP TABUNGAN EMAS"},
+				{5, date(2024, 2, 3), "1.235", "1052500", "1299311.25", "PEMBELIAN EMAS"},
+			},
+		},
+		{
+			platform: "pegadaian",
+			file:     "pegadaian.txt",
+			want: []wantEntry{
+				{5, date(2024, 1, 5), "0.048", "1045000", "49951", "TOP UP TABUNGAN EMAS"},
+				{7, date(2024, 1, 28), "0.25", "1048000", "262000", "TOP UP TABUNGAN EMAS"},
+			},
+		},
+		{
+			platform: "tokopedia",
+			file:     "tokopedia.csv",
+			want: []wantEntry{
+				{2, date(2024, 1, 5), "0.025", "1045000", "26126", "INV/20240105/001"},
+				// Without a price per gram it is derived from the total
+				{5, date(2024, 5, 1), "1", "1050000", "1050000", "INV/20240501/004"},
+			},
+		},
+		{
+			platform: "pluang",
+			file:     "pluang.csv",
+			want: []wantEntry{
+				// The total is kept as paid, not recomputed from weight and price
+				{2, date(2024, 1, 5), "0.012", "1045000.5", "12853.56", "PLG-001"},
+				{6, date(2024, 2, 10), "2.5", "1046000", "2615000", "PLG-005"},
+			},
+		},
+		{
+			platform: "bibit",
+			file:     "bibit.txt",
+			want: []wantEntry{
+				{2, date(2024, 1, 5), "0.125", "1045000", "130625", ""},
+				{3, date(2024, 1, 12), "0.048", "1046000", "49998.8", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			importer, ok := Get(tt.platform)
+			if !ok {
+				t.Fatalf("no importer for %s", tt.platform)
+			}
+
+			file, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			entries, err := importer.Parse(file)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d: %+v", len(entries), len(tt.want), entries)
+			}
+
+			for i, want := range tt.want {
+				got := entries[i]
+				if got.Line != want.line {
+					t.Errorf("entry %d: line = %d, want %d", i, got.Line, want.line)
+				}
+				if !got.Date.Equal(want.date) {
+					t.Errorf("entry %d: date = %s, want %s", i, got.Date, want.date)
+				}
+				if !got.Weight.Equal(dec(t, want.weight)) {
+					t.Errorf("entry %d: weight = %s, want %s", i, got.Weight, want.weight)
+				}
+				if !got.PricePerGram.Equal(dec(t, want.pricePerGram)) {
+					t.Errorf("entry %d: price per gram = %s, want %s", i, got.PricePerGram, want.pricePerGram)
+				}
+				if !got.Amount.Equal(dec(t, want.amount)) {
+					t.Errorf("entry %d: amount = %s, want %s", i, got.Amount, want.amount)
+				}
+				if got.Reference != want.reference {
+					t.Errorf("entry %d: reference = %q, want %q", i, got.Reference, want.reference)
+				}
+			}
+		})
+	}
+}
+
+func TestParseStatementErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		content  string
+		want     string
+	}{
+		{"no purchases", "bibit", "05 Jan 2024 Jual Emas 0,0500 gr @ Rp1.050.000\n", errNoEntries.Error()},
+		{"empty CSV", "pluang", "", "CSV file is empty"},
+		{"missing column", "tokopedia", "Tanggal,Jenis Transaksi,Total\n", "missing column: jumlah"},
+		{"invalid date", "pluang", "Date,Asset,Transaction Type,Quantity,Total Amount\n05/01/2024,Gold,Buy,1,1000000\n", "unrecognized date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, _ := Get(tt.platform)
+			_, err := importer.Parse(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Time
+	}{
+		{"05 Jan 2024", date(2024, 1, 5)},
+		{"5 Agustus 2024", date(2024, 8, 5)},
+		{"17 Okt. 2023", date(2023, 10, 17)},
+		{"01 Mei 2024, 08:05", date(2024, 5, 1)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.raw, "02 Jan 2006", "2 Jan 2006", "02 Jan 2006 15:04")
+		if err != nil {
+			t.Errorf("parseDate(%q): %v", tt.raw, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %s, want %s", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package importers
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"nabung-emas-api/internal/utils"
+)
+
+// PegadaianImporter parses Pegadaian Digital (Tabungan Emas) account statements.
+//
+// CSV exports have the columns Tanggal, Keterangan, Debet (gr), Kredit (gr),
+// Harga (Rp) and Saldo (gr). The PDF statement has the same columns per line:
+//
+//	05/01/2024 TOP UP TABUNGAN EMAS - 0,1000 1.045.000 1,2345
+type PegadaianImporter struct{}
+
+func (PegadaianImporter) Platform() string { return "pegadaian" }
+func (PegadaianImporter) Name() string     { return "Pegadaian Digital" }
+func (PegadaianImporter) Brand() string    { return "Pegadaian" }
+
+var pegadaianLine = regexp.MustCompile(`^(\d{2}[/-]\d{2}[/-]\d{4})\s+(.+?)\s+(-|[\d.,]+)\s+(-|[\d.,]+)\s+([\d.,]+)\s+([\d.,]+)$`)
+
+func (p PegadaianImporter) Parse(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+
+	var entries []Entry
+	if looksLikeCSV(content, "keterangan") {
+		entries, err = p.parseCSV(content)
+	} else {
+		entries, err = p.parseText(content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errNoEntries
+	}
+
+	return entries, nil
+}
+
+func (PegadaianImporter) parseCSV(content string) ([]Entry, error) {
+	header, records, err := readCSV(content)
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := columns(header, map[string][]string{
+		"date":        {"tanggal"},
+		"description": {"keterangan"},
+		"credit":      {"kredit"},
+		"price":       {"harga"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for i, record := range records {
+		description := field(record, cols["description"])
+		if !isPegadaianPurchase(description) {
+			continue
+		}
+
+		entry, err := pegadaianEntry(i+2, field(record, cols["date"]), description, field(record, cols["credit"]), field(record, cols["price"]))
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (PegadaianImporter) parseText(content string) ([]Entry, error) {
+	var entries []Entry
+	for i, line := range readLines(content) {
+		match := pegadaianLine.FindStringSubmatch(line)
+		if match == nil || !isPegadaianPurchase(match[2]) {
+			continue
+		}
+
+		entry, err := pegadaianEntry(i+1, match[1], match[2], match[4], match[5])
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func isPegadaianPurchase(description string) bool {
+	lower := strings.ToLower(description)
+	return strings.Contains(lower, "top up") || strings.Contains(lower, "beli") || strings.Contains(lower, "pembelian")
+}
+
+func pegadaianEntry(line int, rawDate, description, rawCredit, rawPrice string) (*Entry, error) {
+	if rawCredit == "" || rawCredit == "-" {
+		return nil, nil
+	}
+
+	date, err := parseDate(strings.ReplaceAll(rawDate, "-", "/"), "02/01/2006", "02/01/2006 15:04", "02/01/2006 15:04:05")
+	if err != nil {
+		return nil, err
+	}
+	weight, err := utils.ParseNumber(rawCredit, ",")
+	if err != nil {
+		return nil, err
+	}
+	price, err := utils.ParseNumber(rawPrice, ",")
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := newEntry(line, date, weight, price, 0, description)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
@@ -0,0 +1,89 @@
+package importers
+
+import (
+	"io"
+	"strings"
+
+	"nabung-emas-api/internal/utils"
+)
+
+// PluangImporter parses Pluang transaction history CSV exports with the columns
+// Date, Asset, Transaction Type, Quantity, Price, Total Amount, Status and
+// Transaction ID. Numbers use English formatting.
+type PluangImporter struct{}
+
+func (PluangImporter) Platform() string { return "pluang" }
+func (PluangImporter) Name() string     { return "Pluang" }
+func (PluangImporter) Brand() string    { return "Custom" }
+
+func (PluangImporter) Parse(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header, records, err := readCSV(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := columns(header, map[string][]string{
+		"date":     {"date"},
+		"asset":    {"asset"},
+		"type":     {"type"},
+		"quantity": {"quantity"},
+		"total":    {"total", "amount"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	priceCol := column(header, "price")
+	statusCol := column(header, "status")
+	referenceCol := column(header, "transaction id", "order id")
+
+	var entries []Entry
+	for i, record := range records {
+		asset := strings.ToLower(field(record, cols["asset"]))
+		if !strings.Contains(asset, "gold") && !strings.Contains(asset, "emas") {
+			continue
+		}
+		if strings.ToLower(field(record, cols["type"])) != "buy" {
+			continue
+		}
+		if status := strings.ToLower(field(record, statusCol)); status != "" && status != "completed" && status != "success" {
+			continue
+		}
+
+		date, err := parseDate(field(record, cols["date"]), "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02")
+		if err != nil {
+			return nil, err
+		}
+		weight, err := utils.ParseNumber(field(record, cols["quantity"]), ".")
+		if err != nil {
+			return nil, err
+		}
+		total, err := utils.ParseNumber(field(record, cols["total"]), ".")
+		if err != nil {
+			return nil, err
+		}
+
+		var price float64
+		if raw := field(record, priceCol); raw != "" {
+			if price, err = utils.ParseNumber(raw, "."); err != nil {
+				return nil, err
+			}
+		}
+
+		entry, err := newEntry(i+2, date, weight, price, total, field(record, referenceCol))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, errNoEntries
+	}
+
+	return entries, nil
+}
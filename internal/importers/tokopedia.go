@@ -0,0 +1,86 @@
+package importers
+
+import (
+	"io"
+	"strings"
+
+	"nabung-emas-api/internal/utils"
+)
+
+// TokopediaImporter parses Tokopedia Emas transaction history CSV exports with
+// the columns Tanggal, Jenis Transaksi, Jumlah (gram), Harga per Gram, Total and Status.
+// Dates look like "05 Jan 2024, 10:15".
+type TokopediaImporter struct{}
+
+func (TokopediaImporter) Platform() string { return "tokopedia" }
+func (TokopediaImporter) Name() string     { return "Tokopedia Emas" }
+
+// Tokopedia Emas is held in custody by Pegadaian
+func (TokopediaImporter) Brand() string { return "Pegadaian" }
+
+func (TokopediaImporter) Parse(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	header, records, err := readCSV(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := columns(header, map[string][]string{
+		"date":   {"tanggal"},
+		"type":   {"jenis"},
+		"weight": {"jumlah", "gram"},
+		"total":  {"total"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	priceCol := column(header, "harga")
+	statusCol := column(header, "status")
+	referenceCol := column(header, "invoice", "no. transaksi", "id transaksi")
+
+	var entries []Entry
+	for i, record := range records {
+		if !strings.Contains(strings.ToLower(field(record, cols["type"])), "beli") {
+			continue
+		}
+		if status := strings.ToLower(field(record, statusCol)); status != "" && status != "berhasil" && status != "sukses" {
+			continue
+		}
+
+		date, err := parseDate(field(record, cols["date"]), "02 Jan 2006 15:04", "2 Jan 2006 15:04", "02 Jan 2006", "2 Jan 2006")
+		if err != nil {
+			return nil, err
+		}
+		weight, err := utils.ParseNumber(field(record, cols["weight"]), ",")
+		if err != nil {
+			return nil, err
+		}
+		total, err := utils.ParseNumber(field(record, cols["total"]), ",")
+		if err != nil {
+			return nil, err
+		}
+
+		var price float64
+		if raw := field(record, priceCol); raw != "" {
+			if price, err = utils.ParseNumber(raw, ","); err != nil {
+				return nil, err
+			}
+		}
+
+		entry, err := newEntry(i+2, date, weight, price, total, field(record, referenceCol))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, errNoEntries
+	}
+
+	return entries, nil
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"nabung-emas-api/internal/config"
 	"nabung-emas-api/internal/repositories"
@@ -14,12 +15,14 @@ import (
 type AuthMiddleware struct {
 	config             *config.Config
 	tokenBlacklistRepo *repositories.TokenBlacklistRepository
+	userRepo           *repositories.UserRepository
 }
 
-func NewAuthMiddleware(cfg *config.Config, tokenBlacklistRepo *repositories.TokenBlacklistRepository) *AuthMiddleware {
+func NewAuthMiddleware(cfg *config.Config, tokenBlacklistRepo *repositories.TokenBlacklistRepository, userRepo *repositories.UserRepository) *AuthMiddleware {
 	return &AuthMiddleware{
 		config:             cfg,
 		tokenBlacklistRepo: tokenBlacklistRepo,
+		userRepo:           userRepo,
 	}
 }
 
@@ -36,28 +39,79 @@ func (m *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid authorization header format")
 		}
 
-		tokenString := parts[1]
+		return m.authenticate(c, next, parts[1])
+	}
+}
 
-		// Check if token is blacklisted
-		isBlacklisted, err := m.tokenBlacklistRepo.IsBlacklisted(tokenString)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify token")
-		}
-		if isBlacklisted {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Token has been revoked")
+// RequireStreamAuth is RequireAuth for the event stream. Browsers cannot set headers
+// on an EventSource, so the token may also be given in the access_token query
+// parameter instead.
+func (m *AuthMiddleware) RequireStreamAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	requireAuth := m.RequireAuth(next)
+	return func(c echo.Context) error {
+		if c.Request().Header.Get("Authorization") != "" {
+			return requireAuth(c)
 		}
 
-		// Validate token
-		claims, err := utils.ValidateToken(tokenString, m.config.JWTSecret)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+		tokenString := c.QueryParam("access_token")
+		if tokenString == "" {
+			return echo.NewHTTPError(http.StatusUnauthorized, "Missing authorization header")
 		}
 
-		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
+		return m.authenticate(c, next, tokenString)
+	}
+}
+
+// authenticate checks the access token and passes the request on as its user
+func (m *AuthMiddleware) authenticate(c echo.Context, next echo.HandlerFunc, tokenString string) error {
+	// Check if token is blacklisted
+	isBlacklisted, err := m.tokenBlacklistRepo.IsBlacklisted(tokenString)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify token")
+	}
+	if isBlacklisted {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Token has been revoked")
+	}
+
+	// Validate token
+	claims, err := utils.ValidateToken(tokenString, m.config.JWTSecret)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+	}
 
-		return next(c)
+	// Every token of a deleted account stops working, not only the one that deleted it,
+	// until logging in again restores the account
+	active, err := m.userRepo.IsActive(claims.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify token")
+	}
+	if !active {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Account has been deleted")
+	}
+
+	// Set user info in context
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("access_token", tokenString)
+	if claims.ExpiresAt != nil {
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
+	}
+
+	return next(c)
+}
+
+// RequireAdmin allows only users whose email is listed in ADMIN_EMAILS. It must
+// run after RequireAuth.
+func (m *AuthMiddleware) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		email := GetUserEmail(c)
+		for _, admin := range m.config.AdminEmails {
+			if email != "" && strings.EqualFold(email, admin) {
+				return next(c)
+			}
+		}
+
+		return echo.NewHTTPError(http.StatusForbidden, "Admin access required")
 	}
 }
 
@@ -76,3 +130,22 @@ func GetUserEmail(c echo.Context) string {
 	}
 	return email
 }
+
+// GetAccessToken returns the access token the request was authenticated with
+func GetAccessToken(c echo.Context) string {
+	token, ok := c.Get("access_token").(string)
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// GetTokenExpiry returns when the access token of the request expires, or the zero
+// time when unknown
+func GetTokenExpiry(c echo.Context) time.Time {
+	expiresAt, ok := c.Get("token_expires_at").(time.Time)
+	if !ok {
+		return time.Time{}
+	}
+	return expiresAt
+}
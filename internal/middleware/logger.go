@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -9,7 +10,20 @@ import (
 
 func SetupLogger() echo.MiddlewareFunc {
 	return middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "${time_rfc3339} | ${status} | ${method} ${uri} | ${latency_human} | ${remote_ip}\n",
+		Format: "${time_rfc3339} | ${status} | ${method} ${custom} | ${latency_human} | ${remote_ip}\n",
 		CustomTimeFormat: time.RFC3339,
+		CustomTagFunc: logURI,
 	})
 }
+
+// logURI writes the request URI with an access token given in the query hidden
+func logURI(c echo.Context, buf *bytes.Buffer) (int, error) {
+	uri := *c.Request().URL
+	query := uri.Query()
+	if query.Has("access_token") {
+		query.Set("access_token", "REDACTED")
+		uri.RawQuery = query.Encode()
+	}
+
+	return buf.WriteString(uri.RequestURI())
+}
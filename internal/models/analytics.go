@@ -1,55 +1,72 @@
 package models
 
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
 type PortfolioAnalytics struct {
-	TotalValue           float64              `json:"total_value"`
-	TotalWeight          float64              `json:"total_weight"`
-	AveragePricePerGram  float64              `json:"average_price_per_gram"`
-	CurrentMarketPrice   *float64             `json:"current_market_price,omitempty"`
-	CurrentValue         *float64             `json:"current_value,omitempty"`
-	ProfitLoss           *float64             `json:"profit_loss,omitempty"`
+	Currency             string               `json:"currency"`
+	TotalValue           decimal.Decimal      `json:"total_value"`
+	TotalFees            decimal.Decimal      `json:"total_fees"`
+	TotalCost            decimal.Decimal      `json:"total_cost"`
+	TotalWeight          decimal.Decimal      `json:"total_weight"`
+	TotalFineWeight      decimal.Decimal      `json:"total_fine_weight"`
+	AveragePricePerGram  decimal.Decimal      `json:"average_price_per_gram"`
+	CurrentMarketPrice   *decimal.Decimal     `json:"current_market_price,omitempty"`
+	CurrentValue         *decimal.Decimal     `json:"current_value,omitempty"`
+	ProfitLoss           *decimal.Decimal     `json:"profit_loss,omitempty"`
 	ProfitLossPercentage *float64             `json:"profit_loss_percentage,omitempty"`
 	Distribution         []PocketDistribution `json:"distribution"`
+	Installments         *InstallmentSummary  `json:"installments"`
 }
 
 type PocketDistribution struct {
-	PocketID        string  `json:"pocket_id"`
-	PocketName      string  `json:"pocket_name"`
-	TypePocketName  string  `json:"type_pocket_name"`
-	TypePocketColor string  `json:"type_pocket_color"`
-	Weight          float64 `json:"weight"`
-	Value           float64 `json:"value"`
-	Percentage      float64 `json:"percentage"`
+	PocketID        string          `json:"pocket_id"`
+	PocketName      string          `json:"pocket_name"`
+	TypePocketName  string          `json:"type_pocket_name"`
+	TypePocketColor string          `json:"type_pocket_color"`
+	Weight          decimal.Decimal `json:"weight"`
+	FineWeight      decimal.Decimal `json:"fine_weight"`
+	Value           decimal.Decimal `json:"value"`
+	Percentage      float64         `json:"percentage"`
 }
 
 type MonthlyPurchaseData struct {
-	Month               string  `json:"month"`
-	Weight              float64 `json:"weight"`
-	Amount              float64 `json:"amount"`
-	Count               int     `json:"count"`
-	AveragePricePerGram float64 `json:"average_price_per_gram"`
+	Month               string          `json:"month"`
+	Weight              decimal.Decimal `json:"weight"`
+	Amount              decimal.Decimal `json:"amount"`
+	Count               int             `json:"count"`
+	AveragePricePerGram decimal.Decimal `json:"average_price_per_gram"`
 }
 
 type MonthlyPurchaseAnalytics struct {
+	Currency               string                `json:"currency"`
 	MonthlyData            []MonthlyPurchaseData `json:"monthly_data"`
-	AverageMonthlyPurchase float64               `json:"average_monthly_purchase"`
-	TotalPeriodWeight      float64               `json:"total_period_weight"`
-	TotalPeriodAmount      float64               `json:"total_period_amount"`
+	AverageMonthlyPurchase decimal.Decimal       `json:"average_monthly_purchase"`
+	TotalPeriodWeight      decimal.Decimal       `json:"total_period_weight"`
+	TotalPeriodAmount      decimal.Decimal       `json:"total_period_amount"`
 }
 
 type BrandDistribution struct {
-	Brand            string  `json:"brand"`
-	Weight           float64 `json:"weight"`
-	Value            float64 `json:"value"`
-	TransactionCount int     `json:"transaction_count"`
-	Percentage       float64 `json:"percentage"`
+	Brand            string          `json:"brand"`
+	Weight           decimal.Decimal `json:"weight"`
+	FineWeight       decimal.Decimal `json:"fine_weight"`
+	Value            decimal.Decimal `json:"value"`
+	TotalFees        decimal.Decimal `json:"total_fees"`
+	TotalCost        decimal.Decimal `json:"total_cost"`
+	Currency         string          `json:"currency"`
+	TransactionCount int             `json:"transaction_count"`
+	Percentage       float64         `json:"percentage"`
 }
 
 type TrendData struct {
-	Period              string  `json:"period"`
-	TotalWeight         float64 `json:"total_weight"`
-	TotalValue          float64 `json:"total_value"`
-	TransactionCount    int     `json:"transaction_count"`
-	AveragePricePerGram float64 `json:"average_price_per_gram"`
+	Period              string          `json:"period"`
+	TotalWeight         decimal.Decimal `json:"total_weight"`
+	TotalValue          decimal.Decimal `json:"total_value"`
+	TransactionCount    int             `json:"transaction_count"`
+	AveragePricePerGram decimal.Decimal `json:"average_price_per_gram"`
 }
 
 type TrendAnalytics struct {
@@ -58,28 +75,190 @@ type TrendAnalytics struct {
 }
 
 type TrendsSummary struct {
-	TotalWeight         float64 `json:"total_weight"`
-	TotalValue          float64 `json:"total_value"`
-	TransactionCount    int     `json:"transaction_count"`
-	AveragePricePerGram float64 `json:"average_price_per_gram"`
-	LowestPricePerGram  float64 `json:"lowest_price_per_gram"`
-	HighestPricePerGram float64 `json:"highest_price_per_gram"`
+	TotalWeight         decimal.Decimal `json:"total_weight"`
+	TotalValue          decimal.Decimal `json:"total_value"`
+	TransactionCount    int             `json:"transaction_count"`
+	AveragePricePerGram decimal.Decimal `json:"average_price_per_gram"`
+	LowestPricePerGram  decimal.Decimal `json:"lowest_price_per_gram"`
+	HighestPricePerGram decimal.Decimal `json:"highest_price_per_gram"`
 }
 
 type DashboardSummary struct {
-	Portfolio          PortfolioSummary `json:"portfolio"`
-	TopPockets         []Pocket         `json:"top_pockets"`
-	RecentTransactions []Transaction    `json:"recent_transactions"`
+	Portfolio          PortfolioSummary   `json:"portfolio"`
+	TopPockets         []Pocket           `json:"top_pockets"`
+	Goals              []PocketGoal       `json:"goals"`
+	Pledges            PledgeSummary      `json:"pledges"`
+	Installments       InstallmentSummary `json:"installments"`
+	RecentTransactions []Transaction      `json:"recent_transactions"`
 }
 
 type PortfolioSummary struct {
-	TotalValue           float64  `json:"total_value"`
-	TotalWeight          float64  `json:"total_weight"`
-	TotalPockets         int      `json:"total_pockets"`
-	TotalTransactions    int      `json:"total_transactions"`
-	AveragePricePerGram  float64  `json:"average_price_per_gram"`
-	CurrentGoldPrice     *float64 `json:"current_gold_price,omitempty"`
-	CurrentValue         *float64 `json:"current_value,omitempty"`
-	ProfitLoss           *float64 `json:"profit_loss,omitempty"`
-	ProfitLossPercentage *float64 `json:"profit_loss_percentage,omitempty"`
+	Currency                string           `json:"currency"`
+	TotalValue              decimal.Decimal  `json:"total_value"`
+	TotalFees               decimal.Decimal  `json:"total_fees"`
+	TotalCost               decimal.Decimal  `json:"total_cost"`
+	TotalWeight             decimal.Decimal  `json:"total_weight"`
+	TotalFineWeight         decimal.Decimal  `json:"total_fine_weight"`
+	PledgedWeight           decimal.Decimal  `json:"pledged_weight"`
+	AvailableWeight         decimal.Decimal  `json:"available_weight"`
+	OutstandingLoan         decimal.Decimal  `json:"outstanding_loan"`
+	TotalPockets            int              `json:"total_pockets"`
+	TotalTransactions       int              `json:"total_transactions"`
+	AveragePricePerGram     decimal.Decimal  `json:"average_price_per_gram"`
+	AveragePricePerFineGram decimal.Decimal  `json:"average_price_per_fine_gram"`
+	CurrentGoldPrice        *decimal.Decimal `json:"current_gold_price,omitempty"`
+	CurrentValue            *decimal.Decimal `json:"current_value,omitempty"`
+	ProfitLoss              *decimal.Decimal `json:"profit_loss,omitempty"`
+	ProfitLossPercentage    *float64         `json:"profit_loss_percentage,omitempty"`
+}
+
+// BrandPremium separates what was paid for a brand into the spot-equivalent cost (fine
+// weight x the stored market price on the purchase date) and the premiums on top of
+// it: the dealer markup in the price per gram plus itemized fees. Only transactions
+// with a market price from the week before their date are compared; the summary
+// uses the same fields for all brands together.
+type BrandPremium struct {
+	Brand              string                     `json:"brand,omitempty"`
+	Currency           string                     `json:"currency"`
+	Weight             decimal.Decimal            `json:"weight"`
+	FineWeight         decimal.Decimal            `json:"fine_weight"`
+	TransactionCount   int                        `json:"transaction_count"`
+	TotalCost          decimal.Decimal            `json:"total_cost"`
+	TotalFees          decimal.Decimal            `json:"total_fees"`
+	FeesByType         map[string]decimal.Decimal `json:"fees_by_type"`
+	PricedTransactions int                        `json:"priced_transactions"`
+	PricedFineWeight   decimal.Decimal            `json:"priced_fine_weight"`
+	SpotEquivalentCost decimal.Decimal            `json:"spot_equivalent_cost"`
+	DealerPremium      decimal.Decimal            `json:"dealer_premium"`
+	FeePremium         decimal.Decimal            `json:"fee_premium"`
+	TotalPremium       decimal.Decimal            `json:"total_premium"`
+	PremiumPerFineGram decimal.Decimal            `json:"premium_per_fine_gram"`
+	PremiumPercentage  float64                    `json:"premium_percentage"`
+}
+
+type PremiumAnalytics struct {
+	Brands  []BrandPremium `json:"brands"`
+	Summary BrandPremium   `json:"summary"`
+}
+
+// ReturnPerformance compares what was paid into the portfolio or a pocket with its
+// value at today's gold price. Return figures are percentages. The money-weighted
+// return (XIRR) weighs each purchase by its amount and date; the time-weighted return
+// chains the gold price performance between purchases, so it does not depend on how
+// much was bought when. Annualized figures are omitted for holdings younger than a
+// year, where they would extrapolate a short period.
+type ReturnPerformance struct {
+	PocketID                      string          `json:"pocket_id,omitempty"`
+	PocketName                    string          `json:"pocket_name,omitempty"`
+	FirstTransactionDate          time.Time       `json:"first_transaction_date"`
+	HoldingDays                   int             `json:"holding_days"`
+	TransactionCount              int             `json:"transaction_count"`
+	FineWeight                    decimal.Decimal `json:"fine_weight"`
+	TotalCost                     decimal.Decimal `json:"total_cost"`
+	CurrentValue                  decimal.Decimal `json:"current_value"`
+	ProfitLoss                    decimal.Decimal `json:"profit_loss"`
+	SimpleReturn                  *float64        `json:"simple_return"`
+	MoneyWeightedReturn           *float64        `json:"money_weighted_return"`
+	AnnualizedMoneyWeightedReturn *float64        `json:"annualized_money_weighted_return"`
+	TimeWeightedReturn            *float64        `json:"time_weighted_return"`
+	AnnualizedTimeWeightedReturn  *float64        `json:"annualized_time_weighted_return"`
+	EstimatedValuations           int             `json:"estimated_valuations"`
+}
+
+// PerformanceAnalytics reports returns at CurrentGoldPrice, in rupiah per gram of
+// fine gold. Purchase days without a stored gold price from the week before are
+// valued at the average price paid that day and counted in EstimatedValuations.
+type PerformanceAnalytics struct {
+	Currency         string              `json:"currency"`
+	CurrentGoldPrice decimal.Decimal     `json:"current_gold_price"`
+	PriceDate        time.Time           `json:"price_date"`
+	Portfolio        *ReturnPerformance  `json:"portfolio"`
+	Pockets          []ReturnPerformance `json:"pockets"`
+}
+
+// BenchmarkResult is what the rupiah of every purchase would be worth had it gone into
+// the benchmark on the purchase day instead. Return figures are percentages, as in
+// ReturnPerformance; GoldDifference is the actual gold value minus this value.
+type BenchmarkResult struct {
+	Benchmark                     string          `json:"benchmark"`
+	Name                          string          `json:"name"`
+	LastValueDate                 time.Time       `json:"last_value_date"`
+	TotalCost                     decimal.Decimal `json:"total_cost"`
+	CurrentValue                  decimal.Decimal `json:"current_value"`
+	ProfitLoss                    decimal.Decimal `json:"profit_loss"`
+	SimpleReturn                  *float64        `json:"simple_return"`
+	MoneyWeightedReturn           *float64        `json:"money_weighted_return"`
+	AnnualizedMoneyWeightedReturn *float64        `json:"annualized_money_weighted_return"`
+	GoldDifference                decimal.Decimal `json:"gold_difference"`
+	GoldOutperformed              bool            `json:"gold_outperformed"`
+}
+
+// BenchmarkComparison sets the actual gold performance beside the benchmarks.
+// Unavailable lists benchmarks whose stored values do not reach back to the first
+// purchase.
+type BenchmarkComparison struct {
+	Currency         string             `json:"currency"`
+	CurrentGoldPrice decimal.Decimal    `json:"current_gold_price"`
+	PriceDate        time.Time          `json:"price_date"`
+	Gold             *ReturnPerformance `json:"gold"`
+	Benchmarks       []BenchmarkResult  `json:"benchmarks"`
+	Unavailable      []string           `json:"unavailable"`
+}
+
+// DCAMonth sums the purchases of a month. DifferencePercentage compares the price
+// paid per fine gram, before fees, with the month's average stored gold price.
+type DCAMonth struct {
+	Month                   string           `json:"month"`
+	TransactionCount        int              `json:"transaction_count"`
+	TotalPrice              decimal.Decimal  `json:"total_price"`
+	TotalCost               decimal.Decimal  `json:"total_cost"`
+	FineWeight              decimal.Decimal  `json:"fine_weight"`
+	AveragePricePerFineGram decimal.Decimal  `json:"average_price_per_fine_gram"`
+	AverageMarketPrice      *decimal.Decimal `json:"average_market_price"`
+	DifferencePercentage    *float64         `json:"difference_percentage"`
+}
+
+// DCASimulation spreads the same money evenly over every month of the period, bought
+// at the stored gold price on the same day of each month as the first purchase.
+// Months without a price from the week before that day are skipped and their share
+// spread over the others. ActualSpotFineWeight is the fine gold the actual purchases
+// would have bought at the stored price of their own days, so both sides leave out
+// dealer premiums; a positive difference means the actual timing beat the schedule.
+type DCASimulation struct {
+	MonthlyAmount           decimal.Decimal `json:"monthly_amount"`
+	Months                  int             `json:"months"`
+	SkippedMonths           int             `json:"skipped_months"`
+	TotalAmount             decimal.Decimal `json:"total_amount"`
+	FineWeight              decimal.Decimal `json:"fine_weight"`
+	AveragePricePerFineGram decimal.Decimal `json:"average_price_per_fine_gram"`
+	ActualSpotFineWeight    decimal.Decimal `json:"actual_spot_fine_weight"`
+	FineWeightDifference    decimal.Decimal `json:"fine_weight_difference"`
+	DifferencePercentage    float64         `json:"difference_percentage"`
+}
+
+// DCAReport compares the prices paid between the first and last purchase with the
+// stored gold prices of the same period, in rupiah per gram of fine gold.
+// AverageSpotAtPurchases is the market price the money actually met on the purchase
+// days with a stored price; TimingPercentage is how far it lies from the period's
+// average market price, negative when the purchases were timed below average.
+type DCAReport struct {
+	Currency                string           `json:"currency"`
+	PocketID                *string          `json:"pocket_id"`
+	StartDate               time.Time        `json:"start_date"`
+	EndDate                 time.Time        `json:"end_date"`
+	TransactionCount        int              `json:"transaction_count"`
+	PricedTransactions      int              `json:"priced_transactions"`
+	TotalPrice              decimal.Decimal  `json:"total_price"`
+	TotalCost               decimal.Decimal  `json:"total_cost"`
+	FineWeight              decimal.Decimal  `json:"fine_weight"`
+	AveragePricePerFineGram decimal.Decimal  `json:"average_price_per_fine_gram"`
+	AverageCostPerFineGram  decimal.Decimal  `json:"average_cost_per_fine_gram"`
+	AverageMarketPrice      *decimal.Decimal `json:"average_market_price"`
+	MarketPriceDays         int              `json:"market_price_days"`
+	AverageSpotAtPurchases  *decimal.Decimal `json:"average_spot_at_purchases"`
+	TimingPercentage        *float64         `json:"timing_percentage"`
+	Months                  []DCAMonth       `json:"months"`
+	BestMonth               *DCAMonth        `json:"best_month"`
+	WorstMonth              *DCAMonth        `json:"worst_month"`
+	Simulation              *DCASimulation   `json:"simulation"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// BenchmarkValue is the value of a benchmark series on a day: a rate in percent a
+// year for bi_rate, otherwise a price or index level
+type BenchmarkValue struct {
+	ID        string          `json:"id"`
+	Benchmark string          `json:"benchmark"`
+	Date      time.Time       `json:"date"`
+	Value     decimal.Decimal `json:"value"`
+	Source    string          `json:"source"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// BenchmarkSeries describes a benchmark and the range of its stored values
+type BenchmarkSeries struct {
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Rate        bool       `json:"rate"`
+	ValueCount  int        `json:"value_count"`
+	FirstDate   *time.Time `json:"first_date"`
+	LastDate    *time.Time `json:"last_date"`
+}
+
+type BenchmarkImportResult struct {
+	Imported   int            `json:"imported"`
+	Benchmarks map[string]int `json:"benchmarks"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Brand struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	LogoURL   *string   `json:"logo_url"`
+	Color     *string   `json:"color"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateBrandRequest struct {
+	Name    string  `json:"name" validate:"required,min=2,max=50"`
+	LogoURL *string `json:"logo_url" validate:"omitempty,url,max=500"`
+	Color   *string `json:"color" validate:"omitempty,max=50"`
+	Active  *bool   `json:"active"`
+}
+
+type UpdateBrandRequest struct {
+	Name    string  `json:"name" validate:"omitempty,min=2,max=50"`
+	LogoURL *string `json:"logo_url" validate:"omitempty,max=500"`
+	Color   *string `json:"color" validate:"omitempty,max=50"`
+	Active  *bool   `json:"active"`
+}
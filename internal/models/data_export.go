@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+)
+
+type DataExport struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      string     `json:"status"`
+	FileKey     *string    `json:"-"`
+	FileSize    *int64     `json:"file_size,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	DownloadURL *string    `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
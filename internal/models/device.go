@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type PushDevice struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Platform   string     `json:"platform"`
+	Token      string     `json:"token"`
+	P256DH     *string    `json:"-"`
+	Auth       *string    `json:"-"`
+	Language   *string    `json:"language,omitempty"`
+	Name       *string    `json:"name,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// RegisterDeviceRequest registers an app installation or browser subscription. For
+// webpush, token is the subscription endpoint and keys holds its p256dh and auth keys.
+// Language overrides the language of the user's settings on this device.
+type RegisterDeviceRequest struct {
+	Platform string       `json:"platform" validate:"required,oneof=fcm apns webpush"`
+	Token    string       `json:"token" validate:"required,max=4096"`
+	Keys     *WebPushKeys `json:"keys"`
+	Language *string      `json:"language" validate:"omitempty,oneof=en id"`
+	Name     *string      `json:"name" validate:"omitempty,max=100"`
+}
+
+type WebPushKeys struct {
+	P256DH string `json:"p256dh" validate:"required,max=200"`
+	Auth   string `json:"auth" validate:"required,max=50"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// ExchangeRate is the rupiah price of one unit of a currency on a day
+type ExchangeRate struct {
+	ID        string          `json:"id"`
+	Date      time.Time       `json:"date"`
+	Currency  string          `json:"currency"`
+	Rate      decimal.Decimal `json:"rate"`
+	Source    string          `json:"source"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ExchangeRateImportResult struct {
+	Imported int        `json:"imported"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+}
+
+type SyncExchangeRatesRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"omitempty"`
+}
@@ -18,3 +18,20 @@ type CurrentGoldPrice struct {
 	Change24h           *float64  `json:"change_24h,omitempty"`
 	ChangePercentage24h *float64  `json:"change_percentage_24h,omitempty"`
 }
+
+type GoldPriceInput struct {
+	Date         string  `json:"date" validate:"required"`
+	PricePerGram float64 `json:"price_per_gram" validate:"required,gte=1000,lte=10000000"`
+	Source       string  `json:"source" validate:"required,max=50"`
+}
+
+type StoreGoldPricesRequest struct {
+	Prices []GoldPriceInput `json:"prices" validate:"required,min=1,max=1000,dive"`
+}
+
+type StoreGoldPricesResult struct {
+	Stored int `json:"stored"`
+	// Latest lists the prices that are now the newest of their source; price alerts
+	// are evaluated against them
+	Latest []GoldPrice `json:"latest"`
+}
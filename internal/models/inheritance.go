@@ -0,0 +1,41 @@
+package models
+
+type FaraidRequest struct {
+	PocketID  *string     `json:"pocket_id" validate:"omitempty,uuid"`
+	GoldPrice *float64    `json:"gold_price" validate:"omitempty,gt=0"`
+	Heirs     FaraidHeirs `json:"heirs"`
+}
+
+type FaraidHeirs struct {
+	Husband   bool `json:"husband"`
+	Wives     int  `json:"wives" validate:"gte=0,lte=4"`
+	Sons      int  `json:"sons" validate:"gte=0,lte=100"`
+	Daughters int  `json:"daughters" validate:"gte=0,lte=100"`
+	Father    bool `json:"father"`
+	Mother    bool `json:"mother"`
+}
+
+type FaraidShare struct {
+	Heir            string   `json:"heir"`
+	Count           int      `json:"count"`
+	Basis           string   `json:"basis"`
+	Fraction        string   `json:"fraction"`
+	Percentage      float64  `json:"percentage"`
+	Weight          float64  `json:"weight"`
+	WeightPerPerson float64  `json:"weight_per_person"`
+	Value           *float64 `json:"value,omitempty"`
+	ValuePerPerson  *float64 `json:"value_per_person,omitempty"`
+}
+
+type FaraidDistribution struct {
+	PocketID          *string       `json:"pocket_id,omitempty"`
+	PocketName        *string       `json:"pocket_name,omitempty"`
+	TotalWeight       float64       `json:"total_weight"`
+	GoldPrice         *float64      `json:"gold_price,omitempty"`
+	TotalValue        *float64      `json:"total_value,omitempty"`
+	Shares            []FaraidShare `json:"shares"`
+	Awl               bool          `json:"awl"`
+	Radd              bool          `json:"radd"`
+	UnallocatedWeight float64       `json:"unallocated_weight"`
+	Summary           string        `json:"summary"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+const (
+	InstallmentStatusActive    = "active"
+	InstallmentStatusCompleted = "completed"
+	InstallmentStatusCancelled = "cancelled"
+)
+
+// InstallmentContract is gold bought on installment. The gold is only added to the pocket
+// (as a Transaction) once every installment has been paid.
+type InstallmentContract struct {
+	ID                 string               `json:"id"`
+	UserID             string               `json:"user_id"`
+	PocketID           string               `json:"pocket_id"`
+	Provider           string               `json:"provider"`
+	Brand              string               `json:"brand"`
+	BrandID            string               `json:"brand_id"`
+	Weight             float64              `json:"weight"`
+	PricePerGram       float64              `json:"price_per_gram"`
+	DownPayment        float64              `json:"down_payment"`
+	TenorMonths        int                  `json:"tenor_months"`
+	MarginAmount       float64              `json:"margin_amount"`
+	MonthlyInstallment float64              `json:"monthly_installment"`
+	ContractDate       time.Time            `json:"contract_date"`
+	FirstDueDate       time.Time            `json:"first_due_date"`
+	Status             string               `json:"status"`
+	TransactionID      *string              `json:"transaction_id"`
+	Notes              *string              `json:"notes"`
+	CashPrice          float64              `json:"cash_price"`
+	TotalPayable       float64              `json:"total_payable"`
+	TotalPaid          float64              `json:"total_paid"`
+	RemainingAmount    float64              `json:"remaining_amount"`
+	PaidInstallments   int                  `json:"paid_installments"`
+	NextDueDate        *time.Time           `json:"next_due_date,omitempty"`
+	Schedule           []InstallmentPayment `json:"schedule,omitempty"`
+	Pocket             *Pocket              `json:"pocket,omitempty"`
+	CreatedAt          time.Time            `json:"created_at"`
+	UpdatedAt          time.Time            `json:"updated_at"`
+}
+
+type InstallmentPayment struct {
+	ID         string     `json:"id"`
+	ContractID string     `json:"contract_id"`
+	UserID     string     `json:"user_id"`
+	Sequence   int        `json:"sequence"`
+	DueDate    time.Time  `json:"due_date"`
+	AmountDue  float64    `json:"amount_due"`
+	PaidAmount *float64   `json:"paid_amount"`
+	PaidAt     *time.Time `json:"paid_at"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateInstallmentRequest takes either the flat margin rate or the monthly installment
+// quoted by the provider; the other is derived
+type CreateInstallmentRequest struct {
+	PocketID           string   `json:"pocket_id" validate:"required,uuid"`
+	Provider           string   `json:"provider" validate:"required,max=100"`
+	Brand              string   `json:"brand" validate:"required,brand"`
+	Weight             float64  `json:"weight" validate:"required,gte=0.1,lte=1000"`
+	PricePerGram       float64  `json:"price_per_gram" validate:"required,gte=1000,lte=10000000"`
+	DownPayment        float64  `json:"down_payment" validate:"gte=0"`
+	TenorMonths        int      `json:"tenor_months" validate:"required,gte=1,lte=120"`
+	MarginRate         *float64 `json:"margin_rate" validate:"omitempty,gte=0,lte=100"`
+	MonthlyInstallment *float64 `json:"monthly_installment" validate:"omitempty,gt=0"`
+	ContractDate       string   `json:"contract_date" validate:"required"`
+	FirstDueDate       *string  `json:"first_due_date"`
+	Notes              *string  `json:"notes" validate:"omitempty,max=500"`
+}
+
+type RecordInstallmentPaymentRequest struct {
+	PaidAt string   `json:"paid_at" validate:"required"`
+	Amount *float64 `json:"amount" validate:"omitempty,gt=0"`
+}
+
+// InstallmentSummary reports gold still being paid off, kept apart from owned holdings
+type InstallmentSummary struct {
+	ActiveContracts int             `json:"active_contracts"`
+	Weight          decimal.Decimal `json:"weight"`
+	TotalPayable    decimal.Decimal `json:"total_payable"`
+	TotalPaid       decimal.Decimal `json:"total_paid"`
+	RemainingAmount decimal.Decimal `json:"remaining_amount"`
+	NextDueDate     *time.Time      `json:"next_due_date,omitempty"`
+}
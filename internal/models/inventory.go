@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// InventoryItem is a single physical piece of gold, e.g. one 5g bar with its certificate
+type InventoryItem struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	PocketID        string    `json:"pocket_id"`
+	TransactionID   *string   `json:"transaction_id"`
+	Brand           string    `json:"brand"`
+	BrandID         string    `json:"brand_id"`
+	SerialNumber    *string   `json:"serial_number"`
+	PieceWeight     float64   `json:"piece_weight"`
+	Packaging       *string   `json:"packaging"`
+	StorageLocation *string   `json:"storage_location"`
+	Notes           *string   `json:"notes"`
+	Pocket          *Pocket   `json:"pocket,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type CreateInventoryItemRequest struct {
+	PocketID        string  `json:"pocket_id" validate:"required,uuid"`
+	TransactionID   *string `json:"transaction_id" validate:"omitempty,uuid"`
+	Brand           string  `json:"brand" validate:"required,brand"`
+	SerialNumber    *string `json:"serial_number" validate:"omitempty,max=50"`
+	PieceWeight     float64 `json:"piece_weight" validate:"required,gt=0,lte=1000"`
+	Packaging       *string `json:"packaging" validate:"omitempty,max=50"`
+	StorageLocation *string `json:"storage_location" validate:"omitempty,max=100"`
+	Notes           *string `json:"notes" validate:"omitempty,max=500"`
+}
+
+type UpdateInventoryItemRequest struct {
+	PocketID        string  `json:"pocket_id" validate:"omitempty,uuid"`
+	SerialNumber    *string `json:"serial_number" validate:"omitempty,max=50"`
+	Packaging       *string `json:"packaging" validate:"omitempty,max=50"`
+	StorageLocation *string `json:"storage_location" validate:"omitempty,max=100"`
+	Notes           *string `json:"notes" validate:"omitempty,max=500"`
+}
+
+// CreateTransactionInventoryRequest splits a purchase into pieces, e.g. 5g x 2
+type CreateTransactionInventoryRequest struct {
+	Pieces []InventoryPieceRequest `json:"pieces" validate:"required,min=1,dive"`
+}
+
+type InventoryPieceRequest struct {
+	PieceWeight     float64  `json:"piece_weight" validate:"required,gt=0,lte=1000"`
+	Quantity        int      `json:"quantity" validate:"required,gte=1,lte=1000"`
+	SerialNumbers   []string `json:"serial_numbers" validate:"omitempty,dive,max=50"`
+	Packaging       *string  `json:"packaging" validate:"omitempty,max=50"`
+	StorageLocation *string  `json:"storage_location" validate:"omitempty,max=100"`
+}
+
+type StorageLocationSummary struct {
+	StorageLocation *string `json:"storage_location"`
+	ItemCount       int     `json:"item_count"`
+	TotalWeight     float64 `json:"total_weight"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Notification types, one per kind of event shown in the notification center
+const (
+	NotificationPriceAlert      = "price_alert"
+	NotificationGoalReached     = "goal_reached"
+	NotificationPledgeDue       = "pledge_due"
+	NotificationPlannedPurchase = "planned_purchase"
+	NotificationInstallmentDue  = "installment_due"
+)
+
+// NotificationTypes lists every notification type, in the order preferences are shown
+var NotificationTypes = []string{
+	NotificationPriceAlert,
+	NotificationGoalReached,
+	NotificationPledgeDue,
+	NotificationPlannedPurchase,
+	NotificationInstallmentDue,
+}
+
+type Notification struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data"`
+	Read      bool              `json:"read"`
+	ReadAt    *time.Time        `json:"read_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type NotificationList struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unread_count"`
+	// NextCursor is passed as cursor to fetch the following page; it is empty on the
+	// last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NotificationPreference holds the channels a notification type is delivered through
+type NotificationPreference struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+	Push  bool   `json:"push"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" validate:"required,min=1,dive"`
+}
+
+type NotificationPreferenceInput struct {
+	Type  string `json:"type" validate:"required,oneof=price_alert goal_reached pledge_due planned_purchase installment_due"`
+	InApp *bool  `json:"in_app"`
+	Email *bool  `json:"email"`
+	Push  *bool  `json:"push"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+const (
+	PledgeStatusActive   = "active"
+	PledgeStatusRedeemed = "redeemed"
+)
+
+// GoldPledge is gold pawned as collateral for a loan. The fee rate is a percentage of the
+// principal charged for every started fee period, as pawnshops charge "sewa modal".
+type GoldPledge struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	PocketID         string     `json:"pocket_id"`
+	TransactionID    *string    `json:"transaction_id"`
+	Lender           string     `json:"lender"`
+	Weight           float64    `json:"weight"`
+	LoanPrincipal    float64    `json:"loan_principal"`
+	FeeRate          float64    `json:"fee_rate"`
+	FeePeriodDays    int        `json:"fee_period_days"`
+	PledgedAt        time.Time  `json:"pledged_at"`
+	DueDate          time.Time  `json:"due_date"`
+	Status           string     `json:"status"`
+	RedeemedAt       *time.Time `json:"redeemed_at"`
+	RedemptionAmount *float64   `json:"redemption_amount"`
+	Notes            *string    `json:"notes"`
+	AccruedFee       float64    `json:"accrued_fee"`
+	OutstandingLoan  float64    `json:"outstanding_loan"`
+	Pocket           *Pocket    `json:"pocket,omitempty"`
+	RemindedAt       *time.Time `json:"reminded_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type CreatePledgeRequest struct {
+	PocketID      string  `json:"pocket_id" validate:"required,uuid"`
+	TransactionID *string `json:"transaction_id" validate:"omitempty,uuid"`
+	Lender        string  `json:"lender" validate:"required,max=100"`
+	Weight        float64 `json:"weight" validate:"required,gt=0,lte=1000"`
+	LoanPrincipal float64 `json:"loan_principal" validate:"required,gt=0"`
+	FeeRate       float64 `json:"fee_rate" validate:"gte=0,lte=100"`
+	FeePeriodDays int     `json:"fee_period_days" validate:"omitempty,gt=0,lte=365"`
+	PledgedAt     string  `json:"pledged_at" validate:"required"`
+	DueDate       string  `json:"due_date" validate:"required"`
+	Notes         *string `json:"notes" validate:"omitempty,max=500"`
+}
+
+type UpdatePledgeRequest struct {
+	Lender        string   `json:"lender" validate:"omitempty,max=100"`
+	LoanPrincipal *float64 `json:"loan_principal" validate:"omitempty,gt=0"`
+	FeeRate       *float64 `json:"fee_rate" validate:"omitempty,gte=0,lte=100"`
+	FeePeriodDays *int     `json:"fee_period_days" validate:"omitempty,gt=0,lte=365"`
+	DueDate       *string  `json:"due_date"`
+	Notes         *string  `json:"notes" validate:"omitempty,max=500"`
+}
+
+type RedeemPledgeRequest struct {
+	RedeemedAt       *string  `json:"redeemed_at"`
+	RedemptionAmount *float64 `json:"redemption_amount" validate:"omitempty,gt=0"`
+}
+
+// PledgeSummary is the user's outstanding pawn position
+type PledgeSummary struct {
+	ActivePledges   int             `json:"active_pledges"`
+	PledgedWeight   decimal.Decimal `json:"pledged_weight"`
+	OutstandingLoan decimal.Decimal `json:"outstanding_loan"`
+	OutstandingFees decimal.Decimal `json:"outstanding_fees"`
+	NextDueDate     *time.Time      `json:"next_due_date,omitempty"`
+	OverduePledges  int             `json:"overdue_pledges"`
+}
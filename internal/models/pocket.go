@@ -1,20 +1,30 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
 
 type Pocket struct {
-	ID                   string      `json:"id"`
-	UserID               string      `json:"user_id"`
-	TypePocketID         string      `json:"type_pocket_id"`
-	Name                 string      `json:"name"`
-	Description          *string     `json:"description"`
-	AggregateTotalPrice  float64     `json:"aggregate_total_price"`
-	AggregateTotalWeight float64     `json:"aggregate_total_weight"`
-	TargetWeight         *float64    `json:"target_weight"`
-	TypePocket           *TypePocket `json:"type_pocket,omitempty"`
-	TransactionCount     *int        `json:"transaction_count,omitempty"`
-	CreatedAt            time.Time   `json:"created_at"`
-	UpdatedAt            time.Time   `json:"updated_at"`
+	ID                   string           `json:"id"`
+	UserID               string           `json:"user_id"`
+	TypePocketID         string           `json:"type_pocket_id"`
+	Name                 string           `json:"name"`
+	Description          *string          `json:"description"`
+	AggregateTotalPrice  decimal.Decimal  `json:"aggregate_total_price"`
+	AggregateTotalCost   decimal.Decimal  `json:"aggregate_total_cost"`
+	AggregateTotalWeight decimal.Decimal  `json:"aggregate_total_weight"`
+	AggregateFineWeight  decimal.Decimal  `json:"aggregate_fine_weight"`
+	TargetWeight         *decimal.Decimal `json:"target_weight"`
+	TargetAmount         *decimal.Decimal `json:"target_amount"`
+	TargetDate           *time.Time       `json:"target_date"`
+	ZakatExempt          bool             `json:"zakat_exempt"`
+	Goal                 *PocketGoal      `json:"goal,omitempty"`
+	TypePocket           *TypePocket      `json:"type_pocket,omitempty"`
+	TransactionCount     *int             `json:"transaction_count,omitempty"`
+	CreatedAt            time.Time        `json:"created_at"`
+	UpdatedAt            time.Time        `json:"updated_at"`
 }
 
 type CreatePocketRequest struct {
@@ -22,6 +32,9 @@ type CreatePocketRequest struct {
 	Name         string   `json:"name" validate:"required,min=3,max=100"`
 	Description  *string  `json:"description" validate:"omitempty,max=500"`
 	TargetWeight *float64 `json:"target_weight" validate:"omitempty,gt=0"`
+	TargetAmount *float64 `json:"target_amount" validate:"omitempty,gt=0"`
+	TargetDate   *string  `json:"target_date"`
+	ZakatExempt  bool     `json:"zakat_exempt"`
 }
 
 type UpdatePocketRequest struct {
@@ -29,15 +42,52 @@ type UpdatePocketRequest struct {
 	Name         string   `json:"name" validate:"omitempty,min=3,max=100"`
 	Description  *string  `json:"description" validate:"omitempty,max=500"`
 	TargetWeight *float64 `json:"target_weight" validate:"omitempty,gt=0"`
+	TargetAmount *float64 `json:"target_amount" validate:"omitempty,gt=0"`
+	TargetDate   *string  `json:"target_date"`
+	ZakatExempt  *bool    `json:"zakat_exempt"`
 }
 
 type PocketStats struct {
-	TotalWeight          float64  `json:"total_weight"`
-	TotalValue           float64  `json:"total_value"`
-	AveragePricePerGram  float64  `json:"average_price_per_gram"`
-	CurrentGoldPrice     *float64 `json:"current_gold_price,omitempty"`
-	CurrentValue         *float64 `json:"current_value,omitempty"`
-	ProfitLoss           *float64 `json:"profit_loss,omitempty"`
-	ProfitLossPercentage *float64 `json:"profit_loss_percentage,omitempty"`
-	TransactionCount     int      `json:"transaction_count"`
+	TotalWeight          decimal.Decimal  `json:"total_weight"`
+	FineWeight           decimal.Decimal  `json:"fine_weight"`
+	PledgedWeight        decimal.Decimal  `json:"pledged_weight"`
+	AvailableWeight      decimal.Decimal  `json:"available_weight"`
+	TotalValue           decimal.Decimal  `json:"total_value"`
+	TotalFees            decimal.Decimal  `json:"total_fees"`
+	TotalCost            decimal.Decimal  `json:"total_cost"`
+	AveragePricePerGram  decimal.Decimal  `json:"average_price_per_gram"`
+	CurrentGoldPrice     *decimal.Decimal `json:"current_gold_price,omitempty"`
+	CurrentValue         *decimal.Decimal `json:"current_value,omitempty"`
+	ProfitLoss           *decimal.Decimal `json:"profit_loss,omitempty"`
+	ProfitLossPercentage *float64         `json:"profit_loss_percentage,omitempty"`
+	TransactionCount     int              `json:"transaction_count"`
+}
+
+const (
+	GoalTargetWeight = "weight"
+	GoalTargetAmount = "amount"
+)
+
+// PocketGoal describes progress towards a pocket's savings target. Weight goals are
+// measured in grams held, amount goals in rupiah invested.
+type PocketGoal struct {
+	PocketID                string           `json:"pocket_id"`
+	PocketName              string           `json:"pocket_name"`
+	TargetType              string           `json:"target_type"`
+	TargetWeight            *decimal.Decimal `json:"target_weight,omitempty"`
+	TargetAmount            *decimal.Decimal `json:"target_amount,omitempty"`
+	TargetDate              *time.Time       `json:"target_date,omitempty"`
+	CurrentWeight           decimal.Decimal  `json:"current_weight"`
+	CurrentAmount           decimal.Decimal  `json:"current_amount"`
+	ProgressPercentage      float64          `json:"progress_percentage"`
+	RemainingWeight         decimal.Decimal  `json:"remaining_weight"`
+	RemainingAmount         *decimal.Decimal `json:"remaining_amount,omitempty"`
+	Reached                 bool             `json:"reached"`
+	MonthsRemaining         *int             `json:"months_remaining,omitempty"`
+	RequiredMonthlyWeight   *decimal.Decimal `json:"required_monthly_weight,omitempty"`
+	RequiredMonthlyAmount   *decimal.Decimal `json:"required_monthly_amount,omitempty"`
+	AverageMonthlyWeight    decimal.Decimal  `json:"average_monthly_weight"`
+	AverageMonthlyAmount    decimal.Decimal  `json:"average_monthly_amount"`
+	ProjectedCompletionDate *time.Time       `json:"projected_completion_date,omitempty"`
+	OnTrack                 *bool            `json:"on_track,omitempty"`
 }
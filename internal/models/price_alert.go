@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+const (
+	PriceAlertAbove            = "price_above"
+	PriceAlertBelow            = "price_below"
+	PriceAlertDailyChange      = "daily_change"
+	PriceAlertBelowAverageCost = "below_average_cost"
+)
+
+// PriceAlert fires when a newly stored gold price of PriceSource, or of any source
+// when it is empty, meets the condition of its type. Threshold is a price per gram in
+// rupiah for price_above and price_below and a percentage for daily_change.
+type PriceAlert struct {
+	ID              string           `json:"id"`
+	UserID          string           `json:"user_id"`
+	AlertType       string           `json:"alert_type"`
+	PriceSource     *string          `json:"price_source"`
+	Brand           *string          `json:"brand"`
+	BrandID         *string          `json:"brand_id"`
+	Threshold       *decimal.Decimal `json:"threshold"`
+	NotifyEmail     bool             `json:"notify_email"`
+	NotifyPush      bool             `json:"notify_push"`
+	NotifyInApp     bool             `json:"notify_in_app"`
+	CooldownHours   int              `json:"cooldown_hours"`
+	Active          bool             `json:"active"`
+	LastTriggeredAt *time.Time       `json:"last_triggered_at"`
+	LastPriceDate   *time.Time       `json:"last_price_date"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// CreatePriceAlertRequest takes a brand, by name or ID, only for below_average_cost
+type CreatePriceAlertRequest struct {
+	AlertType     string   `json:"alert_type" validate:"required,oneof=price_above price_below daily_change below_average_cost"`
+	PriceSource   *string  `json:"price_source" validate:"omitempty,max=50"`
+	Brand         *string  `json:"brand" validate:"omitempty,brand"`
+	Threshold     *float64 `json:"threshold" validate:"omitempty,gt=0,lte=10000000"`
+	NotifyEmail   *bool    `json:"notify_email"`
+	NotifyPush    *bool    `json:"notify_push"`
+	NotifyInApp   *bool    `json:"notify_in_app"`
+	CooldownHours *int     `json:"cooldown_hours" validate:"omitempty,min=1,max=720"`
+}
+
+type UpdatePriceAlertRequest struct {
+	PriceSource   *string  `json:"price_source" validate:"omitempty,max=50"`
+	Brand         *string  `json:"brand" validate:"omitempty,brand"`
+	Threshold     *float64 `json:"threshold" validate:"omitempty,gt=0,lte=10000000"`
+	NotifyEmail   *bool    `json:"notify_email"`
+	NotifyPush    *bool    `json:"notify_push"`
+	NotifyInApp   *bool    `json:"notify_in_app"`
+	CooldownHours *int     `json:"cooldown_hours" validate:"omitempty,min=1,max=720"`
+	Active        *bool    `json:"active"`
+}
+
+// PriceAlertEvent records an alert firing. ReferenceValue is the threshold, the
+// previous price for daily_change or the average cost for below_average_cost.
+type PriceAlertEvent struct {
+	ID               string           `json:"id"`
+	AlertID          string           `json:"alert_id"`
+	UserID           string           `json:"user_id"`
+	AlertType        string           `json:"alert_type"`
+	PriceDate        time.Time        `json:"price_date"`
+	PriceSource      string           `json:"price_source"`
+	PricePerGram     decimal.Decimal  `json:"price_per_gram"`
+	ReferenceValue   *decimal.Decimal `json:"reference_value"`
+	ChangePercentage *float64         `json:"change_percentage"`
+	Message          string           `json:"message"`
+	DeliveredEmail   bool             `json:"delivered_email"`
+	DeliveredPush    bool             `json:"delivered_push"`
+	InApp            bool             `json:"in_app"`
+	CreatedAt        time.Time        `json:"created_at"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+const (
+	PlanFrequencyWeekly  = "weekly"
+	PlanFrequencyMonthly = "monthly"
+
+	PlanAmountRupiah = "rupiah"
+	PlanAmountGrams  = "grams"
+
+	PlannedPurchasePending   = "pending"
+	PlannedPurchaseConfirmed = "confirmed"
+	PlannedPurchaseSkipped   = "skipped"
+	PlannedPurchaseMissed    = "missed"
+)
+
+type RecurringPlan struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	PocketID    string     `json:"pocket_id"`
+	Brand       string     `json:"brand"`
+	BrandID     string     `json:"brand_id"`
+	Frequency   string     `json:"frequency"`
+	AmountType  string     `json:"amount_type"`
+	Amount      float64    `json:"amount"`
+	PriceSource *string    `json:"price_source"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	NextDueDate *time.Time `json:"next_due_date"`
+	AutoPost    bool       `json:"auto_post"`
+	Active      bool       `json:"active"`
+	Pocket      *Pocket    `json:"pocket,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type CreateRecurringPlanRequest struct {
+	PocketID    string  `json:"pocket_id" validate:"required,uuid"`
+	Brand       string  `json:"brand" validate:"required,brand"`
+	Frequency   string  `json:"frequency" validate:"required,oneof=weekly monthly"`
+	AmountType  string  `json:"amount_type" validate:"required,oneof=rupiah grams"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	PriceSource *string `json:"price_source" validate:"omitempty,max=50"`
+	StartDate   string  `json:"start_date" validate:"required"`
+	EndDate     *string `json:"end_date"`
+	AutoPost    bool    `json:"auto_post"`
+}
+
+type UpdateRecurringPlanRequest struct {
+	Brand       string   `json:"brand" validate:"omitempty,brand"`
+	AmountType  string   `json:"amount_type" validate:"omitempty,oneof=rupiah grams"`
+	Amount      *float64 `json:"amount" validate:"omitempty,gt=0"`
+	PriceSource *string  `json:"price_source" validate:"omitempty,max=50"`
+	EndDate     *string  `json:"end_date"`
+	AutoPost    *bool    `json:"auto_post"`
+	Active      *bool    `json:"active"`
+}
+
+type PlannedPurchase struct {
+	ID            string     `json:"id"`
+	PlanID        string     `json:"plan_id"`
+	UserID        string     `json:"user_id"`
+	PocketID      string     `json:"pocket_id"`
+	DueDate       time.Time  `json:"due_date"`
+	Weight        *float64   `json:"weight"`
+	PricePerGram  *float64   `json:"price_per_gram"`
+	TotalPrice    *float64   `json:"total_price"`
+	Status        string     `json:"status"`
+	TransactionID *string    `json:"transaction_id"`
+	RemindedAt    *time.Time `json:"reminded_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ConfirmPlannedPurchaseRequest lets the user correct the actual purchase before it is recorded
+type ConfirmPlannedPurchaseRequest struct {
+	TransactionDate *string  `json:"transaction_date"`
+	Weight          *float64 `json:"weight" validate:"omitempty,gte=0.1,lte=1000"`
+	PricePerGram    *float64 `json:"price_per_gram" validate:"omitempty,gte=1000,lte=10000000"`
+	Description     *string  `json:"description" validate:"omitempty,max=500"`
+}
@@ -11,6 +11,7 @@ type UserSettings struct {
 	EmailNotifications bool      `json:"email_notifications"`
 	PushNotifications  bool      `json:"push_notifications"`
 	PriceAlerts        bool      `json:"price_alerts"`
+	ZakatNisabGrams    float64   `json:"zakat_nisab_grams"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
@@ -18,7 +19,9 @@ type UserSettings struct {
 type UpdateSettingsRequest struct {
 	Language      *string                `json:"language" validate:"omitempty,oneof=en id"`
 	Theme         *string                `json:"theme" validate:"omitempty,oneof=light dark"`
+	Currency      *string                `json:"currency" validate:"omitempty,oneof=IDR USD EUR SGD MYR"`
 	Notifications *NotificationSettings  `json:"notifications"`
+	Zakat         *ZakatSettings         `json:"zakat"`
 }
 
 type NotificationSettings struct {
@@ -27,11 +30,20 @@ type NotificationSettings struct {
 	PriceAlerts *bool `json:"price_alerts"`
 }
 
+type ZakatSettings struct {
+	NisabGrams *float64 `json:"nisab_grams" validate:"omitempty,gt=0,lte=1000"`
+}
+
 type SettingsResponse struct {
 	Language      string                       `json:"language"`
 	Theme         string                       `json:"theme"`
 	Currency      string                       `json:"currency"`
 	Notifications NotificationSettingsResponse `json:"notifications"`
+	Zakat         ZakatSettingsResponse        `json:"zakat"`
+}
+
+type ZakatSettingsResponse struct {
+	NisabGrams float64 `json:"nisab_grams"`
 }
 
 type NotificationSettingsResponse struct {
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// ValueSnapshot is the end-of-day state of a portfolio or pocket. Gold price and
+// market value are missing on days without a stored price from the week before.
+type ValueSnapshot struct {
+	Date        time.Time        `json:"date"`
+	Weight      decimal.Decimal  `json:"weight"`
+	FineWeight  decimal.Decimal  `json:"fine_weight"`
+	TotalCost   decimal.Decimal  `json:"total_cost"`
+	GoldPrice   *decimal.Decimal `json:"gold_price"`
+	MarketValue *decimal.Decimal `json:"market_value"`
+	ProfitLoss  *decimal.Decimal `json:"profit_loss"`
+}
+
+type ValueHistory struct {
+	Currency  string          `json:"currency"`
+	PocketID  *string         `json:"pocket_id"`
+	Interval  string          `json:"interval"`
+	Snapshots []ValueSnapshot `json:"snapshots"`
+}
+
+type BackfillSnapshotsRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"omitempty"`
+}
+
+type SnapshotBackfillResult struct {
+	Days             int        `json:"days"`
+	DaysWithoutPrice int        `json:"days_without_price"`
+	Snapshots        int        `json:"snapshots"`
+	From             *time.Time `json:"from,omitempty"`
+	To               *time.Time `json:"to,omitempty"`
+}
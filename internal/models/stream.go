@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// Event types of the live event stream
+const (
+	StreamEventGoldPrice    = "gold_price"
+	StreamEventPortfolio    = "portfolio"
+	StreamEventNotification = "notification"
+	// StreamEventReset tells a resuming client that events were missed and its state
+	// must be reloaded
+	StreamEventReset = "reset"
+)
+
+// StreamEvent is an event of the live event stream. Events with an ID are stored and
+// can be resumed from; the others are only sent to the clients connected at the time.
+// Events without a user go to everyone.
+type StreamEvent struct {
+	ID        int64
+	UserID    *string
+	Type      string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// PortfolioUpdate is the current value of a user's portfolio, sent whenever the
+// holdings or the gold price change. Amounts are in the user's display currency;
+// the gold price is missing when none was stored in the past week.
+type PortfolioUpdate struct {
+	Currency             string           `json:"currency"`
+	TotalWeight          decimal.Decimal  `json:"total_weight"`
+	TotalFineWeight      decimal.Decimal  `json:"total_fine_weight"`
+	TotalCost            decimal.Decimal  `json:"total_cost"`
+	GoldPrice            *GoldPrice       `json:"gold_price"`
+	CurrentValue         *decimal.Decimal `json:"current_value"`
+	ProfitLoss           *decimal.Decimal `json:"profit_loss"`
+	ProfitLossPercentage *float64         `json:"profit_loss_percentage"`
+	UpdatedAt            time.Time        `json:"updated_at"`
+}
+
+// NotificationUpdate announces a new notification with the user's unread count
+type NotificationUpdate struct {
+	Notification Notification `json:"notification"`
+	UnreadCount  int          `json:"unread_count"`
+}
@@ -1,39 +1,87 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
 
 type Transaction struct {
-	ID              string    `json:"id"`
-	UserID          string    `json:"user_id"`
-	PocketID        string    `json:"pocket_id"`
-	TransactionDate time.Time `json:"transaction_date"`
-	Brand           string    `json:"brand"`
-	Weight          float64   `json:"weight"`
-	PricePerGram    float64   `json:"price_per_gram"`
-	TotalPrice      float64   `json:"total_price"`
-	Description     *string   `json:"description"`
-	ReceiptImage    *string   `json:"receipt_image"`
-	Pocket          *Pocket   `json:"pocket,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string           `json:"id"`
+	UserID          string           `json:"user_id"`
+	PocketID        string           `json:"pocket_id"`
+	TransactionDate time.Time        `json:"transaction_date"`
+	Brand           string           `json:"brand"`
+	BrandID         string           `json:"brand_id"`
+	Weight          decimal.Decimal  `json:"weight"`
+	Purity          decimal.Decimal  `json:"purity"`
+	FineWeight      decimal.Decimal  `json:"fine_weight"`
+	PricePerGram    decimal.Decimal  `json:"price_per_gram"`
+	TotalPrice      decimal.Decimal  `json:"total_price"`
+	TotalFees       decimal.Decimal  `json:"total_fees"`
+	TotalCost       decimal.Decimal  `json:"total_cost"`
+	Currency        string           `json:"currency,omitempty"`
+	Description     *string          `json:"description"`
+	ReceiptImage    *string          `json:"receipt_image"`
+	Fees            []TransactionFee `json:"fees,omitempty"`
+	Pocket          *Pocket          `json:"pocket,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
 }
 
+// MinTransactionWeight is the smallest purchase in grams a transaction records
+const MinTransactionWeight = 0.1
+
 type CreateTransactionRequest struct {
-	PocketID        string  `json:"pocket_id" validate:"required,uuid"`
-	TransactionDate string  `json:"transaction_date" validate:"required"`
-	Brand           string  `json:"brand" validate:"required,oneof=Antam UBS Pegadaian 'King Halim' Custom"`
-	Weight          float64 `json:"weight" validate:"required,gte=0.1,lte=1000"`
-	PricePerGram    float64 `json:"price_per_gram" validate:"required,gte=1000,lte=10000000"`
-	TotalPrice      float64 `json:"total_price" validate:"required"`
-	Description     *string `json:"description" validate:"omitempty,max=500"`
-	ReceiptImage    *string `json:"receipt_image" validate:"omitempty"`
+	PocketID        string                  `json:"pocket_id" validate:"required,uuid"`
+	TransactionDate string                  `json:"transaction_date" validate:"required"`
+	Brand           string                  `json:"brand" validate:"required,brand"`
+	Weight          float64                 `json:"weight" validate:"required,gte=0.1,lte=1000"`
+	Karat           *float64                `json:"karat" validate:"omitempty,gt=0,lte=24"`
+	Fineness        *float64                `json:"fineness" validate:"omitempty,gt=0,lte=1000"`
+	PricePerGram    float64                 `json:"price_per_gram" validate:"required,gte=1000,lte=10000000"`
+	TotalPrice      float64                 `json:"total_price" validate:"required"`
+	Description     *string                 `json:"description" validate:"omitempty,max=500"`
+	ReceiptImage    *string                 `json:"receipt_image" validate:"omitempty"`
+	Fees            []TransactionFeeRequest `json:"fees" validate:"omitempty,max=10,dive"`
 }
 
+// UpdateTransactionRequest replaces all fee lines when fees is present; an empty
+// list removes them
 type UpdateTransactionRequest struct {
-	TransactionDate string  `json:"transaction_date" validate:"omitempty"`
-	Brand           string  `json:"brand" validate:"omitempty,oneof=Antam UBS Pegadaian 'King Halim' Custom"`
-	Weight          float64 `json:"weight" validate:"omitempty,gte=0.1,lte=1000"`
-	PricePerGram    float64 `json:"price_per_gram" validate:"omitempty,gte=1000,lte=10000000"`
-	TotalPrice      float64 `json:"total_price" validate:"omitempty"`
-	Description     *string `json:"description" validate:"omitempty,max=500"`
+	TransactionDate string                  `json:"transaction_date" validate:"omitempty"`
+	Brand           string                  `json:"brand" validate:"omitempty,brand"`
+	Weight          float64                 `json:"weight" validate:"omitempty,gte=0.1,lte=1000"`
+	Karat           *float64                `json:"karat" validate:"omitempty,gt=0,lte=24"`
+	Fineness        *float64                `json:"fineness" validate:"omitempty,gt=0,lte=1000"`
+	PricePerGram    float64                 `json:"price_per_gram" validate:"omitempty,gte=1000,lte=10000000"`
+	TotalPrice      float64                 `json:"total_price" validate:"omitempty"`
+	Description     *string                 `json:"description" validate:"omitempty,max=500"`
+	Fees            []TransactionFeeRequest `json:"fees" validate:"omitempty,max=10,dive"`
+}
+
+const (
+	FeeTypePPh22       = "pph22"
+	FeeTypePrinting    = "printing"
+	FeeTypeCertificate = "certificate"
+	FeeTypeShipping    = "shipping"
+	FeeTypeAdmin       = "admin"
+	FeeTypeOther       = "other"
+)
+
+// TransactionFee is a cost paid on top of weight x price, e.g. PPh 22 or shipping.
+// Fees count towards the cost basis but not towards the price per gram.
+type TransactionFee struct {
+	ID            string          `json:"id"`
+	TransactionID string          `json:"transaction_id"`
+	FeeType       string          `json:"fee_type"`
+	Amount        decimal.Decimal `json:"amount"`
+	Description   *string         `json:"description"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type TransactionFeeRequest struct {
+	FeeType     string  `json:"fee_type" validate:"required,oneof=pph22 printing certificate shipping admin other"`
+	Amount      float64 `json:"amount" validate:"required,gt=0,lte=100000000"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
 }
@@ -0,0 +1,36 @@
+package models
+
+// TransactionImportMapping maps transaction fields to CSV column headers.
+// Empty fields fall back to the field name itself (e.g. "transaction_date").
+type TransactionImportMapping struct {
+	TransactionDate string `json:"transaction_date"`
+	Pocket          string `json:"pocket"`
+	Brand           string `json:"brand"`
+	Weight          string `json:"weight"`
+	PricePerGram    string `json:"price_per_gram"`
+	TotalPrice      string `json:"total_price"`
+	Description     string `json:"description"`
+}
+
+type TransactionImportOptions struct {
+	Mapping          TransactionImportMapping `json:"mapping"`
+	DateFormat       string                   `json:"date_format"`
+	DecimalSeparator string                   `json:"decimal_separator" validate:"omitempty,oneof=. ,"`
+	SkipDuplicates   bool                     `json:"skip_duplicates"`
+	DryRun           bool                     `json:"dry_run"`
+}
+
+type TransactionImportRowError struct {
+	Row    int                 `json:"row"`
+	Errors map[string][]string `json:"errors"`
+}
+
+type TransactionImportResult struct {
+	DryRun        bool                        `json:"dry_run"`
+	TotalRows     int                         `json:"total_rows"`
+	ValidRows     int                         `json:"valid_rows"`
+	DuplicateRows []int                       `json:"duplicate_rows"`
+	ImportedRows  int                         `json:"imported_rows"`
+	Errors        []TransactionImportRowError `json:"errors"`
+	Preview       []Transaction               `json:"preview,omitempty"`
+}
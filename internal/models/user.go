@@ -9,6 +9,7 @@ type User struct {
 	Phone     string     `json:"phone"`
 	Password  string     `json:"-"` // Never return in JSON
 	Avatar    *string    `json:"avatar"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
@@ -52,6 +53,10 @@ type ChangePasswordRequest struct {
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
 }
 
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
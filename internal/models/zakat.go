@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+const (
+	// ZakatRate is the 2.5% (1/40) due on gold that reached nisab for a full haul
+	ZakatRate = 0.025
+	// DefaultZakatNisabGrams is the nisab for gold (20 dinar) used when the user has not configured one
+	DefaultZakatNisabGrams = 85.0
+	// ZakatHaulDays is one lunar (hijri) year
+	ZakatHaulDays = 354
+)
+
+// ZakatLot is a single purchase and whether it has been held for a full haul
+type ZakatLot struct {
+	TransactionID    string    `json:"transaction_id"`
+	PocketID         string    `json:"pocket_id"`
+	PocketName       string    `json:"pocket_name"`
+	TransactionDate  time.Time `json:"transaction_date"`
+	Brand            string    `json:"brand"`
+	Weight           float64   `json:"weight"`
+	HaulCompleteDate time.Time `json:"haul_complete_date"`
+	HaulComplete     bool      `json:"haul_complete"`
+	Exempt           bool      `json:"exempt"`
+}
+
+type ZakatReport struct {
+	AsOf            time.Time          `json:"as_of"`
+	NisabGrams      float64            `json:"nisab_grams"`
+	HaulDays        int                `json:"haul_days"`
+	Rate            float64            `json:"rate"`
+	GoldPrice       *float64           `json:"gold_price,omitempty"`
+	GoldPriceDate   *time.Time         `json:"gold_price_date,omitempty"`
+	TotalWeight     float64            `json:"total_weight"`
+	ExemptWeight    float64            `json:"exempt_weight"`
+	ZakatableWeight float64            `json:"zakatable_weight"`
+	NisabReached    bool               `json:"nisab_reached"`
+	EligibleWeight  float64            `json:"eligible_weight"`
+	EligibleValue   *float64           `json:"eligible_value,omitempty"`
+	ZakatDueWeight  float64            `json:"zakat_due_weight"`
+	ZakatDue        *float64           `json:"zakat_due,omitempty"`
+	PaidThisYear    float64            `json:"paid_this_year"`
+	Lots            []ZakatLot         `json:"lots"`
+	History         []ZakatYearSummary `json:"history"`
+}
+
+type ZakatYearSummary struct {
+	Year         int     `json:"year"`
+	TotalPaid    float64 `json:"total_paid"`
+	TotalWeight  float64 `json:"total_weight"`
+	PaymentCount int     `json:"payment_count"`
+}
+
+type ZakatPayment struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	PaidAt    time.Time `json:"paid_at"`
+	Amount    float64   `json:"amount"`
+	Weight    *float64  `json:"weight"`
+	Notes     *string   `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateZakatPaymentRequest struct {
+	PaidAt string   `json:"paid_at" validate:"required"`
+	Amount float64  `json:"amount" validate:"required,gt=0"`
+	Weight *float64 `json:"weight" validate:"omitempty,gt=0"`
+	Notes  *string  `json:"notes" validate:"omitempty,max=500"`
+}
@@ -0,0 +1,154 @@
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// apnsTokenLifetime is how long a provider token is reused; Apple rejects tokens older
+// than an hour and refreshes more often than every 20 minutes
+const apnsTokenLifetime = 50 * time.Minute
+
+// APNsSender delivers messages through the Apple Push Notification service, using
+// token-based authentication with a signing key from the Apple developer account
+type APNsSender struct {
+	KeyID  string
+	TeamID string
+	// Topic is the app's bundle ID
+	Topic   string
+	BaseURL string
+	Client  *http.Client
+
+	key *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender reads the .p8 signing key. Production selects the production service
+// instead of the sandbox used by development builds.
+func NewAPNsSender(keyFile, keyID, teamID, topic string, production bool) (*APNsSender, error) {
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, errors.New("apns: key ID, team ID and topic are required")
+	}
+
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("apns: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("apns: invalid signing key: %w", err)
+	}
+
+	baseURL := "https://api.sandbox.push.apple.com"
+	if production {
+		baseURL = "https://api.push.apple.com"
+	}
+
+	return &APNsSender{
+		KeyID:   keyID,
+		TeamID:  teamID,
+		Topic:   topic,
+		BaseURL: baseURL,
+		// The service only speaks HTTP/2, which the default transport negotiates
+		Client: &http.Client{Timeout: 10 * time.Second},
+		key:    key,
+	}, nil
+}
+
+func (s *APNsSender) Send(device Device, message Message) error {
+	token, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	// Custom data sits next to the aps dictionary
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.BaseURL+"/3/device/"+device.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", s.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&failure)
+
+	switch failure.Reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	}
+	if resp.StatusCode == http.StatusGone {
+		return ErrInvalidToken
+	}
+
+	return fmt.Errorf("apns: send returned status %d: %s", resp.StatusCode, failure.Reason)
+}
+
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenLifetime {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.KeyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	s.token, s.issuedAt = signed, now
+	return s.token, nil
+}
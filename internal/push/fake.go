@@ -0,0 +1,51 @@
+package push
+
+import (
+	"log"
+	"sync"
+)
+
+// FakeSender records messages instead of delivering them, for development and tests.
+// Tokens marked invalid are rejected like a provider rejects uninstalled apps.
+type FakeSender struct {
+	mu         sync.Mutex
+	deliveries []Delivery
+	invalid    map[string]bool
+}
+
+// Delivery is a message recorded by FakeSender
+type Delivery struct {
+	Device  Device
+	Message Message
+}
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{invalid: make(map[string]bool)}
+}
+
+func (f *FakeSender) Send(device Device, message Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.invalid[device.Token] {
+		return ErrInvalidToken
+	}
+
+	f.deliveries = append(f.deliveries, Delivery{Device: device, Message: message})
+	log.Printf("Push to %s device: %s - %s", device.Platform, message.Title, message.Body)
+	return nil
+}
+
+// MarkInvalid makes every later message to the token fail with ErrInvalidToken
+func (f *FakeSender) MarkInvalid(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invalid[token] = true
+}
+
+// Deliveries returns the messages recorded so far
+func (f *FakeSender) Deliveries() []Delivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Delivery(nil), f.deliveries...)
+}
@@ -0,0 +1,185 @@
+package push
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMSender delivers messages through the Firebase Cloud Messaging HTTP v1 API,
+// authenticated as a Google service account
+type FCMSender struct {
+	ProjectID string
+	BaseURL   string
+	Client    *http.Client
+
+	clientEmail string
+	tokenURI    string
+	privateKey  *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSender reads the service account key file downloaded from the Firebase console
+func NewFCMSender(credentialsFile string) (*FCMSender, error) {
+	content, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("fcm: %w", err)
+	}
+
+	var credentials struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("fcm: invalid credentials file: %w", err)
+	}
+	if credentials.ProjectID == "" || credentials.ClientEmail == "" {
+		return nil, errors.New("fcm: credentials file is not a service account key")
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(credentials.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm: invalid private key: %w", err)
+	}
+	if credentials.TokenURI == "" {
+		credentials.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &FCMSender{
+		ProjectID:   credentials.ProjectID,
+		BaseURL:     "https://fcm.googleapis.com",
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		clientEmail: credentials.ClientEmail,
+		tokenURI:    credentials.TokenURI,
+		privateKey:  privateKey,
+	}, nil
+}
+
+func (s *FCMSender) Send(device Device, message Message) error {
+	accessToken, err := s.token()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": device.Token,
+			"notification": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"data": message.Data,
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/projects/%s/messages:send", s.BaseURL, s.ProjectID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&failure)
+
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound ||
+		(failure.Error.Status == "INVALID_ARGUMENT" && strings.Contains(failure.Error.Message, "registration token")) {
+		return ErrInvalidToken
+	}
+
+	return fmt.Errorf("fcm: send returned status %d: %s", resp.StatusCode, failure.Error.Message)
+}
+
+// token returns an OAuth access token, exchanging a signed assertion for a new one
+// shortly before the current one expires
+func (s *FCMSender) token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.clientEmail,
+		"scope": fcmScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.Client.PostForm(s.tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm: token request returned status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("fcm: invalid token response: %w", err)
+	}
+
+	s.accessToken = token.AccessToken
+	s.expiresAt = now.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+
+	return s.accessToken, nil
+}
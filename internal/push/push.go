@@ -0,0 +1,126 @@
+// Package push delivers notifications to mobile apps through Firebase Cloud Messaging
+// and the Apple Push Notification service, and to browsers through Web Push.
+package push
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Platforms a device can register for
+const (
+	PlatformFCM     = "fcm"
+	PlatformAPNs    = "apns"
+	PlatformWebPush = "webpush"
+)
+
+var (
+	// ErrInvalidToken reports that the provider will never accept the device token
+	// again, e.g. after the app was uninstalled; the device should be removed
+	ErrInvalidToken = errors.New("push: device token is no longer valid")
+
+	// ErrPlatformNotConfigured reports that no sender is configured for the platform
+	ErrPlatformNotConfigured = errors.New("push: platform is not configured")
+)
+
+// Device is a registered app installation or browser subscription. For Web Push the
+// token is the subscription endpoint, with the subscription's P256DH and Auth keys.
+type Device struct {
+	Platform string
+	Token    string
+	P256DH   string
+	Auth     string
+}
+
+// Message is the content of a notification, already in the device's language
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushSender delivers a message to a device
+type PushSender interface {
+	Send(device Device, message Message) error
+}
+
+// Config holds the credentials of each platform; platforms without credentials are
+// not configured
+type Config struct {
+	// Provider is "fake" to record messages in memory instead of delivering them
+	Provider string
+
+	FCMCredentialsFile string
+
+	APNsKeyFile    string
+	APNsKeyID      string
+	APNsTeamID     string
+	APNsTopic      string
+	APNsProduction bool
+
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+}
+
+// New returns a sender for the configured platforms, or nil when none is configured
+func New(config Config) (PushSender, error) {
+	switch config.Provider {
+	case "":
+	case "fake":
+		return NewFakeSender(), nil
+	default:
+		return nil, fmt.Errorf("unknown push provider %q", config.Provider)
+	}
+
+	senders := make(map[string]PushSender)
+	if config.FCMCredentialsFile != "" {
+		sender, err := NewFCMSender(config.FCMCredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		senders[PlatformFCM] = sender
+	}
+	if config.APNsKeyFile != "" {
+		sender, err := NewAPNsSender(config.APNsKeyFile, config.APNsKeyID, config.APNsTeamID, config.APNsTopic, config.APNsProduction)
+		if err != nil {
+			return nil, err
+		}
+		senders[PlatformAPNs] = sender
+	}
+	if config.VAPIDPrivateKey != "" {
+		sender, err := NewWebPushSender(config.VAPIDPrivateKey, config.VAPIDSubject)
+		if err != nil {
+			return nil, err
+		}
+		senders[PlatformWebPush] = sender
+	}
+
+	if len(senders) == 0 {
+		return nil, nil
+	}
+	return Dispatcher(senders), nil
+}
+
+// Dispatcher sends each message with the sender of the device's platform
+type Dispatcher map[string]PushSender
+
+func (d Dispatcher) Send(device Device, message Message) error {
+	sender, ok := d[device.Platform]
+	if !ok {
+		return ErrPlatformNotConfigured
+	}
+	return sender.Send(device, message)
+}
+
+// VAPIDPublicKey returns the application server key browsers subscribe with, or ""
+// when Web Push is not configured
+func VAPIDPublicKey(sender PushSender) string {
+	switch s := sender.(type) {
+	case *WebPushSender:
+		return s.PublicKey()
+	case Dispatcher:
+		return VAPIDPublicKey(s[PlatformWebPush])
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,257 @@
+package push
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+// webPushTTL is how long a push service keeps a message for an offline browser
+const webPushTTL = 24 * time.Hour
+
+// webPushHosts are the push services of the browsers, or with a leading dot their
+// domains. Subscription endpoints are given by clients, so messages are only sent to
+// these hosts and never to an internal address.
+var webPushHosts = []string{
+	"fcm.googleapis.com",                // Chrome, Opera, Samsung Internet
+	"updates.push.services.mozilla.com", // Firefox
+	".push.apple.com",                   // Safari
+	".notify.windows.com",               // Edge
+}
+
+// CheckWebPushEndpoint checks that a subscription endpoint is an https URL of a
+// browser push service
+func CheckWebPushEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil || (u.Port() != "" && u.Port() != "443") {
+		return ErrInvalidToken
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range webPushHosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return nil
+		}
+	}
+	return ErrInvalidToken
+}
+
+// WebPushSender delivers messages to browser push subscriptions, identified to push
+// services by a VAPID key pair (RFC 8292) and encrypted for the browser (RFC 8291)
+type WebPushSender struct {
+	// Subject is a mailto: or https: contact for the push service operator
+	Subject string
+	Client  *http.Client
+
+	key       *ecdsa.PrivateKey
+	publicKey []byte
+}
+
+// NewWebPushSender takes the VAPID private key as the base64url encoded 32-byte
+// scalar; the public key is derived from it
+func NewWebPushSender(privateKey, subject string) (*WebPushSender, error) {
+	if subject == "" {
+		return nil, errors.New("webpush: VAPID subject is required")
+	}
+
+	scalar, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, errors.New("webpush: VAPID private key is not base64url encoded")
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid VAPID private key: %w", err)
+	}
+	publicKey := ecdhKey.PublicKey().Bytes()
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(publicKey[1:33]),
+			Y:     new(big.Int).SetBytes(publicKey[33:]),
+		},
+		D: new(big.Int).SetBytes(scalar),
+	}
+
+	return &WebPushSender{
+		Subject: subject,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+			// Push services answer directly; a redirect could lead outside webPushHosts
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		key:       key,
+		publicKey: publicKey,
+	}, nil
+}
+
+// PublicKey returns the application server key browsers subscribe with
+func (s *WebPushSender) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(s.publicKey)
+}
+
+func (s *WebPushSender) Send(device Device, message Message) error {
+	if err := CheckWebPushEndpoint(device.Token); err != nil {
+		return err
+	}
+	endpoint, err := url.Parse(device.Token)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"title": message.Title,
+		"body":  message.Body,
+		"data":  message.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := encryptWebPush(payload, device.P256DH, device.Auth)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := s.vapidAuthorization(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, device.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		// The subscription expired or the user revoked the permission
+		return ErrInvalidToken
+	default:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webpush: send returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+}
+
+func (s *WebPushSender) vapidAuthorization(audience string) (string, error) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": s.Subject,
+	}).SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	return "vapid t=" + token + ", k=" + s.PublicKey(), nil
+}
+
+// encryptWebPush encrypts the payload for a subscription as a single aes128gcm record
+func encryptWebPush(payload []byte, p256dh, auth string) ([]byte, error) {
+	subscriptionKey, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	authSecret, err := decodeBase64URL(auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, ErrInvalidToken
+	}
+
+	browserKey, err := ecdh.P256().NewPublicKey(subscriptionKey)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// A new key pair and salt for every message
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serverPublicKey := serverKey.PublicKey().Bytes()
+	sharedSecret, err := serverKey.ECDH(browserKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), subscriptionKey...)
+	keyInfo = append(keyInfo, serverPublicKey...)
+	ikm, err := hkdfExpand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The 0x02 delimiter marks the last, and only, record
+	ciphertext := gcm.Seal(nil, nonce, append(payload, 0x02), nil)
+
+	header := make([]byte, 0, 16+4+1+len(serverPublicKey))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, 4096)
+	header = append(header, byte(len(serverPublicKey)))
+	header = append(header, serverPublicKey...)
+
+	return append(header, ciphertext...), nil
+}
+
+func hkdfExpand(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// decodeBase64URL accepts keys with or without padding, as browsers vary
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
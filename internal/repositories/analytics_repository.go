@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
+	"nabung-emas-api/internal/decimal"
 	"nabung-emas-api/internal/models"
 )
 
@@ -13,32 +15,43 @@ func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
 	return &AnalyticsRepository{db: db}
 }
 
-func (r *AnalyticsRepository) GetPortfolioSummary(userID string) (*models.PortfolioSummary, error) {
+// GetPortfolioSummary totals the user's transactions. Amounts are converted into
+// currency at the rate of each transaction date, see idr_to_currency.
+func (r *AnalyticsRepository) GetPortfolioSummary(userID, currency string) (*models.PortfolioSummary, error) {
 	query := `
 		SELECT 
-			COALESCE(SUM(t.total_price), 0) as total_value,
+			COALESCE(SUM(idr_to_currency(t.total_price, $2, t.transaction_date)), 0) as total_value,
+			COALESCE(SUM(idr_to_currency(t.total_fees, $2, t.transaction_date)), 0) as total_fees,
+			COALESCE(SUM(idr_to_currency(t.total_cost, $2, t.transaction_date)), 0) as total_cost,
 			COALESCE(SUM(t.weight), 0) as total_weight,
-			COUNT(DISTINCT p.id) as total_pockets,
+			COALESCE(SUM(t.fine_weight), 0) as total_fine_weight,
+			(SELECT COUNT(*) FROM pockets WHERE user_id = $1) as total_pockets,
 			COUNT(t.id) as total_transactions,
 			CASE 
 				WHEN SUM(t.weight) > 0 
-				THEN SUM(t.total_price) / SUM(t.weight) 
+				THEN SUM(idr_to_currency(t.total_cost, $2, t.transaction_date)) / SUM(t.weight) 
 				ELSE 0 
-			END as average_price_per_gram
-		FROM users u
-		LEFT JOIN pockets p ON p.user_id = u.id
-		LEFT JOIN transactions t ON t.user_id = u.id
-		WHERE u.id = $1
-		GROUP BY u.id, t.id
+			END as average_price_per_gram,
+			CASE 
+				WHEN SUM(t.fine_weight) > 0 
+				THEN SUM(idr_to_currency(t.total_cost, $2, t.transaction_date)) / SUM(t.fine_weight) 
+				ELSE 0 
+			END as average_price_per_fine_gram
+		FROM transactions t
+		WHERE t.user_id = $1
 	`
 
 	summary := &models.PortfolioSummary{}
-	err := r.db.QueryRow(query, userID).Scan(
+	err := r.db.QueryRow(query, userID, currency).Scan(
 		&summary.TotalValue,
+		&summary.TotalFees,
+		&summary.TotalCost,
 		&summary.TotalWeight,
+		&summary.TotalFineWeight,
 		&summary.TotalPockets,
 		&summary.TotalTransactions,
 		&summary.AveragePricePerGram,
+		&summary.AveragePricePerFineGram,
 	)
 
 	if err == sql.ErrNoRows {
@@ -48,7 +61,7 @@ func (r *AnalyticsRepository) GetPortfolioSummary(userID string) (*models.Portfo
 	return summary, err
 }
 
-func (r *AnalyticsRepository) GetPocketDistribution(userID string) ([]models.PocketDistribution, error) {
+func (r *AnalyticsRepository) GetPocketDistribution(userID, currency string) ([]models.PocketDistribution, error) {
 	query := `
 		SELECT 
 			p.id,
@@ -56,21 +69,25 @@ func (r *AnalyticsRepository) GetPocketDistribution(userID string) ([]models.Poc
 			tp.name,
 			tp.color,
 			p.aggregate_total_weight,
-			p.aggregate_total_price
+			p.aggregate_fine_weight,
+			COALESCE((
+				SELECT SUM(idr_to_currency(t.total_price, $2, t.transaction_date))
+				FROM transactions t WHERE t.pocket_id = p.id
+			), 0)
 		FROM pockets p
 		LEFT JOIN type_pockets tp ON tp.id = p.type_pocket_id
 		WHERE p.user_id = $1 AND p.aggregate_total_weight > 0
 		ORDER BY p.aggregate_total_weight DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(query, userID, currency)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var distributions []models.PocketDistribution
-	var totalWeight float64
+	var totalWeight decimal.Decimal
 
 	// First pass: collect data and calculate total
 	type tempDist struct {
@@ -78,8 +95,9 @@ func (r *AnalyticsRepository) GetPocketDistribution(userID string) ([]models.Poc
 		PocketName      string
 		TypePocketName  string
 		TypePocketColor string
-		Weight          float64
-		Value           float64
+		Weight          decimal.Decimal
+		FineWeight      decimal.Decimal
+		Value           decimal.Decimal
 	}
 	var tempDistributions []tempDist
 
@@ -91,20 +109,21 @@ func (r *AnalyticsRepository) GetPocketDistribution(userID string) ([]models.Poc
 			&td.TypePocketName,
 			&td.TypePocketColor,
 			&td.Weight,
+			&td.FineWeight,
 			&td.Value,
 		)
 		if err != nil {
 			return nil, err
 		}
-		totalWeight += td.Weight
+		totalWeight = totalWeight.Add(td.Weight)
 		tempDistributions = append(tempDistributions, td)
 	}
 
 	// Second pass: calculate percentages
 	for _, td := range tempDistributions {
 		percentage := 0.0
-		if totalWeight > 0 {
-			percentage = (td.Weight / totalWeight) * 100
+		if totalWeight.IsPositive() {
+			percentage = td.Weight.Ratio(totalWeight) * 100
 		}
 
 		distributions = append(distributions, models.PocketDistribution{
@@ -113,6 +132,7 @@ func (r *AnalyticsRepository) GetPocketDistribution(userID string) ([]models.Poc
 			TypePocketName:  td.TypePocketName,
 			TypePocketColor: td.TypePocketColor,
 			Weight:          td.Weight,
+			FineWeight:      td.FineWeight,
 			Value:           td.Value,
 			Percentage:      percentage,
 		})
@@ -121,22 +141,23 @@ func (r *AnalyticsRepository) GetPocketDistribution(userID string) ([]models.Poc
 	return distributions, rows.Err()
 }
 
-func (r *AnalyticsRepository) GetMonthlyPurchases(userID string, months int, pocketID *string) ([]models.MonthlyPurchaseData, error) {
+func (r *AnalyticsRepository) GetMonthlyPurchases(userID, currency string, months int, pocketID *string) ([]models.MonthlyPurchaseData, error) {
 	query := `
 		SELECT 
 			TO_CHAR(transaction_date, 'YYYY-MM') as month,
 			SUM(weight) as weight,
-			SUM(total_price) as amount,
+			SUM(idr_to_currency(total_cost, $2, transaction_date)) as amount,
 			COUNT(*) as count,
-			AVG(price_per_gram) as average_price_per_gram
+			AVG(idr_to_currency(price_per_gram, $2, transaction_date)) as average_price_per_gram
 		FROM transactions
 		WHERE user_id = $1
 			AND transaction_date >= CURRENT_DATE - INTERVAL '%d months'
 	`
+	query = fmt.Sprintf(query, months)
 
-	args := []interface{}{userID}
+	args := []interface{}{userID, currency}
 	if pocketID != nil && *pocketID != "" {
-		query += " AND pocket_id = $2"
+		query += " AND pocket_id = $3"
 		args = append(args, *pocketID)
 	}
 
@@ -170,12 +191,15 @@ func (r *AnalyticsRepository) GetMonthlyPurchases(userID string, months int, poc
 	return monthlyData, rows.Err()
 }
 
-func (r *AnalyticsRepository) GetBrandDistribution(userID string) ([]models.BrandDistribution, error) {
+func (r *AnalyticsRepository) GetBrandDistribution(userID, currency string) ([]models.BrandDistribution, error) {
 	query := `
 		SELECT 
 			brand,
 			SUM(weight) as weight,
-			SUM(total_price) as value,
+			SUM(fine_weight) as fine_weight,
+			SUM(idr_to_currency(total_price, $2, transaction_date)) as value,
+			SUM(idr_to_currency(total_fees, $2, transaction_date)) as total_fees,
+			SUM(idr_to_currency(total_cost, $2, transaction_date)) as total_cost,
 			COUNT(*) as transaction_count
 		FROM transactions
 		WHERE user_id = $1
@@ -183,20 +207,23 @@ func (r *AnalyticsRepository) GetBrandDistribution(userID string) ([]models.Bran
 		ORDER BY weight DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(query, userID, currency)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var distributions []models.BrandDistribution
-	var totalWeight float64
+	var totalWeight decimal.Decimal
 
 	// First pass: collect data
 	type tempBrand struct {
 		Brand            string
-		Weight           float64
-		Value            float64
+		Weight           decimal.Decimal
+		FineWeight       decimal.Decimal
+		Value            decimal.Decimal
+		TotalFees        decimal.Decimal
+		TotalCost        decimal.Decimal
 		TransactionCount int
 	}
 	var tempBrands []tempBrand
@@ -206,27 +233,34 @@ func (r *AnalyticsRepository) GetBrandDistribution(userID string) ([]models.Bran
 		err := rows.Scan(
 			&tb.Brand,
 			&tb.Weight,
+			&tb.FineWeight,
 			&tb.Value,
+			&tb.TotalFees,
+			&tb.TotalCost,
 			&tb.TransactionCount,
 		)
 		if err != nil {
 			return nil, err
 		}
-		totalWeight += tb.Weight
+		totalWeight = totalWeight.Add(tb.Weight)
 		tempBrands = append(tempBrands, tb)
 	}
 
 	// Second pass: calculate percentages
 	for _, tb := range tempBrands {
 		percentage := 0.0
-		if totalWeight > 0 {
-			percentage = (tb.Weight / totalWeight) * 100
+		if totalWeight.IsPositive() {
+			percentage = tb.Weight.Ratio(totalWeight) * 100
 		}
 
 		distributions = append(distributions, models.BrandDistribution{
 			Brand:            tb.Brand,
 			Weight:           tb.Weight,
+			FineWeight:       tb.FineWeight,
 			Value:            tb.Value,
+			TotalFees:        tb.TotalFees,
+			TotalCost:        tb.TotalCost,
+			Currency:         currency,
 			TransactionCount: tb.TransactionCount,
 			Percentage:       percentage,
 		})
@@ -234,3 +268,93 @@ func (r *AnalyticsRepository) GetBrandDistribution(userID string) ([]models.Bran
 
 	return distributions, rows.Err()
 }
+
+// GetBrandPremiums compares each purchase with the latest stored market price from
+// the week up to its date. When source is empty, prices from any source are used.
+func (r *AnalyticsRepository) GetBrandPremiums(userID, currency, source string) ([]models.BrandPremium, error) {
+	query := `
+		SELECT 
+			t.brand,
+			SUM(t.weight) as weight,
+			SUM(t.fine_weight) as fine_weight,
+			COUNT(*) as transaction_count,
+			SUM(idr_to_currency(t.total_cost, $3, t.transaction_date)) as total_cost,
+			SUM(idr_to_currency(t.total_fees, $3, t.transaction_date)) as total_fees,
+			COUNT(gp.price_per_gram) as priced_transactions,
+			COALESCE(SUM(t.fine_weight) FILTER (WHERE gp.price_per_gram IS NOT NULL), 0) as priced_fine_weight,
+			COALESCE(SUM(idr_to_currency(ROUND(t.fine_weight * gp.price_per_gram, 2), $3, t.transaction_date)), 0) as spot_equivalent_cost,
+			COALESCE(SUM(idr_to_currency(t.total_price - ROUND(t.fine_weight * gp.price_per_gram, 2), $3, t.transaction_date)), 0) as dealer_premium,
+			COALESCE(SUM(idr_to_currency(t.total_fees, $3, t.transaction_date)) FILTER (WHERE gp.price_per_gram IS NOT NULL), 0) as fee_premium
+		FROM transactions t
+		LEFT JOIN LATERAL (
+			SELECT price_per_gram
+			FROM gold_prices
+			WHERE date <= t.transaction_date
+				AND date > t.transaction_date - 7
+				AND ($2 = '' OR source = $2)
+			ORDER BY date DESC, created_at DESC
+			LIMIT 1
+		) gp ON true
+		WHERE t.user_id = $1
+		GROUP BY t.brand
+		ORDER BY total_cost DESC
+	`
+
+	rows, err := r.db.Query(query, userID, source, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	premiums := []models.BrandPremium{}
+	index := make(map[string]int)
+	for rows.Next() {
+		bp := models.BrandPremium{Currency: currency, FeesByType: make(map[string]decimal.Decimal)}
+		err := rows.Scan(
+			&bp.Brand,
+			&bp.Weight,
+			&bp.FineWeight,
+			&bp.TransactionCount,
+			&bp.TotalCost,
+			&bp.TotalFees,
+			&bp.PricedTransactions,
+			&bp.PricedFineWeight,
+			&bp.SpotEquivalentCost,
+			&bp.DealerPremium,
+			&bp.FeePremium,
+		)
+		if err != nil {
+			return nil, err
+		}
+		index[bp.Brand] = len(premiums)
+		premiums = append(premiums, bp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	feeRows, err := r.db.Query(`
+		SELECT t.brand, f.fee_type, SUM(idr_to_currency(f.amount, $2, t.transaction_date))
+		FROM transaction_fees f
+		JOIN transactions t ON t.id = f.transaction_id
+		WHERE t.user_id = $1
+		GROUP BY t.brand, f.fee_type
+	`, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer feeRows.Close()
+
+	for feeRows.Next() {
+		var brand, feeType string
+		var amount decimal.Decimal
+		if err := feeRows.Scan(&brand, &feeType, &amount); err != nil {
+			return nil, err
+		}
+		if i, ok := index[brand]; ok {
+			premiums[i].FeesByType[feeType] = amount
+		}
+	}
+
+	return premiums, feeRows.Err()
+}
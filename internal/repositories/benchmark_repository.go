@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type BenchmarkRepository struct {
+	db *sql.DB
+}
+
+func NewBenchmarkRepository(db *sql.DB) *BenchmarkRepository {
+	return &BenchmarkRepository{db: db}
+}
+
+const benchmarkValueColumns = `id, benchmark, date, value, source, created_at`
+
+func scanBenchmarkValue(row interface{ Scan(...interface{}) error }) (*models.BenchmarkValue, error) {
+	value := &models.BenchmarkValue{}
+	err := row.Scan(
+		&value.ID,
+		&value.Benchmark,
+		&value.Date,
+		&value.Value,
+		&value.Source,
+		&value.CreatedAt,
+	)
+	return value, err
+}
+
+// Upsert stores the values in a single database transaction. A value already stored
+// for the same benchmark and day is replaced.
+func (r *BenchmarkRepository) Upsert(values []models.BenchmarkValue) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO benchmark_values (id, benchmark, date, value, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (benchmark, date) DO UPDATE
+		SET value = EXCLUDED.value, source = EXCLUDED.source, created_at = EXCLUDED.created_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, value := range values {
+		if _, err := stmt.Exec(uuid.New().String(), value.Benchmark, value.Date, value.Value, value.Source, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindAll returns the stored values of a benchmark, oldest first
+func (r *BenchmarkRepository) FindAll(benchmark string, startDate, endDate *string) ([]models.BenchmarkValue, error) {
+	query := `
+		SELECT ` + benchmarkValueColumns + `
+		FROM benchmark_values
+		WHERE benchmark = $1
+			AND ($2::date IS NULL OR date >= $2::date)
+			AND ($3::date IS NULL OR date <= $3::date)
+		ORDER BY date ASC
+	`
+
+	rows, err := r.db.Query(query, benchmark, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []models.BenchmarkValue{}
+	for rows.Next() {
+		value, err := scanBenchmarkValue(rows)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, *value)
+	}
+
+	return values, rows.Err()
+}
+
+// GetRanges returns per benchmark the number of stored values and their first and
+// last dates, keyed by benchmark
+func (r *BenchmarkRepository) GetRanges() (map[string]models.BenchmarkSeries, error) {
+	query := `
+		SELECT benchmark, COUNT(*), MIN(date), MAX(date)
+		FROM benchmark_values
+		GROUP BY benchmark
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ranges := make(map[string]models.BenchmarkSeries)
+	for rows.Next() {
+		var series models.BenchmarkSeries
+		if err := rows.Scan(&series.Key, &series.ValueCount, &series.FirstDate, &series.LastDate); err != nil {
+			return nil, err
+		}
+		ranges[series.Key] = series
+	}
+
+	return ranges, rows.Err()
+}
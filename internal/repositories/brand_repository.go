@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type BrandRepository struct {
+	db *sql.DB
+}
+
+func NewBrandRepository(db *sql.DB) *BrandRepository {
+	return &BrandRepository{db: db}
+}
+
+const brandColumns = `id, name, logo_url, color, active, created_at, updated_at`
+
+func scanBrand(row interface{ Scan(...interface{}) error }) (*models.Brand, error) {
+	brand := &models.Brand{}
+	err := row.Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.LogoURL,
+		&brand.Color,
+		&brand.Active,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+	)
+	return brand, err
+}
+
+func (r *BrandRepository) Create(brand *models.Brand) error {
+	query := `
+		INSERT INTO brands (id, name, logo_url, color, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	brand.ID = uuid.New().String()
+	now := time.Now()
+
+	return r.db.QueryRow(
+		query,
+		brand.ID,
+		brand.Name,
+		brand.LogoURL,
+		brand.Color,
+		brand.Active,
+		now,
+		now,
+	).Scan(&brand.ID, &brand.CreatedAt, &brand.UpdatedAt)
+}
+
+func (r *BrandRepository) FindAll(activeOnly bool) ([]models.Brand, error) {
+	query := `SELECT ` + brandColumns + ` FROM brands`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	query += ` ORDER BY name ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brands []models.Brand
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, err
+		}
+		brands = append(brands, *brand)
+	}
+
+	return brands, rows.Err()
+}
+
+func (r *BrandRepository) FindByID(id string) (*models.Brand, error) {
+	query := `SELECT ` + brandColumns + ` FROM brands WHERE id = $1`
+
+	brand, err := scanBrand(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("brand not found")
+	}
+
+	return brand, err
+}
+
+func (r *BrandRepository) NameExists(name, excludeID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM brands WHERE LOWER(name) = LOWER($1) AND id::text != $2)`
+
+	var exists bool
+	err := r.db.QueryRow(query, name, excludeID).Scan(&exists)
+	return exists, err
+}
+
+// Update saves the brand. Records keep a copy of the brand name for display and
+// filtering, so a rename is applied to them in the same transaction.
+func (r *BrandRepository) Update(brand *models.Brand) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE brands
+		SET name = $1, logo_url = $2, color = $3, active = $4, updated_at = $5
+		WHERE id = $6
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		brand.Name,
+		brand.LogoURL,
+		brand.Color,
+		brand.Active,
+		time.Now(),
+		brand.ID,
+	).Scan(&brand.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errors.New("brand not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"transactions", "recurring_plans", "installment_contracts", "inventory_items", "price_alerts"} {
+		if _, err := tx.Exec(`UPDATE `+table+` SET brand = $1 WHERE brand_id = $2 AND brand != $1`, brand.Name, brand.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IsInUse reports whether any record references the brand
+func (r *BrandRepository) IsInUse(id string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM transactions WHERE brand_id = $1)
+			OR EXISTS(SELECT 1 FROM recurring_plans WHERE brand_id = $1)
+			OR EXISTS(SELECT 1 FROM installment_contracts WHERE brand_id = $1)
+			OR EXISTS(SELECT 1 FROM inventory_items WHERE brand_id = $1)
+			OR EXISTS(SELECT 1 FROM price_alerts WHERE brand_id = $1)
+	`
+
+	var inUse bool
+	err := r.db.QueryRow(query, id).Scan(&inUse)
+	return inUse, err
+}
+
+func (r *BrandRepository) Delete(id string) error {
+	query := `DELETE FROM brands WHERE id = $1`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("brand not found")
+	}
+
+	return nil
+}
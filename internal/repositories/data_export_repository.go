@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type DataExportRepository struct {
+	db *sql.DB
+}
+
+func NewDataExportRepository(db *sql.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+const dataExportColumns = `
+	id, user_id, status, file_key, file_size, error,
+	expires_at, completed_at, created_at, updated_at
+`
+
+func scanDataExport(row interface{ Scan(...interface{}) error }) (*models.DataExport, error) {
+	e := &models.DataExport{}
+	err := row.Scan(
+		&e.ID,
+		&e.UserID,
+		&e.Status,
+		&e.FileKey,
+		&e.FileSize,
+		&e.Error,
+		&e.ExpiresAt,
+		&e.CompletedAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	return e, err
+}
+
+func (r *DataExportRepository) Create(export *models.DataExport) error {
+	query := `
+		INSERT INTO data_exports (id, user_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	export.ID = uuid.New().String()
+	export.Status = models.ExportStatusPending
+	now := time.Now()
+
+	return r.db.QueryRow(query, export.ID, export.UserID, export.Status, now, now).
+		Scan(&export.ID, &export.CreatedAt, &export.UpdatedAt)
+}
+
+func (r *DataExportRepository) FindByID(id, userID string) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1 AND user_id = $2`
+
+	export, err := scanDataExport(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("export not found")
+	}
+
+	return export, err
+}
+
+// FindByIDUnscoped looks up an export without an owner check.
+// Only use it after the caller has been authorized in another way (e.g. a signed link).
+func (r *DataExportRepository) FindByIDUnscoped(id string) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1`
+
+	export, err := scanDataExport(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("export not found")
+	}
+
+	return export, err
+}
+
+func (r *DataExportRepository) FindAllByUser(userID string) ([]models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE user_id = $1 ORDER BY created_at DESC`
+	return r.query(query, userID)
+}
+
+// FindUnfinished returns pending and processing exports, oldest first
+func (r *DataExportRepository) FindUnfinished() ([]models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE status IN ($1, $2) ORDER BY created_at ASC`
+	return r.query(query, models.ExportStatusPending, models.ExportStatusProcessing)
+}
+
+// FindExpired returns exports whose download link expired before the given time
+func (r *DataExportRepository) FindExpired(before time.Time) ([]models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE expires_at IS NOT NULL AND expires_at <= $1`
+	return r.query(query, before)
+}
+
+func (r *DataExportRepository) HasUnfinished(userID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM data_exports WHERE user_id = $1 AND status IN ($2, $3))`
+
+	var exists bool
+	err := r.db.QueryRow(query, userID, models.ExportStatusPending, models.ExportStatusProcessing).Scan(&exists)
+	return exists, err
+}
+
+// Claim marks a pending export as processing, so only one server builds it. An export
+// left processing for over an hour was interrupted and can be claimed again.
+// It returns false when the export was claimed by someone else.
+func (r *DataExportRepository) Claim(id string) (bool, error) {
+	query := `
+		UPDATE data_exports SET status = $1, updated_at = $2
+		WHERE id = $3 AND (status = $4 OR (status = $1 AND updated_at < CURRENT_TIMESTAMP - INTERVAL '1 hour'))
+	`
+	result, err := r.db.Exec(query, models.ExportStatusProcessing, time.Now(), id, models.ExportStatusPending)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (r *DataExportRepository) MarkCompleted(id, fileKey string, fileSize int64, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = $1, file_key = $2, file_size = $3, expires_at = $4, completed_at = $5, updated_at = $5
+		WHERE id = $6
+	`
+	_, err := r.db.Exec(query, models.ExportStatusCompleted, fileKey, fileSize, expiresAt, time.Now(), id)
+	return err
+}
+
+func (r *DataExportRepository) MarkFailed(id, message string) error {
+	query := `UPDATE data_exports SET status = $1, error = $2, updated_at = $3 WHERE id = $4`
+	_, err := r.db.Exec(query, models.ExportStatusFailed, message, time.Now(), id)
+	return err
+}
+
+func (r *DataExportRepository) Delete(id string) error {
+	query := `DELETE FROM data_exports WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// GetFileKeysByUser returns the stored archive references of all exports of a user
+func (r *DataExportRepository) GetFileKeysByUser(userID string) ([]string, error) {
+	query := `SELECT file_key FROM data_exports WHERE user_id = $1 AND file_key IS NOT NULL`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *DataExportRepository) query(query string, args ...interface{}) ([]models.DataExport, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []models.DataExport
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, *export)
+	}
+
+	return exports, rows.Err()
+}
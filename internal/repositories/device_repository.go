@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type DeviceRepository struct {
+	db *sql.DB
+}
+
+func NewDeviceRepository(db *sql.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+const deviceColumns = `
+	id, user_id, platform, token, p256dh, auth, language, name, last_used_at, created_at, updated_at
+`
+
+func scanDevice(row interface{ Scan(...interface{}) error }) (*models.PushDevice, error) {
+	device := &models.PushDevice{}
+	err := row.Scan(
+		&device.ID,
+		&device.UserID,
+		&device.Platform,
+		&device.Token,
+		&device.P256DH,
+		&device.Auth,
+		&device.Language,
+		&device.Name,
+		&device.LastUsedAt,
+		&device.CreatedAt,
+		&device.UpdatedAt,
+	)
+	return device, err
+}
+
+// Upsert registers the device, moving a token already registered to the device's user
+func (r *DeviceRepository) Upsert(device *models.PushDevice) error {
+	query := `
+		INSERT INTO push_devices (id, user_id, platform, token, p256dh, auth, language, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (platform, md5(token)) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+		    language = EXCLUDED.language, name = EXCLUDED.name, updated_at = EXCLUDED.updated_at
+		RETURNING ` + deviceColumns
+
+	now := time.Now()
+	registered, err := scanDevice(r.db.QueryRow(
+		query,
+		uuid.New().String(),
+		device.UserID,
+		device.Platform,
+		device.Token,
+		device.P256DH,
+		device.Auth,
+		device.Language,
+		device.Name,
+		now,
+		now,
+	))
+	if err != nil {
+		return err
+	}
+
+	*device = *registered
+	return nil
+}
+
+func (r *DeviceRepository) FindByUser(userID string) ([]models.PushDevice, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM push_devices
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []models.PushDevice{}
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *device)
+	}
+
+	return devices, rows.Err()
+}
+
+func (r *DeviceRepository) Delete(id, userID string) error {
+	result, err := r.db.Exec(`DELETE FROM push_devices WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("device not found")
+	}
+
+	return nil
+}
+
+// Prune removes a device its provider reported as no longer valid
+func (r *DeviceRepository) Prune(id string) error {
+	_, err := r.db.Exec(`DELETE FROM push_devices WHERE id = $1`, id)
+	return err
+}
+
+func (r *DeviceRepository) MarkUsed(id string) error {
+	_, err := r.db.Exec(`UPDATE push_devices SET last_used_at = $1 WHERE id = $2`, time.Now(), id)
+	return err
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type ExchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+const exchangeRateColumns = `id, date, currency, rate, source, created_at`
+
+func scanExchangeRate(row interface{ Scan(...interface{}) error }) (*models.ExchangeRate, error) {
+	rate := &models.ExchangeRate{}
+	err := row.Scan(
+		&rate.ID,
+		&rate.Date,
+		&rate.Currency,
+		&rate.Rate,
+		&rate.Source,
+		&rate.CreatedAt,
+	)
+	return rate, err
+}
+
+// Upsert stores the rates in a single database transaction. A rate already stored
+// for the same day and currency is replaced.
+func (r *ExchangeRateRepository) Upsert(rates []models.ExchangeRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO exchange_rates (id, date, currency, rate, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (date, currency) DO UPDATE
+		SET rate = EXCLUDED.rate, source = EXCLUDED.source, created_at = EXCLUDED.created_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, rate := range rates {
+		if _, err := stmt.Exec(uuid.New().String(), rate.Date, rate.Currency, rate.Rate, rate.Source, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindAll returns the stored rates of a currency, newest first
+func (r *ExchangeRateRepository) FindAll(currency string, startDate, endDate *string) ([]models.ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE currency = $1
+			AND ($2::date IS NULL OR date >= $2::date)
+			AND ($3::date IS NULL OR date <= $3::date)
+		ORDER BY date DESC
+	`
+
+	rows, err := r.db.Query(query, currency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// FindOnOrBefore returns the most recent rate of a currency on or before the given date
+func (r *ExchangeRateRepository) FindOnOrBefore(currency string, date time.Time) (*models.ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE currency = $1 AND date <= $2
+		ORDER BY date DESC
+		LIMIT 1
+	`
+
+	rate, err := scanExchangeRate(r.db.QueryRow(query, currency, date))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("exchange rate not found")
+	}
+
+	return rate, err
+}
+
+// FindEarliestDate returns the first day a rate of the currency is stored for
+func (r *ExchangeRateRepository) FindEarliestDate(currency string) (*time.Time, error) {
+	var date *time.Time
+	err := r.db.QueryRow(`SELECT MIN(date) FROM exchange_rates WHERE currency = $1`, currency).Scan(&date)
+	return date, err
+}
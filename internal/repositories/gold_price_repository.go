@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type GoldPriceRepository struct {
+	db *sql.DB
+}
+
+func NewGoldPriceRepository(db *sql.DB) *GoldPriceRepository {
+	return &GoldPriceRepository{db: db}
+}
+
+// FindOnOrBefore returns the most recent stored price on or before the given date.
+// When source is empty, prices from any source are considered.
+func (r *GoldPriceRepository) FindOnOrBefore(date time.Time, source string) (*models.GoldPrice, error) {
+	query := `
+		SELECT id, date, price_per_gram, source, created_at
+		FROM gold_prices
+		WHERE date <= $1 AND ($2 = '' OR source = $2)
+		ORDER BY date DESC, created_at DESC
+		LIMIT 1
+	`
+
+	price := &models.GoldPrice{}
+	err := r.db.QueryRow(query, date, source).Scan(
+		&price.ID,
+		&price.Date,
+		&price.PricePerGram,
+		&price.Source,
+		&price.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("gold price not found")
+	}
+
+	return price, err
+}
+
+// FindBetween returns the stored prices between two dates inclusive, oldest first.
+// When source is empty, prices from any source are returned.
+func (r *GoldPriceRepository) FindBetween(startDate, endDate time.Time, source string) ([]models.GoldPrice, error) {
+	query := `
+		SELECT id, date, price_per_gram, source, created_at
+		FROM gold_prices
+		WHERE date >= $1 AND date <= $2 AND ($3 = '' OR source = $3)
+		ORDER BY date ASC, created_at ASC
+	`
+
+	rows, err := r.db.Query(query, startDate, endDate, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []models.GoldPrice{}
+	for rows.Next() {
+		var price models.GoldPrice
+		err := rows.Scan(
+			&price.ID,
+			&price.Date,
+			&price.PricePerGram,
+			&price.Source,
+			&price.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
+
+// Upsert stores the prices in a single database transaction. A price already stored
+// for the same day and source is replaced.
+func (r *GoldPriceRepository) Upsert(prices []models.GoldPrice) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO gold_prices (id, date, price_per_gram, source, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (date, source) DO UPDATE
+		SET price_per_gram = EXCLUDED.price_per_gram, created_at = EXCLUDED.created_at
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for i := range prices {
+		price := &prices[i]
+		err := stmt.QueryRow(uuid.New().String(), price.Date, price.PricePerGram, price.Source, now).Scan(&price.ID, &price.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindLatestDate returns the newest day a price of the source is stored for
+func (r *GoldPriceRepository) FindLatestDate(source string) (*time.Time, error) {
+	var date *time.Time
+	err := r.db.QueryRow(`SELECT MAX(date) FROM gold_prices WHERE source = $1`, source).Scan(&date)
+	return date, err
+}
@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type InstallmentRepository struct {
+	db *sql.DB
+}
+
+func NewInstallmentRepository(db *sql.DB) *InstallmentRepository {
+	return &InstallmentRepository{db: db}
+}
+
+const installmentContractColumns = `
+	ic.id, ic.user_id, ic.pocket_id, ic.provider, ic.brand, ic.brand_id, ic.weight, ic.price_per_gram,
+	ic.down_payment, ic.tenor_months, ic.margin_amount, ic.monthly_installment, ic.contract_date,
+	ic.first_due_date, ic.status, ic.transaction_id, ic.notes, ic.created_at, ic.updated_at,
+	p.id, p.name,
+	(SELECT COALESCE(SUM(paid_amount), 0) FROM installment_payments WHERE contract_id = ic.id),
+	(SELECT COUNT(*) FROM installment_payments WHERE contract_id = ic.id AND paid_at IS NOT NULL),
+	(SELECT MIN(due_date) FROM installment_payments WHERE contract_id = ic.id AND paid_at IS NULL)
+`
+
+func scanInstallmentContract(row interface{ Scan(...interface{}) error }) (*models.InstallmentContract, error) {
+	contract := &models.InstallmentContract{}
+	pocket := &models.Pocket{}
+	var installmentsPaid float64
+	err := row.Scan(
+		&contract.ID,
+		&contract.UserID,
+		&contract.PocketID,
+		&contract.Provider,
+		&contract.Brand,
+		&contract.BrandID,
+		&contract.Weight,
+		&contract.PricePerGram,
+		&contract.DownPayment,
+		&contract.TenorMonths,
+		&contract.MarginAmount,
+		&contract.MonthlyInstallment,
+		&contract.ContractDate,
+		&contract.FirstDueDate,
+		&contract.Status,
+		&contract.TransactionID,
+		&contract.Notes,
+		&contract.CreatedAt,
+		&contract.UpdatedAt,
+		&pocket.ID,
+		&pocket.Name,
+		&installmentsPaid,
+		&contract.PaidInstallments,
+		&contract.NextDueDate,
+	)
+	contract.Pocket = pocket
+	contract.TotalPaid = contract.DownPayment + installmentsPaid
+	return contract, err
+}
+
+// Create stores the contract together with its payment schedule
+func (r *InstallmentRepository) Create(contract *models.InstallmentContract, schedule []models.InstallmentPayment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO installment_contracts (
+			id, user_id, pocket_id, provider, brand, brand_id, weight, price_per_gram, down_payment,
+			tenor_months, margin_amount, monthly_installment, contract_date, first_due_date,
+			status, notes, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING created_at, updated_at
+	`
+
+	contract.ID = uuid.New().String()
+	now := time.Now()
+
+	err = tx.QueryRow(
+		query,
+		contract.ID,
+		contract.UserID,
+		contract.PocketID,
+		contract.Provider,
+		contract.Brand,
+		contract.BrandID,
+		contract.Weight,
+		contract.PricePerGram,
+		contract.DownPayment,
+		contract.TenorMonths,
+		contract.MarginAmount,
+		contract.MonthlyInstallment,
+		contract.ContractDate,
+		contract.FirstDueDate,
+		contract.Status,
+		contract.Notes,
+		now,
+		now,
+	).Scan(&contract.CreatedAt, &contract.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO installment_payments (id, contract_id, user_id, sequence, due_date, amount_due, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range schedule {
+		payment := &schedule[i]
+		payment.ID = uuid.New().String()
+		payment.ContractID = contract.ID
+		payment.UserID = contract.UserID
+		payment.CreatedAt = now
+		payment.UpdatedAt = now
+
+		_, err := stmt.Exec(payment.ID, payment.ContractID, payment.UserID, payment.Sequence, payment.DueDate, payment.AmountDue, now, now)
+		if err != nil {
+			return fmt.Errorf("installment %d: %w", payment.Sequence, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *InstallmentRepository) FindAll(userID string, status *string) ([]models.InstallmentContract, error) {
+	query := `
+		SELECT ` + installmentContractColumns + `
+		FROM installment_contracts ic
+		LEFT JOIN pockets p ON p.id = ic.pocket_id
+		WHERE ic.user_id = $1
+	`
+	args := []interface{}{userID}
+
+	if status != nil && *status != "" {
+		query += " AND ic.status = $2"
+		args = append(args, *status)
+	}
+
+	query += " ORDER BY ic.contract_date DESC, ic.created_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contracts := []models.InstallmentContract{}
+	for rows.Next() {
+		contract, err := scanInstallmentContract(rows)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, *contract)
+	}
+
+	return contracts, rows.Err()
+}
+
+func (r *InstallmentRepository) FindByID(id, userID string) (*models.InstallmentContract, error) {
+	query := `
+		SELECT ` + installmentContractColumns + `
+		FROM installment_contracts ic
+		LEFT JOIN pockets p ON p.id = ic.pocket_id
+		WHERE ic.id = $1 AND ic.user_id = $2
+	`
+
+	contract, err := scanInstallmentContract(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("installment contract not found")
+	}
+
+	return contract, err
+}
+
+func (r *InstallmentRepository) FindSchedule(contractID string) ([]models.InstallmentPayment, error) {
+	query := `
+		SELECT id, contract_id, user_id, sequence, due_date, amount_due, paid_amount, paid_at,
+		       reminded_at, created_at, updated_at
+		FROM installment_payments
+		WHERE contract_id = $1
+		ORDER BY sequence ASC
+	`
+
+	rows, err := r.db.Query(query, contractID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedule := []models.InstallmentPayment{}
+	for rows.Next() {
+		var p models.InstallmentPayment
+		err := rows.Scan(
+			&p.ID,
+			&p.ContractID,
+			&p.UserID,
+			&p.Sequence,
+			&p.DueDate,
+			&p.AmountDue,
+			&p.PaidAmount,
+			&p.PaidAt,
+			&p.RemindedAt,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, p)
+	}
+
+	return schedule, rows.Err()
+}
+
+// FindDueForReminder returns the next unpaid installment of each active contract when it
+// is due on or before the given date and has not been reminded, of users who have not
+// deleted their account
+func (r *InstallmentRepository) FindDueForReminder(date time.Time) ([]models.InstallmentPayment, error) {
+	query := `
+		SELECT ip.id, ip.contract_id, ip.user_id, ip.sequence, ip.due_date, ip.amount_due
+		FROM installment_payments ip
+		INNER JOIN installment_contracts ic ON ic.id = ip.contract_id
+		INNER JOIN users u ON u.id = ip.user_id
+		WHERE ic.status = 'active' AND ip.paid_at IS NULL AND ip.due_date <= $1 AND ip.reminded_at IS NULL
+			AND ip.sequence = (
+				SELECT MIN(sequence) FROM installment_payments WHERE contract_id = ip.contract_id AND paid_at IS NULL
+			)
+			AND u.deleted_at IS NULL
+		ORDER BY ip.due_date ASC
+	`
+
+	rows, err := r.db.Query(query, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.InstallmentPayment{}
+	for rows.Next() {
+		var p models.InstallmentPayment
+		if err := rows.Scan(&p.ID, &p.ContractID, &p.UserID, &p.Sequence, &p.DueDate, &p.AmountDue); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+func (r *InstallmentRepository) MarkReminded(paymentID string) error {
+	_, err := r.db.Exec(`UPDATE installment_payments SET reminded_at = $1 WHERE id = $2`, time.Now(), paymentID)
+	return err
+}
+
+// RecordPayment marks an installment as paid. When completion is given the contract is
+// closed and the completed purchase is stored as a transaction in the same database transaction.
+func (r *InstallmentRepository) RecordPayment(payment *models.InstallmentPayment, contract *models.InstallmentContract, completion *models.Transaction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	// The conditions keep a payment recorded concurrently from being recorded twice, and
+	// the gold of a contract from being added to the pocket twice
+	result, err := tx.Exec(
+		`UPDATE installment_payments SET paid_amount = $1, paid_at = $2, updated_at = $3 WHERE id = $4 AND paid_at IS NULL`,
+		payment.PaidAmount, payment.PaidAt, now, payment.ID,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("installment has already been paid")
+	}
+
+	if completion != nil {
+		stmt, err := tx.Prepare(insertTransactionQuery)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		if err := insertTransaction(tx, stmt, completion, now); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(
+			`UPDATE installment_contracts SET status = $1, transaction_id = $2, updated_at = $3 WHERE id = $4 AND status = $5`,
+			models.InstallmentStatusCompleted, completion.ID, now, contract.ID, models.InstallmentStatusActive,
+		)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return errors.New("installment contract is not active")
+		}
+		contract.Status = models.InstallmentStatusCompleted
+		contract.TransactionID = &completion.ID
+	}
+
+	return tx.Commit()
+}
+
+func (r *InstallmentRepository) UpdateStatus(id, userID, status string) error {
+	result, err := r.db.Exec(
+		`UPDATE installment_contracts SET status = $1, updated_at = $2 WHERE id = $3 AND user_id = $4`,
+		status, time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("installment contract not found")
+	}
+
+	return nil
+}
+
+func (r *InstallmentRepository) Delete(id, userID string) error {
+	query := `DELETE FROM installment_contracts WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("installment contract not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type InventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+
+const inventoryItemColumns = `
+	ii.id, ii.user_id, ii.pocket_id, ii.transaction_id, ii.brand, ii.brand_id, ii.serial_number, ii.piece_weight,
+	ii.packaging, ii.storage_location, ii.notes, ii.created_at, ii.updated_at, p.id, p.name
+`
+
+func scanInventoryItem(row interface{ Scan(...interface{}) error }) (*models.InventoryItem, error) {
+	item := &models.InventoryItem{}
+	pocket := &models.Pocket{}
+	err := row.Scan(
+		&item.ID,
+		&item.UserID,
+		&item.PocketID,
+		&item.TransactionID,
+		&item.Brand,
+		&item.BrandID,
+		&item.SerialNumber,
+		&item.PieceWeight,
+		&item.Packaging,
+		&item.StorageLocation,
+		&item.Notes,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&pocket.ID,
+		&pocket.Name,
+	)
+	item.Pocket = pocket
+	return item, err
+}
+
+// CreateBatch inserts all items in a single database transaction
+func (r *InventoryRepository) CreateBatch(items []*models.InventoryItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO inventory_items (
+			id, user_id, pocket_id, transaction_id, brand, brand_id, serial_number, piece_weight,
+			packaging, storage_location, notes, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, item := range items {
+		item.ID = uuid.New().String()
+		item.CreatedAt = now
+		item.UpdatedAt = now
+
+		_, err := stmt.Exec(
+			item.ID,
+			item.UserID,
+			item.PocketID,
+			item.TransactionID,
+			item.Brand,
+			item.BrandID,
+			item.SerialNumber,
+			item.PieceWeight,
+			item.Packaging,
+			item.StorageLocation,
+			item.Notes,
+			now,
+			now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Search finds items by serial number, storage location, packaging or notes (q),
+// optionally filtered by brand, pocket and exact storage location
+func (r *InventoryRepository) Search(userID string, q, brand, pocketID, location *string, page, limit int) ([]models.InventoryItem, int, error) {
+	where := " WHERE ii.user_id = $1"
+	args := []interface{}{userID}
+
+	if q != nil && *q != "" {
+		args = append(args, "%"+*q+"%")
+		n := len(args)
+		where += fmt.Sprintf(" AND (ii.serial_number ILIKE $%d OR ii.storage_location ILIKE $%d OR ii.packaging ILIKE $%d OR ii.notes ILIKE $%d)", n, n, n, n)
+	}
+	if brand != nil && *brand != "" {
+		args = append(args, *brand)
+		where += fmt.Sprintf(" AND ii.brand = $%d", len(args))
+	}
+	if pocketID != nil && *pocketID != "" {
+		args = append(args, *pocketID)
+		where += fmt.Sprintf(" AND ii.pocket_id = $%d", len(args))
+	}
+	if location != nil && *location != "" {
+		args = append(args, *location)
+		where += fmt.Sprintf(" AND ii.storage_location = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM inventory_items ii`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + inventoryItemColumns + `
+		FROM inventory_items ii
+		LEFT JOIN pockets p ON p.id = ii.pocket_id
+	` + where + fmt.Sprintf(" ORDER BY ii.created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := []models.InventoryItem{}
+	for rows.Next() {
+		item, err := scanInventoryItem(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, total, rows.Err()
+}
+
+func (r *InventoryRepository) FindByID(id, userID string) (*models.InventoryItem, error) {
+	query := `
+		SELECT ` + inventoryItemColumns + `
+		FROM inventory_items ii
+		LEFT JOIN pockets p ON p.id = ii.pocket_id
+		WHERE ii.id = $1 AND ii.user_id = $2
+	`
+
+	item, err := scanInventoryItem(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("inventory item not found")
+	}
+
+	return item, err
+}
+
+func (r *InventoryRepository) Update(item *models.InventoryItem) error {
+	query := `
+		UPDATE inventory_items
+		SET pocket_id = $1, serial_number = $2, packaging = $3, storage_location = $4, notes = $5, updated_at = $6
+		WHERE id = $7 AND user_id = $8
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		item.PocketID,
+		item.SerialNumber,
+		item.Packaging,
+		item.StorageLocation,
+		item.Notes,
+		time.Now(),
+		item.ID,
+		item.UserID,
+	).Scan(&item.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return errors.New("inventory item not found")
+	}
+
+	return err
+}
+
+func (r *InventoryRepository) Delete(id, userID string) error {
+	query := `DELETE FROM inventory_items WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("inventory item not found")
+	}
+
+	return nil
+}
+
+// SerialExists reports whether the user already has a bar of this brand with the serial number
+func (r *InventoryRepository) SerialExists(userID, brand, serialNumber, excludeID string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM inventory_items
+			WHERE user_id = $1 AND brand = $2 AND serial_number = $3 AND id::text != $4
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRow(query, userID, brand, serialNumber, excludeID).Scan(&exists)
+	return exists, err
+}
+
+// GetTransactionWeight sums the piece weights already recorded for a purchase
+func (r *InventoryRepository) GetTransactionWeight(transactionID string) (float64, error) {
+	var weight float64
+	err := r.db.QueryRow(
+		`SELECT COALESCE(SUM(piece_weight), 0) FROM inventory_items WHERE transaction_id = $1`,
+		transactionID,
+	).Scan(&weight)
+	return weight, err
+}
+
+// GetLocations summarizes how many pieces and grams are kept in each storage location
+func (r *InventoryRepository) GetLocations(userID string) ([]models.StorageLocationSummary, error) {
+	query := `
+		SELECT storage_location, COUNT(*), SUM(piece_weight)
+		FROM inventory_items
+		WHERE user_id = $1
+		GROUP BY storage_location
+		ORDER BY SUM(piece_weight) DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []models.StorageLocationSummary{}
+	for rows.Next() {
+		var l models.StorageLocationSummary
+		if err := rows.Scan(&l.StorageLocation, &l.ItemCount, &l.TotalWeight); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+
+	return locations, rows.Err()
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, type, title, body, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	if notification.Data == nil {
+		notification.Data = map[string]string{}
+	}
+	data, err := json.Marshal(notification.Data)
+	if err != nil {
+		return err
+	}
+
+	notification.ID = uuid.New().String()
+
+	return r.db.QueryRow(
+		query,
+		notification.ID,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.Body,
+		data,
+		time.Now(),
+	).Scan(&notification.CreatedAt)
+}
+
+// FindPage returns up to limit notifications of the user, newest first, created before
+// the notification identified by beforeTime and beforeID when those are set
+func (r *NotificationRepository) FindPage(userID string, beforeTime *time.Time, beforeID *string, unreadOnly bool, limit int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, type, title, body, data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+			AND ($2::timestamp IS NULL OR (created_at, id) < ($2::timestamp, $3::uuid))
+			AND ($4 = FALSE OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5
+	`
+
+	rows, err := r.db.Query(query, userID, beforeTime, beforeID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var notification models.Notification
+		var data []byte
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.Title,
+			&notification.Body,
+			&data,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &notification.Data); err != nil {
+			return nil, err
+		}
+		notification.Read = notification.ReadAt != nil
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(userID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepository) MarkRead(id, userID string) error {
+	result, err := r.db.Exec(
+		`UPDATE notifications SET read_at = COALESCE(read_at, $1) WHERE id = $2 AND user_id = $3`,
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("notification not found")
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many
+// were unread
+func (r *NotificationRepository) MarkAllRead(userID string) (int, error) {
+	result, err := r.db.Exec(
+		`UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return int(rowsAffected), err
+}
+
+// FindPreferences returns the stored preferences of the user by type
+func (r *NotificationRepository) FindPreferences(userID string) (map[string]models.NotificationPreference, error) {
+	rows, err := r.db.Query(
+		`SELECT type, in_app, email, push FROM notification_preferences WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	preferences := make(map[string]models.NotificationPreference)
+	for rows.Next() {
+		var preference models.NotificationPreference
+		if err := rows.Scan(&preference.Type, &preference.InApp, &preference.Email, &preference.Push); err != nil {
+			return nil, err
+		}
+		preferences[preference.Type] = preference
+	}
+
+	return preferences, rows.Err()
+}
+
+// UpsertPreferences stores the preferences of the user in a single database transaction
+func (r *NotificationRepository) UpsertPreferences(userID string, preferences []models.NotificationPreference) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO notification_preferences (user_id, type, in_app, email, push, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, type) DO UPDATE
+		SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, push = EXCLUDED.push, updated_at = EXCLUDED.updated_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, preference := range preferences {
+		if _, err := stmt.Exec(userID, preference.Type, preference.InApp, preference.Email, preference.Push, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+)
+
+type PledgeRepository struct {
+	db *sql.DB
+}
+
+func NewPledgeRepository(db *sql.DB) *PledgeRepository {
+	return &PledgeRepository{db: db}
+}
+
+const pledgeColumns = `
+	gp.id, gp.user_id, gp.pocket_id, gp.transaction_id, gp.lender, gp.weight, gp.loan_principal,
+	gp.fee_rate, gp.fee_period_days, gp.pledged_at, gp.due_date, gp.status, gp.redeemed_at,
+	gp.redemption_amount, gp.notes, gp.reminded_at, gp.created_at, gp.updated_at, p.id, p.name
+`
+
+func scanPledge(row interface{ Scan(...interface{}) error }) (*models.GoldPledge, error) {
+	pledge := &models.GoldPledge{}
+	pocket := &models.Pocket{}
+	err := row.Scan(
+		&pledge.ID,
+		&pledge.UserID,
+		&pledge.PocketID,
+		&pledge.TransactionID,
+		&pledge.Lender,
+		&pledge.Weight,
+		&pledge.LoanPrincipal,
+		&pledge.FeeRate,
+		&pledge.FeePeriodDays,
+		&pledge.PledgedAt,
+		&pledge.DueDate,
+		&pledge.Status,
+		&pledge.RedeemedAt,
+		&pledge.RedemptionAmount,
+		&pledge.Notes,
+		&pledge.RemindedAt,
+		&pledge.CreatedAt,
+		&pledge.UpdatedAt,
+		&pocket.ID,
+		&pocket.Name,
+	)
+	pledge.Pocket = pocket
+	return pledge, err
+}
+
+func (r *PledgeRepository) Create(pledge *models.GoldPledge) error {
+	query := `
+		INSERT INTO gold_pledges (
+			id, user_id, pocket_id, transaction_id, lender, weight, loan_principal, fee_rate,
+			fee_period_days, pledged_at, due_date, status, notes, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING created_at, updated_at
+	`
+
+	pledge.ID = uuid.New().String()
+	now := time.Now()
+
+	return r.db.QueryRow(
+		query,
+		pledge.ID,
+		pledge.UserID,
+		pledge.PocketID,
+		pledge.TransactionID,
+		pledge.Lender,
+		pledge.Weight,
+		pledge.LoanPrincipal,
+		pledge.FeeRate,
+		pledge.FeePeriodDays,
+		pledge.PledgedAt,
+		pledge.DueDate,
+		pledge.Status,
+		pledge.Notes,
+		now,
+		now,
+	).Scan(&pledge.CreatedAt, &pledge.UpdatedAt)
+}
+
+func (r *PledgeRepository) FindAll(userID string, status *string) ([]models.GoldPledge, error) {
+	query := `
+		SELECT ` + pledgeColumns + `
+		FROM gold_pledges gp
+		LEFT JOIN pockets p ON p.id = gp.pocket_id
+		WHERE gp.user_id = $1
+	`
+	args := []interface{}{userID}
+
+	if status != nil && *status != "" {
+		query += fmt.Sprintf(" AND gp.status = $%d", len(args)+1)
+		args = append(args, *status)
+	}
+
+	query += " ORDER BY gp.due_date ASC, gp.created_at DESC"
+
+	return r.queryPledges(query, args...)
+}
+
+func (r *PledgeRepository) FindByID(id, userID string) (*models.GoldPledge, error) {
+	query := `
+		SELECT ` + pledgeColumns + `
+		FROM gold_pledges gp
+		LEFT JOIN pockets p ON p.id = gp.pocket_id
+		WHERE gp.id = $1 AND gp.user_id = $2
+	`
+
+	pledge, err := scanPledge(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("pledge not found")
+	}
+
+	return pledge, err
+}
+
+// FindActiveByUser returns the user's pledges that have not been redeemed
+func (r *PledgeRepository) FindActiveByUser(userID string) ([]models.GoldPledge, error) {
+	status := models.PledgeStatusActive
+	return r.FindAll(userID, &status)
+}
+
+// FindDueForReminder returns active pledges due on or before the given date that have
+// not been reminded, of users who have not deleted their account
+func (r *PledgeRepository) FindDueForReminder(date time.Time) ([]models.GoldPledge, error) {
+	query := `
+		SELECT ` + pledgeColumns + `
+		FROM gold_pledges gp
+		INNER JOIN users u ON u.id = gp.user_id
+		LEFT JOIN pockets p ON p.id = gp.pocket_id
+		WHERE gp.status = 'active' AND gp.due_date <= $1 AND gp.reminded_at IS NULL
+			AND u.deleted_at IS NULL
+		ORDER BY gp.due_date ASC
+	`
+	return r.queryPledges(query, date)
+}
+
+func (r *PledgeRepository) Update(pledge *models.GoldPledge) error {
+	query := `
+		UPDATE gold_pledges
+		SET lender = $1, loan_principal = $2, fee_rate = $3, fee_period_days = $4, due_date = $5,
+			status = $6, redeemed_at = $7, redemption_amount = $8, notes = $9, reminded_at = $10,
+			updated_at = $11
+		WHERE id = $12 AND user_id = $13
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		pledge.Lender,
+		pledge.LoanPrincipal,
+		pledge.FeeRate,
+		pledge.FeePeriodDays,
+		pledge.DueDate,
+		pledge.Status,
+		pledge.RedeemedAt,
+		pledge.RedemptionAmount,
+		pledge.Notes,
+		pledge.RemindedAt,
+		time.Now(),
+		pledge.ID,
+		pledge.UserID,
+	).Scan(&pledge.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return errors.New("pledge not found")
+	}
+
+	return err
+}
+
+func (r *PledgeRepository) MarkReminded(id string) error {
+	_, err := r.db.Exec(`UPDATE gold_pledges SET reminded_at = $1 WHERE id = $2`, time.Now(), id)
+	return err
+}
+
+func (r *PledgeRepository) Delete(id, userID string) error {
+	query := `DELETE FROM gold_pledges WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("pledge not found")
+	}
+
+	return nil
+}
+
+// GetPledgedWeight sums the weight of active pledges on a pocket, optionally limited to one
+// transaction and ignoring one pledge (the one being edited)
+func (r *PledgeRepository) GetPledgedWeight(pocketID string, transactionID *string, excludeID string) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(weight), 0)
+		FROM gold_pledges
+		WHERE pocket_id = $1 AND status = 'active' AND id::text != $2
+	`
+	args := []interface{}{pocketID, excludeID}
+
+	if transactionID != nil && *transactionID != "" {
+		query += " AND transaction_id = $3"
+		args = append(args, *transactionID)
+	}
+
+	var weight decimal.Decimal
+	err := r.db.QueryRow(query, args...).Scan(&weight)
+	return weight, err
+}
+
+func (r *PledgeRepository) queryPledges(query string, args ...interface{}) ([]models.GoldPledge, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pledges := []models.GoldPledge{}
+	for rows.Next() {
+		pledge, err := scanPledge(rows)
+		if err != nil {
+			return nil, err
+		}
+		pledges = append(pledges, *pledge)
+	}
+
+	return pledges, rows.Err()
+}
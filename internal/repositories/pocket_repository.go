@@ -21,9 +21,9 @@ func NewPocketRepository(db *sql.DB) *PocketRepository {
 
 func (r *PocketRepository) Create(pocket *models.Pocket) error {
 	query := `
-		INSERT INTO pockets (id, user_id, type_pocket_id, name, description, target_weight, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, aggregate_total_price, aggregate_total_weight, created_at, updated_at
+		INSERT INTO pockets (id, user_id, type_pocket_id, name, description, target_weight, target_amount, target_date, zakat_exempt, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, aggregate_total_price, aggregate_total_cost, aggregate_total_weight, aggregate_fine_weight, created_at, updated_at
 	`
 
 	pocket.ID = uuid.New().String()
@@ -37,12 +37,17 @@ func (r *PocketRepository) Create(pocket *models.Pocket) error {
 		pocket.Name,
 		pocket.Description,
 		pocket.TargetWeight,
+		pocket.TargetAmount,
+		pocket.TargetDate,
+		pocket.ZakatExempt,
 		now,
 		now,
 	).Scan(
 		&pocket.ID,
 		&pocket.AggregateTotalPrice,
+		&pocket.AggregateTotalCost,
 		&pocket.AggregateTotalWeight,
+		&pocket.AggregateFineWeight,
 		&pocket.CreatedAt,
 		&pocket.UpdatedAt,
 	)
@@ -54,8 +59,8 @@ func (r *PocketRepository) GetTopPockets(userID string) ([]models.Pocket, error)
 	query := `
 		SELECT 
 			p.id, p.user_id, p.type_pocket_id, p.name, p.description,
-			p.aggregate_total_price, p.aggregate_total_weight, p.target_weight,
-			p.created_at, p.updated_at,
+			p.aggregate_total_price, p.aggregate_total_cost, p.aggregate_total_weight, p.aggregate_fine_weight, p.target_weight,
+			p.target_amount, p.target_date, p.zakat_exempt, p.created_at, p.updated_at,
 			tp.id, tp.name, tp.icon, tp.color
 		FROM pockets p
 		LEFT JOIN type_pockets tp ON tp.id = p.type_pocket_id
@@ -82,8 +87,13 @@ func (r *PocketRepository) GetTopPockets(userID string) ([]models.Pocket, error)
 			&p.Name,
 			&p.Description,
 			&p.AggregateTotalPrice,
+			&p.AggregateTotalCost,
 			&p.AggregateTotalWeight,
+			&p.AggregateFineWeight,
 			&p.TargetWeight,
+			&p.TargetAmount,
+			&p.TargetDate,
+			&p.ZakatExempt,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 			&tp.ID,
@@ -124,8 +134,8 @@ func (r *PocketRepository) FindAll(userID string, typePocketID *string, page, li
 	query := `
 		SELECT 
 			p.id, p.user_id, p.type_pocket_id, p.name, p.description,
-			p.aggregate_total_price, p.aggregate_total_weight, p.target_weight,
-			p.created_at, p.updated_at,
+			p.aggregate_total_price, p.aggregate_total_cost, p.aggregate_total_weight, p.aggregate_fine_weight, p.target_weight,
+			p.target_amount, p.target_date, p.zakat_exempt, p.created_at, p.updated_at,
 			tp.id, tp.name, tp.icon, tp.color
 		FROM pockets p
 		LEFT JOIN type_pockets tp ON tp.id = p.type_pocket_id
@@ -177,8 +187,13 @@ func (r *PocketRepository) FindAll(userID string, typePocketID *string, page, li
 			&p.Name,
 			&p.Description,
 			&p.AggregateTotalPrice,
+			&p.AggregateTotalCost,
 			&p.AggregateTotalWeight,
+			&p.AggregateFineWeight,
 			&p.TargetWeight,
+			&p.TargetAmount,
+			&p.TargetDate,
+			&p.ZakatExempt,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 			&tp.ID,
@@ -201,8 +216,8 @@ func (r *PocketRepository) FindByID(id, userID string) (*models.Pocket, error) {
 	query := `
 		SELECT 
 			p.id, p.user_id, p.type_pocket_id, p.name, p.description,
-			p.aggregate_total_price, p.aggregate_total_weight, p.target_weight,
-			p.created_at, p.updated_at,
+			p.aggregate_total_price, p.aggregate_total_cost, p.aggregate_total_weight, p.aggregate_fine_weight, p.target_weight,
+			p.target_amount, p.target_date, p.zakat_exempt, p.created_at, p.updated_at,
 			tp.id, tp.name, tp.description, tp.icon, tp.color,
 			(SELECT COUNT(*) FROM transactions WHERE pocket_id = p.id) as transaction_count
 		FROM pockets p
@@ -221,8 +236,13 @@ func (r *PocketRepository) FindByID(id, userID string) (*models.Pocket, error) {
 		&p.Name,
 		&p.Description,
 		&p.AggregateTotalPrice,
+		&p.AggregateTotalCost,
 		&p.AggregateTotalWeight,
+		&p.AggregateFineWeight,
 		&p.TargetWeight,
+		&p.TargetAmount,
+		&p.TargetDate,
+		&p.ZakatExempt,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 		&tp.ID,
@@ -250,8 +270,9 @@ func (r *PocketRepository) FindByID(id, userID string) (*models.Pocket, error) {
 func (r *PocketRepository) Update(pocket *models.Pocket) error {
 	query := `
 		UPDATE pockets
-		SET name = $1, description = $2, target_weight = $3, updated_at = $4, type_pocket_id = $5
-		WHERE id = $6 AND user_id = $7
+		SET name = $1, description = $2, target_weight = $3, target_amount = $4, target_date = $5,
+			zakat_exempt = $6, updated_at = $7, type_pocket_id = $8
+		WHERE id = $9 AND user_id = $10
 		RETURNING updated_at
 	`
 
@@ -260,6 +281,9 @@ func (r *PocketRepository) Update(pocket *models.Pocket) error {
 		pocket.Name,
 		pocket.Description,
 		pocket.TargetWeight,
+		pocket.TargetAmount,
+		pocket.TargetDate,
+		pocket.ZakatExempt,
 		time.Now(),
 		pocket.TypePocketID,
 		pocket.ID,
@@ -297,13 +321,16 @@ func (r *PocketRepository) GetStats(pocketID, userID string) (*models.PocketStat
 	query := `
 		SELECT 
 			p.aggregate_total_weight,
+			p.aggregate_fine_weight,
 			p.aggregate_total_price,
+			p.aggregate_total_cost,
 			CASE 
 				WHEN p.aggregate_total_weight > 0 
-				THEN p.aggregate_total_price / p.aggregate_total_weight 
+				THEN p.aggregate_total_cost / p.aggregate_total_weight 
 				ELSE 0 
 			END as average_price_per_gram,
-			(SELECT COUNT(*) FROM transactions WHERE pocket_id = p.id) as transaction_count
+			(SELECT COUNT(*) FROM transactions WHERE pocket_id = p.id) as transaction_count,
+			(SELECT COALESCE(SUM(weight), 0) FROM gold_pledges WHERE pocket_id = p.id AND status = 'active') as pledged_weight
 		FROM pockets p
 		WHERE p.id = $1 AND p.user_id = $2
 	`
@@ -311,15 +338,21 @@ func (r *PocketRepository) GetStats(pocketID, userID string) (*models.PocketStat
 	stats := &models.PocketStats{}
 	err := r.db.QueryRow(query, pocketID, userID).Scan(
 		&stats.TotalWeight,
+		&stats.FineWeight,
 		&stats.TotalValue,
+		&stats.TotalCost,
 		&stats.AveragePricePerGram,
 		&stats.TransactionCount,
+		&stats.PledgedWeight,
 	)
 
 	if err == sql.ErrNoRows {
 		return nil, errors.New("pocket not found")
 	}
 
+	// Pledged gold is still owned but cannot be sold or withdrawn until redeemed
+	stats.AvailableWeight = stats.TotalWeight.Sub(stats.PledgedWeight)
+
 	return stats, err
 }
 
@@ -339,3 +372,127 @@ func (r *PocketRepository) NameExistsForUser(userID, name, excludeID string) (bo
 	err := r.db.QueryRow(query, args...).Scan(&exists)
 	return exists, err
 }
+
+// FindAllByUser returns every pocket of a user without pagination
+func (r *PocketRepository) FindAllByUser(userID string) ([]models.Pocket, error) {
+	query := `
+		SELECT 
+			p.id, p.user_id, p.type_pocket_id, p.name, p.description,
+			p.aggregate_total_price, p.aggregate_total_cost, p.aggregate_total_weight, p.aggregate_fine_weight, p.target_weight,
+			p.target_amount, p.target_date, p.zakat_exempt, p.created_at, p.updated_at,
+			tp.id, tp.name, tp.icon, tp.color
+		FROM pockets p
+		LEFT JOIN type_pockets tp ON tp.id = p.type_pocket_id
+		WHERE p.user_id = $1
+		ORDER BY p.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pockets []models.Pocket
+	for rows.Next() {
+		var p models.Pocket
+		var tp models.TypePocket
+
+		err := rows.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.TypePocketID,
+			&p.Name,
+			&p.Description,
+			&p.AggregateTotalPrice,
+			&p.AggregateTotalCost,
+			&p.AggregateTotalWeight,
+			&p.AggregateFineWeight,
+			&p.TargetWeight,
+			&p.TargetAmount,
+			&p.TargetDate,
+			&p.ZakatExempt,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&tp.ID,
+			&tp.Name,
+			&tp.Icon,
+			&tp.Color,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		p.TypePocket = &tp
+		pockets = append(pockets, p)
+	}
+
+	return pockets, rows.Err()
+}
+
+// FindWithGoals returns the user's pockets that have a weight or amount target set
+func (r *PocketRepository) FindWithGoals(userID string) ([]models.Pocket, error) {
+	query := `
+		SELECT 
+			p.id, p.user_id, p.type_pocket_id, p.name, p.description,
+			p.aggregate_total_price, p.aggregate_total_cost, p.aggregate_total_weight, p.aggregate_fine_weight, p.target_weight,
+			p.target_amount, p.target_date, p.zakat_exempt, p.created_at, p.updated_at
+		FROM pockets p
+		WHERE p.user_id = $1 AND (p.target_weight IS NOT NULL OR p.target_amount IS NOT NULL)
+		ORDER BY p.target_date ASC NULLS LAST, p.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pockets []models.Pocket
+	for rows.Next() {
+		var p models.Pocket
+
+		err := rows.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.TypePocketID,
+			&p.Name,
+			&p.Description,
+			&p.AggregateTotalPrice,
+			&p.AggregateTotalCost,
+			&p.AggregateTotalWeight,
+			&p.AggregateFineWeight,
+			&p.TargetWeight,
+			&p.TargetAmount,
+			&p.TargetDate,
+			&p.ZakatExempt,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		pockets = append(pockets, p)
+	}
+
+	return pockets, rows.Err()
+}
+
+// SetGoalReached records whether the pocket's savings goal is met. It reports whether
+// that changed, so a reached goal is announced only once.
+func (r *PocketRepository) SetGoalReached(id string, reached bool) (bool, error) {
+	query := `
+		UPDATE pockets
+		SET goal_reached_at = CASE WHEN $2 THEN $3::timestamp ELSE NULL END
+		WHERE id = $1 AND (goal_reached_at IS NULL) = $2
+	`
+
+	result, err := r.db.Exec(query, id, reached, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
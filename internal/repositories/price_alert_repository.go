@@ -0,0 +1,325 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+)
+
+type PriceAlertRepository struct {
+	db *sql.DB
+}
+
+func NewPriceAlertRepository(db *sql.DB) *PriceAlertRepository {
+	return &PriceAlertRepository{db: db}
+}
+
+const priceAlertColumns = `
+	pa.id, pa.user_id, pa.alert_type, pa.price_source, pa.brand, pa.brand_id, pa.threshold,
+	pa.notify_email, pa.notify_push, pa.notify_in_app, pa.cooldown_hours, pa.active,
+	pa.last_triggered_at, pa.last_price_date, pa.created_at, pa.updated_at
+`
+
+func scanPriceAlert(row interface{ Scan(...interface{}) error }) (*models.PriceAlert, error) {
+	alert := &models.PriceAlert{}
+	err := row.Scan(
+		&alert.ID,
+		&alert.UserID,
+		&alert.AlertType,
+		&alert.PriceSource,
+		&alert.Brand,
+		&alert.BrandID,
+		&alert.Threshold,
+		&alert.NotifyEmail,
+		&alert.NotifyPush,
+		&alert.NotifyInApp,
+		&alert.CooldownHours,
+		&alert.Active,
+		&alert.LastTriggeredAt,
+		&alert.LastPriceDate,
+		&alert.CreatedAt,
+		&alert.UpdatedAt,
+	)
+	return alert, err
+}
+
+func (r *PriceAlertRepository) Create(alert *models.PriceAlert) error {
+	query := `
+		INSERT INTO price_alerts (
+			id, user_id, alert_type, price_source, brand, brand_id, threshold,
+			notify_email, notify_push, notify_in_app, cooldown_hours, active, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at, updated_at
+	`
+
+	alert.ID = uuid.New().String()
+	now := time.Now()
+
+	return r.db.QueryRow(
+		query,
+		alert.ID,
+		alert.UserID,
+		alert.AlertType,
+		alert.PriceSource,
+		alert.Brand,
+		alert.BrandID,
+		alert.Threshold,
+		alert.NotifyEmail,
+		alert.NotifyPush,
+		alert.NotifyInApp,
+		alert.CooldownHours,
+		alert.Active,
+		now,
+		now,
+	).Scan(&alert.ID, &alert.CreatedAt, &alert.UpdatedAt)
+}
+
+func (r *PriceAlertRepository) FindAll(userID string) ([]models.PriceAlert, error) {
+	query := `
+		SELECT ` + priceAlertColumns + `
+		FROM price_alerts pa
+		WHERE pa.user_id = $1
+		ORDER BY pa.created_at DESC
+	`
+	return r.queryAlerts(query, userID)
+}
+
+func (r *PriceAlertRepository) FindByID(id, userID string) (*models.PriceAlert, error) {
+	query := `
+		SELECT ` + priceAlertColumns + `
+		FROM price_alerts pa
+		WHERE pa.id = $1 AND pa.user_id = $2
+	`
+
+	alert, err := scanPriceAlert(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("alert not found")
+	}
+
+	return alert, err
+}
+
+// FindActiveForSource returns the active alerts watching prices of the source, of
+// users who have not turned price alerts off in their settings
+func (r *PriceAlertRepository) FindActiveForSource(source string) ([]models.PriceAlert, error) {
+	query := `
+		SELECT ` + priceAlertColumns + `
+		FROM price_alerts pa
+		INNER JOIN users u ON u.id = pa.user_id
+		LEFT JOIN user_settings us ON us.user_id = pa.user_id
+		WHERE pa.active = TRUE
+			AND (pa.price_source IS NULL OR pa.price_source = $1)
+			AND u.deleted_at IS NULL
+			AND COALESCE(us.price_alerts, TRUE) = TRUE
+		ORDER BY pa.created_at ASC
+	`
+	return r.queryAlerts(query, source)
+}
+
+func (r *PriceAlertRepository) queryAlerts(query string, args ...interface{}) ([]models.PriceAlert, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []models.PriceAlert{}
+	for rows.Next() {
+		alert, err := scanPriceAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+func (r *PriceAlertRepository) Update(alert *models.PriceAlert) error {
+	query := `
+		UPDATE price_alerts
+		SET price_source = $1, brand = $2, brand_id = $3, threshold = $4, notify_email = $5,
+		    notify_push = $6, notify_in_app = $7, cooldown_hours = $8, active = $9, updated_at = $10
+		WHERE id = $11 AND user_id = $12
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		alert.PriceSource,
+		alert.Brand,
+		alert.BrandID,
+		alert.Threshold,
+		alert.NotifyEmail,
+		alert.NotifyPush,
+		alert.NotifyInApp,
+		alert.CooldownHours,
+		alert.Active,
+		time.Now(),
+		alert.ID,
+		alert.UserID,
+	).Scan(&alert.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return errors.New("alert not found")
+	}
+
+	return err
+}
+
+func (r *PriceAlertRepository) Delete(id, userID string) error {
+	result, err := r.db.Exec(`DELETE FROM price_alerts WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("alert not found")
+	}
+
+	return nil
+}
+
+// RecordTrigger stores the event of an alert firing and starts its cooldown, before
+// the event is delivered. It returns false when the alert has already fired for the
+// price date, e.g. on another API instance, so each event is delivered at most once.
+func (r *PriceAlertRepository) RecordTrigger(event *models.PriceAlertEvent) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	event.ID = uuid.New().String()
+	event.CreatedAt = time.Now()
+
+	result, err := tx.Exec(`
+		UPDATE price_alerts SET last_triggered_at = $1, last_price_date = $2
+		WHERE id = $3 AND (last_price_date IS NULL OR last_price_date < $2)
+	`, event.CreatedAt, event.PriceDate, event.AlertID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	query := `
+		INSERT INTO price_alert_events (
+			id, alert_id, user_id, alert_type, price_date, price_source, price_per_gram, reference_value,
+			change_percentage, message, delivered_email, delivered_push, in_app, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err = tx.Exec(
+		query,
+		event.ID,
+		event.AlertID,
+		event.UserID,
+		event.AlertType,
+		event.PriceDate,
+		event.PriceSource,
+		event.PricePerGram,
+		event.ReferenceValue,
+		event.ChangePercentage,
+		event.Message,
+		event.DeliveredEmail,
+		event.DeliveredPush,
+		event.InApp,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RecordDelivery stores the channels a recorded event was delivered through
+func (r *PriceAlertRepository) RecordDelivery(event *models.PriceAlertEvent) error {
+	_, err := r.db.Exec(
+		`UPDATE price_alert_events SET delivered_email = $1, delivered_push = $2, in_app = $3 WHERE id = $4`,
+		event.DeliveredEmail, event.DeliveredPush, event.InApp, event.ID,
+	)
+	return err
+}
+
+// FindEvents returns the in-app events of a user, newest first
+func (r *PriceAlertRepository) FindEvents(userID string, page, limit int) ([]models.PriceAlertEvent, int, error) {
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM price_alert_events WHERE user_id = $1 AND in_app = TRUE`, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, alert_id, user_id, alert_type, price_date, price_source, price_per_gram, reference_value,
+			change_percentage, message, delivered_email, delivered_push, in_app, created_at
+		FROM price_alert_events
+		WHERE user_id = $1 AND in_app = TRUE
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []models.PriceAlertEvent{}
+	for rows.Next() {
+		var event models.PriceAlertEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.AlertID,
+			&event.UserID,
+			&event.AlertType,
+			&event.PriceDate,
+			&event.PriceSource,
+			&event.PricePerGram,
+			&event.ReferenceValue,
+			&event.ChangePercentage,
+			&event.Message,
+			&event.DeliveredEmail,
+			&event.DeliveredPush,
+			&event.InApp,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
+
+// GetAverageCost returns the user's cost per fine gram including fees, of one brand
+// when brandID is set. It is nil without holdings.
+func (r *PriceAlertRepository) GetAverageCost(userID string, brandID *string) (*decimal.Decimal, error) {
+	query := `
+		SELECT ROUND(SUM(total_cost) / NULLIF(SUM(fine_weight), 0), 2)
+		FROM transactions
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR brand_id = $2::uuid)
+	`
+
+	var average *decimal.Decimal
+	err := r.db.QueryRow(query, userID, brandID).Scan(&average)
+	return average, err
+}
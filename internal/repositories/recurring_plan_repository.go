@@ -0,0 +1,374 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+)
+
+type RecurringPlanRepository struct {
+	db *sql.DB
+}
+
+func NewRecurringPlanRepository(db *sql.DB) *RecurringPlanRepository {
+	return &RecurringPlanRepository{db: db}
+}
+
+const recurringPlanColumns = `
+	rp.id, rp.user_id, rp.pocket_id, rp.brand, rp.brand_id, rp.frequency, rp.amount_type, rp.amount,
+	rp.price_source, rp.start_date, rp.end_date, rp.next_due_date, rp.auto_post, rp.active,
+	rp.created_at, rp.updated_at, p.id, p.name
+`
+
+func scanRecurringPlan(row interface{ Scan(...interface{}) error }) (*models.RecurringPlan, error) {
+	plan := &models.RecurringPlan{}
+	pocket := &models.Pocket{}
+	err := row.Scan(
+		&plan.ID,
+		&plan.UserID,
+		&plan.PocketID,
+		&plan.Brand,
+		&plan.BrandID,
+		&plan.Frequency,
+		&plan.AmountType,
+		&plan.Amount,
+		&plan.PriceSource,
+		&plan.StartDate,
+		&plan.EndDate,
+		&plan.NextDueDate,
+		&plan.AutoPost,
+		&plan.Active,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+		&pocket.ID,
+		&pocket.Name,
+	)
+	plan.Pocket = pocket
+	return plan, err
+}
+
+func (r *RecurringPlanRepository) Create(plan *models.RecurringPlan) error {
+	query := `
+		INSERT INTO recurring_plans (
+			id, user_id, pocket_id, brand, brand_id, frequency, amount_type, amount, price_source,
+			start_date, end_date, next_due_date, auto_post, active, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at, updated_at
+	`
+
+	plan.ID = uuid.New().String()
+	now := time.Now()
+
+	return r.db.QueryRow(
+		query,
+		plan.ID,
+		plan.UserID,
+		plan.PocketID,
+		plan.Brand,
+		plan.BrandID,
+		plan.Frequency,
+		plan.AmountType,
+		plan.Amount,
+		plan.PriceSource,
+		plan.StartDate,
+		plan.EndDate,
+		plan.NextDueDate,
+		plan.AutoPost,
+		plan.Active,
+		now,
+		now,
+	).Scan(&plan.ID, &plan.CreatedAt, &plan.UpdatedAt)
+}
+
+func (r *RecurringPlanRepository) FindAll(userID string) ([]models.RecurringPlan, error) {
+	query := `
+		SELECT ` + recurringPlanColumns + `
+		FROM recurring_plans rp
+		LEFT JOIN pockets p ON p.id = rp.pocket_id
+		WHERE rp.user_id = $1
+		ORDER BY rp.created_at DESC
+	`
+	return r.queryPlans(query, userID)
+}
+
+func (r *RecurringPlanRepository) FindByID(id, userID string) (*models.RecurringPlan, error) {
+	query := `
+		SELECT ` + recurringPlanColumns + `
+		FROM recurring_plans rp
+		LEFT JOIN pockets p ON p.id = rp.pocket_id
+		WHERE rp.id = $1 AND rp.user_id = $2
+	`
+
+	plan, err := scanRecurringPlan(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("plan not found")
+	}
+
+	return plan, err
+}
+
+// FindDue returns active plans with a due date on or before the given date, of users
+// who have not deleted their account
+func (r *RecurringPlanRepository) FindDue(date time.Time) ([]models.RecurringPlan, error) {
+	query := `
+		SELECT ` + recurringPlanColumns + `
+		FROM recurring_plans rp
+		INNER JOIN users u ON u.id = rp.user_id
+		LEFT JOIN pockets p ON p.id = rp.pocket_id
+		WHERE rp.active = TRUE AND rp.next_due_date IS NOT NULL AND rp.next_due_date <= $1
+			AND u.deleted_at IS NULL
+		ORDER BY rp.next_due_date ASC
+	`
+	return r.queryPlans(query, date)
+}
+
+func (r *RecurringPlanRepository) Update(plan *models.RecurringPlan) error {
+	query := `
+		UPDATE recurring_plans
+		SET brand = $1, brand_id = $2, amount_type = $3, amount = $4, price_source = $5, end_date = $6,
+		    next_due_date = $7, auto_post = $8, active = $9, updated_at = $10
+		WHERE id = $11 AND user_id = $12
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(
+		query,
+		plan.Brand,
+		plan.BrandID,
+		plan.AmountType,
+		plan.Amount,
+		plan.PriceSource,
+		plan.EndDate,
+		plan.NextDueDate,
+		plan.AutoPost,
+		plan.Active,
+		time.Now(),
+		plan.ID,
+		plan.UserID,
+	).Scan(&plan.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return errors.New("plan not found")
+	}
+
+	return err
+}
+
+func (r *RecurringPlanRepository) Delete(id, userID string) error {
+	query := `DELETE FROM recurring_plans WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("plan not found")
+	}
+
+	return nil
+}
+
+func (r *RecurringPlanRepository) queryPlans(query string, args ...interface{}) ([]models.RecurringPlan, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []models.RecurringPlan
+	for rows.Next() {
+		plan, err := scanRecurringPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+	}
+
+	return plans, rows.Err()
+}
+
+const plannedPurchaseColumns = `
+	id, plan_id, user_id, pocket_id, due_date, weight, price_per_gram, total_price,
+	status, transaction_id, reminded_at, created_at, updated_at
+`
+
+func scanPlannedPurchase(row interface{ Scan(...interface{}) error }) (*models.PlannedPurchase, error) {
+	pp := &models.PlannedPurchase{}
+	err := row.Scan(
+		&pp.ID,
+		&pp.PlanID,
+		&pp.UserID,
+		&pp.PocketID,
+		&pp.DueDate,
+		&pp.Weight,
+		&pp.PricePerGram,
+		&pp.TotalPrice,
+		&pp.Status,
+		&pp.TransactionID,
+		&pp.RemindedAt,
+		&pp.CreatedAt,
+		&pp.UpdatedAt,
+	)
+	return pp, err
+}
+
+// CreatePurchase stores a planned purchase. A purchase already generated for the
+// same plan and due date is left untouched, so the scheduler can safely retry.
+func (r *RecurringPlanRepository) CreatePurchase(pp *models.PlannedPurchase) (bool, error) {
+	query := `
+		INSERT INTO planned_purchases (
+			id, plan_id, user_id, pocket_id, due_date, weight, price_per_gram, total_price,
+			status, transaction_id, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (plan_id, due_date) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	pp.ID = uuid.New().String()
+	now := time.Now()
+
+	err := r.db.QueryRow(
+		query,
+		pp.ID,
+		pp.PlanID,
+		pp.UserID,
+		pp.PocketID,
+		pp.DueDate,
+		pp.Weight,
+		pp.PricePerGram,
+		pp.TotalPrice,
+		pp.Status,
+		pp.TransactionID,
+		now,
+		now,
+	).Scan(&pp.ID, &pp.CreatedAt, &pp.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (r *RecurringPlanRepository) FindPurchases(userID string, status *string) ([]models.PlannedPurchase, error) {
+	query := `SELECT ` + plannedPurchaseColumns + ` FROM planned_purchases WHERE user_id = $1`
+	args := []interface{}{userID}
+
+	if status != nil && *status != "" {
+		query += ` AND status = $2`
+		args = append(args, *status)
+	}
+	query += ` ORDER BY due_date DESC`
+
+	return r.queryPurchases(query, args...)
+}
+
+func (r *RecurringPlanRepository) FindPurchaseByID(id, userID string) (*models.PlannedPurchase, error) {
+	query := `SELECT ` + plannedPurchaseColumns + ` FROM planned_purchases WHERE id = $1 AND user_id = $2`
+
+	pp, err := scanPlannedPurchase(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("planned purchase not found")
+	}
+
+	return pp, err
+}
+
+// FindPendingDueBefore returns pending purchases whose due date is before the given
+// date, of users who have not deleted their account
+func (r *RecurringPlanRepository) FindPendingDueBefore(date time.Time) ([]models.PlannedPurchase, error) {
+	query := `
+		SELECT ` + plannedPurchaseColumns + `
+		FROM planned_purchases
+		WHERE status = $1 AND due_date < $2
+			AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)
+	`
+	return r.queryPurchases(query, models.PlannedPurchasePending, date)
+}
+
+func (r *RecurringPlanRepository) UpdatePurchaseStatus(id, status string, transactionID *string) error {
+	query := `
+		UPDATE planned_purchases
+		SET status = $1, transaction_id = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, status, transactionID, time.Now(), id)
+	return err
+}
+
+// ConfirmPurchase stores the transaction recording a pending or missed planned purchase
+// and marks the purchase confirmed in the same database transaction
+func (r *RecurringPlanRepository) ConfirmPurchase(id string, transaction *models.Transaction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(insertTransactionQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	if err := insertTransaction(tx, stmt, transaction, now); err != nil {
+		return err
+	}
+
+	// The status condition keeps a purchase confirmed concurrently from being recorded twice
+	result, err := tx.Exec(`
+		UPDATE planned_purchases
+		SET status = $1, transaction_id = $2, updated_at = $3
+		WHERE id = $4 AND status IN ($5, $6)
+	`, models.PlannedPurchaseConfirmed, transaction.ID, now, id, models.PlannedPurchasePending, models.PlannedPurchaseMissed)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("planned purchase is no longer pending")
+	}
+
+	return tx.Commit()
+}
+
+func (r *RecurringPlanRepository) MarkPurchaseReminded(id string) error {
+	query := `UPDATE planned_purchases SET reminded_at = $1, updated_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, time.Now(), id)
+	return err
+}
+
+func (r *RecurringPlanRepository) queryPurchases(query string, args ...interface{}) ([]models.PlannedPurchase, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purchases []models.PlannedPurchase
+	for rows.Next() {
+		pp, err := scanPlannedPurchase(rows)
+		if err != nil {
+			return nil, err
+		}
+		purchases = append(purchases, *pp)
+	}
+
+	return purchases, rows.Err()
+}
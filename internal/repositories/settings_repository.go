@@ -21,7 +21,7 @@ func (r *SettingsRepository) FindByUserID(userID string) (*models.UserSettings,
 	query := `
 		SELECT id, user_id, language, theme, currency, 
 		       email_notifications, push_notifications, price_alerts,
-		       created_at, updated_at
+		       zakat_nisab_grams, created_at, updated_at
 		FROM user_settings
 		WHERE user_id = $1
 	`
@@ -36,6 +36,7 @@ func (r *SettingsRepository) FindByUserID(userID string) (*models.UserSettings,
 		&settings.EmailNotifications,
 		&settings.PushNotifications,
 		&settings.PriceAlerts,
+		&settings.ZakatNisabGrams,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
@@ -54,7 +55,7 @@ func (r *SettingsRepository) CreateDefault(userID string) (*models.UserSettings,
 		VALUES ($1, $2, $3, $4)
 		RETURNING id, user_id, language, theme, currency,
 		          email_notifications, push_notifications, price_alerts,
-		          created_at, updated_at
+		          zakat_nisab_grams, created_at, updated_at
 	`
 
 	settings := &models.UserSettings{}
@@ -70,6 +71,7 @@ func (r *SettingsRepository) CreateDefault(userID string) (*models.UserSettings,
 		&settings.EmailNotifications,
 		&settings.PushNotifications,
 		&settings.PriceAlerts,
+		&settings.ZakatNisabGrams,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
@@ -80,10 +82,10 @@ func (r *SettingsRepository) CreateDefault(userID string) (*models.UserSettings,
 func (r *SettingsRepository) Update(settings *models.UserSettings) error {
 	query := `
 		UPDATE user_settings
-		SET language = $1, theme = $2, 
-		    email_notifications = $3, push_notifications = $4, price_alerts = $5,
-		    updated_at = $6
-		WHERE user_id = $7
+		SET language = $1, theme = $2, currency = $3,
+		    email_notifications = $4, push_notifications = $5, price_alerts = $6,
+		    zakat_nisab_grams = $7, updated_at = $8
+		WHERE user_id = $9
 		RETURNING updated_at
 	`
 
@@ -91,9 +93,11 @@ func (r *SettingsRepository) Update(settings *models.UserSettings) error {
 		query,
 		settings.Language,
 		settings.Theme,
+		settings.Currency,
 		settings.EmailNotifications,
 		settings.PushNotifications,
 		settings.PriceAlerts,
+		settings.ZakatNisabGrams,
 		time.Now(),
 		settings.UserID,
 	).Scan(&settings.UpdatedAt)
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+)
+
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewSnapshotRepository(db *sql.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// ReplaceForDate recomputes the snapshots of every active user and pocket holding gold
+// on the given day from their transactions up to that day, valued at goldPrice when
+// one is given. It returns the number of snapshots stored.
+func (r *SnapshotRepository) ReplaceForDate(date time.Time, goldPrice *decimal.Decimal) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM portfolio_snapshots WHERE date = $1`, date); err != nil {
+		return 0, err
+	}
+
+	// Pocket rows first, then the portfolio totals of each user
+	stored := 0
+	groupings := []struct{ pocketID, groupBy string }{
+		{pocketID: "t.pocket_id", groupBy: "t.user_id, t.pocket_id"},
+		{pocketID: "NULL::uuid", groupBy: "t.user_id"},
+	}
+	for _, grouping := range groupings {
+		query := `
+			INSERT INTO portfolio_snapshots (user_id, pocket_id, date, weight, fine_weight, total_cost, gold_price, market_value)
+			SELECT t.user_id, ` + grouping.pocketID + `, $1, SUM(t.weight), SUM(t.fine_weight), SUM(t.total_cost),
+				$2::numeric, ROUND(SUM(t.fine_weight) * $2::numeric, 2)
+			FROM transactions t
+			INNER JOIN users u ON u.id = t.user_id
+			WHERE t.transaction_date <= $1 AND u.deleted_at IS NULL
+			GROUP BY ` + grouping.groupBy
+
+		result, err := tx.Exec(query, date, goldPrice)
+		if err != nil {
+			return 0, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		stored += int(rows)
+	}
+
+	return stored, tx.Commit()
+}
+
+// FindLatestDate returns the most recent snapshot day, nil before the first snapshot
+func (r *SnapshotRepository) FindLatestDate() (*time.Time, error) {
+	var date *time.Time
+	err := r.db.QueryRow(`SELECT MAX(date) FROM portfolio_snapshots`).Scan(&date)
+	return date, err
+}
+
+// FindFirstDate returns the user's oldest snapshot day, nil without snapshots
+func (r *SnapshotRepository) FindFirstDate(userID string) (*time.Time, error) {
+	var date *time.Time
+	err := r.db.QueryRow(`SELECT MIN(date) FROM portfolio_snapshots WHERE user_id = $1`, userID).Scan(&date)
+	return date, err
+}
+
+// FindHistory returns the portfolio snapshots of a user, or those of one pocket, oldest
+// first. With an interval of week or month only the last snapshot of each is returned.
+func (r *SnapshotRepository) FindHistory(userID string, pocketID, startDate, endDate *string, interval string) ([]models.ValueSnapshot, error) {
+	query := `
+		SELECT DISTINCT ON (date_trunc($5, date))
+			date, weight, fine_weight, total_cost, gold_price, market_value
+		FROM portfolio_snapshots
+		WHERE user_id = $1
+			AND (($2::uuid IS NULL AND pocket_id IS NULL) OR pocket_id = $2::uuid)
+			AND ($3::date IS NULL OR date >= $3::date)
+			AND ($4::date IS NULL OR date <= $4::date)
+		ORDER BY date_trunc($5, date), date DESC
+	`
+
+	rows, err := r.db.Query(query, userID, pocketID, startDate, endDate, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []models.ValueSnapshot{}
+	for rows.Next() {
+		var snapshot models.ValueSnapshot
+		err := rows.Scan(
+			&snapshot.Date,
+			&snapshot.Weight,
+			&snapshot.FineWeight,
+			&snapshot.TotalCost,
+			&snapshot.GoldPrice,
+			&snapshot.MarketValue,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"nabung-emas-api/internal/models"
+)
+
+type StreamEventRepository struct {
+	db *sql.DB
+}
+
+func NewStreamEventRepository(db *sql.DB) *StreamEventRepository {
+	return &StreamEventRepository{db: db}
+}
+
+const streamEventColumns = `id, user_id, type, data, created_at`
+
+func scanStreamEvent(row interface{ Scan(...interface{}) error }) (*models.StreamEvent, error) {
+	event := &models.StreamEvent{}
+	var data []byte
+	err := row.Scan(
+		&event.ID,
+		&event.UserID,
+		&event.Type,
+		&data,
+		&event.CreatedAt,
+	)
+	event.Data = data
+	return event, err
+}
+
+// Create stores the event, which announces it to every API instance
+func (r *StreamEventRepository) Create(event *models.StreamEvent) error {
+	query := `
+		INSERT INTO stream_events (user_id, type, data, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	event.CreatedAt = time.Now()
+	return r.db.QueryRow(query, event.UserID, event.Type, []byte(event.Data), event.CreatedAt).Scan(&event.ID)
+}
+
+func (r *StreamEventRepository) FindByID(id int64) (*models.StreamEvent, error) {
+	query := `SELECT ` + streamEventColumns + ` FROM stream_events WHERE id = $1`
+
+	event, err := scanStreamEvent(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("stream event not found")
+	}
+
+	return event, err
+}
+
+// FindAfter returns up to limit events of every user stored after the event afterID,
+// oldest first
+func (r *StreamEventRepository) FindAfter(afterID int64, limit int) ([]models.StreamEvent, error) {
+	query := `
+		SELECT ` + streamEventColumns + `
+		FROM stream_events
+		WHERE id > $1
+		ORDER BY id ASC
+		LIMIT $2
+	`
+
+	return r.findAll(query, afterID, limit)
+}
+
+// FindForUserAfter returns up to limit events of the user, or of everyone, stored after
+// the event afterID, oldest first
+func (r *StreamEventRepository) FindForUserAfter(userID string, afterID int64, limit int) ([]models.StreamEvent, error) {
+	query := `
+		SELECT ` + streamEventColumns + `
+		FROM stream_events
+		WHERE id > $2 AND (user_id = $1 OR user_id IS NULL)
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	return r.findAll(query, userID, afterID, limit)
+}
+
+// FindLastID returns the id of the newest event, 0 when there is none
+func (r *StreamEventRepository) FindLastID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM stream_events`).Scan(&id)
+	return id, err
+}
+
+// FindFirstID returns the id of the oldest event still kept, nil when there is none
+func (r *StreamEventRepository) FindFirstID() (*int64, error) {
+	var id sql.NullInt64
+	if err := r.db.QueryRow(`SELECT MIN(id) FROM stream_events`).Scan(&id); err != nil {
+		return nil, err
+	}
+	if !id.Valid {
+		return nil, nil
+	}
+
+	return &id.Int64, nil
+}
+
+// DeleteBefore removes the events stored before the given time
+func (r *StreamEventRepository) DeleteBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM stream_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *StreamEventRepository) findAll(query string, args ...interface{}) ([]models.StreamEvent, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.StreamEvent{}
+	for rows.Next() {
+		event, err := scanStreamEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, rows.Err()
+}
@@ -18,65 +18,102 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
+const insertTransactionQuery = `
+	INSERT INTO transactions (
+		id, user_id, pocket_id, transaction_date, brand, brand_id, weight, purity,
+		price_per_gram, total_price, total_fees, description, receipt_image, created_at, updated_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	RETURNING id, purity, fine_weight, total_cost, created_at, updated_at
+`
+
+// Create stores the transaction together with its fee lines
 func (r *TransactionRepository) Create(transaction *models.Transaction) error {
-	query := `
-		INSERT INTO transactions (
-			id, user_id, pocket_id, transaction_date, brand, weight, 
-			price_per_gram, total_price, description, receipt_image, created_at, updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id, created_at, updated_at
-	`
+	return r.CreateBatch([]*models.Transaction{transaction})
+}
+
+// CreateBatch inserts all transactions in a single database transaction.
+// Either every transaction is stored or none is.
+func (r *TransactionRepository) CreateBatch(transactions []*models.Transaction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(insertTransactionQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
 
-	transaction.ID = uuid.New().String()
 	now := time.Now()
+	for _, transaction := range transactions {
+		if err := insertTransaction(tx, stmt, transaction, now); err != nil {
+			return err
+		}
+	}
 
-	err := r.db.QueryRow(
-		query,
+	return tx.Commit()
+}
+
+// insertTransaction stores the transaction and its fee lines within tx, through stmt
+// prepared from insertTransactionQuery
+func insertTransaction(tx *sql.Tx, stmt *sql.Stmt, transaction *models.Transaction, now time.Time) error {
+	transaction.ID = uuid.New().String()
+
+	err := stmt.QueryRow(
 		transaction.ID,
 		transaction.UserID,
 		transaction.PocketID,
 		transaction.TransactionDate,
 		transaction.Brand,
+		transaction.BrandID,
 		transaction.Weight,
+		transaction.Purity,
 		transaction.PricePerGram,
 		transaction.TotalPrice,
+		transaction.TotalFees,
 		transaction.Description,
 		transaction.ReceiptImage,
 		now,
 		now,
-	).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt)
+	).Scan(&transaction.ID, &transaction.Purity, &transaction.FineWeight, &transaction.TotalCost, &transaction.CreatedAt, &transaction.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return insertTransactionFees(tx, transaction)
+}
+
+// insertTransactionFees stores the fee lines of a transaction. The caller keeps
+// transactions.total_fees equal to their sum.
+func insertTransactionFees(tx *sql.Tx, transaction *models.Transaction) error {
+	query := `
+		INSERT INTO transaction_fees (id, transaction_id, fee_type, amount, description, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
 
-	return err
+	now := time.Now()
+	for i := range transaction.Fees {
+		fee := &transaction.Fees[i]
+		fee.ID = uuid.New().String()
+		fee.TransactionID = transaction.ID
+		fee.CreatedAt = now
+
+		if _, err := tx.Exec(query, fee.ID, fee.TransactionID, fee.FeeType, fee.Amount, fee.Description, fee.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (r *TransactionRepository) FindAll(userID string, pocketID, brand, startDate, endDate *string, page, limit int, sortBy, sortOrder string) ([]models.Transaction, int, error) {
 	// Build count query
 	countQuery := `SELECT COUNT(*) FROM transactions WHERE user_id = $1`
-	args := []interface{}{userID}
-	argCount := 1
-
-	whereClause := ""
-	if pocketID != nil && *pocketID != "" {
-		argCount++
-		whereClause += fmt.Sprintf(" AND pocket_id = $%d", argCount)
-		args = append(args, *pocketID)
-	}
-	if brand != nil && *brand != "" {
-		argCount++
-		whereClause += fmt.Sprintf(" AND brand = $%d", argCount)
-		args = append(args, *brand)
-	}
-	if startDate != nil && *startDate != "" {
-		argCount++
-		whereClause += fmt.Sprintf(" AND transaction_date >= $%d", argCount)
-		args = append(args, *startDate)
-	}
-	if endDate != nil && *endDate != "" {
-		argCount++
-		whereClause += fmt.Sprintf(" AND transaction_date <= $%d", argCount)
-		args = append(args, *endDate)
-	}
+	whereClause, args := transactionFilterClause(userID, pocketID, brand, startDate, endDate)
+	argCount := len(args)
 
 	countQuery += whereClause
 
@@ -89,8 +126,8 @@ func (r *TransactionRepository) FindAll(userID string, pocketID, brand, startDat
 	// Build main query
 	query := `
 		SELECT 
-			t.id, t.user_id, t.pocket_id, t.transaction_date, t.brand,
-			t.weight, t.price_per_gram, t.total_price, t.description,
+			t.id, t.user_id, t.pocket_id, t.transaction_date, t.brand, t.brand_id,
+			t.weight, t.purity, t.fine_weight, t.price_per_gram, t.total_price, t.total_fees, t.total_cost, t.description,
 			t.receipt_image, t.created_at, t.updated_at,
 			p.id, p.name,
 			tp.name, tp.color
@@ -138,9 +175,14 @@ func (r *TransactionRepository) FindAll(userID string, pocketID, brand, startDat
 			&t.PocketID,
 			&t.TransactionDate,
 			&t.Brand,
+			&t.BrandID,
 			&t.Weight,
+			&t.Purity,
+			&t.FineWeight,
 			&t.PricePerGram,
 			&t.TotalPrice,
+			&t.TotalFees,
+			&t.TotalCost,
 			&t.Description,
 			&t.ReceiptImage,
 			&t.CreatedAt,
@@ -162,11 +204,104 @@ func (r *TransactionRepository) FindAll(userID string, pocketID, brand, startDat
 	return transactions, total, rows.Err()
 }
 
+// transactionFilterClause builds the optional filters shared by transaction listing and export.
+// The returned args start with userID as $1.
+func transactionFilterClause(userID string, pocketID, brand, startDate, endDate *string) (string, []interface{}) {
+	args := []interface{}{userID}
+	argCount := 1
+
+	whereClause := ""
+	if pocketID != nil && *pocketID != "" {
+		argCount++
+		whereClause += fmt.Sprintf(" AND pocket_id = $%d", argCount)
+		args = append(args, *pocketID)
+	}
+	if brand != nil && *brand != "" {
+		argCount++
+		whereClause += fmt.Sprintf(" AND brand = $%d", argCount)
+		args = append(args, *brand)
+	}
+	if startDate != nil && *startDate != "" {
+		argCount++
+		whereClause += fmt.Sprintf(" AND transaction_date >= $%d", argCount)
+		args = append(args, *startDate)
+	}
+	if endDate != nil && *endDate != "" {
+		argCount++
+		whereClause += fmt.Sprintf(" AND transaction_date <= $%d", argCount)
+		args = append(args, *endDate)
+	}
+
+	return whereClause, args
+}
+
+// Stream calls fn for every transaction matching the filters, ordered by date,
+// without loading the whole result set into memory
+func (r *TransactionRepository) Stream(userID string, pocketID, brand, startDate, endDate *string, fn func(t *models.Transaction) error) error {
+	whereClause, args := transactionFilterClause(userID, pocketID, brand, startDate, endDate)
+
+	query := `
+		SELECT 
+			t.id, t.user_id, t.pocket_id, t.transaction_date, t.brand, t.brand_id,
+			t.weight, t.purity, t.fine_weight, t.price_per_gram, t.total_price, t.total_fees, t.total_cost, t.description,
+			t.receipt_image, t.created_at, t.updated_at,
+			p.id, p.name
+		FROM transactions t
+		LEFT JOIN pockets p ON p.id = t.pocket_id
+		WHERE t.user_id = $1
+	` + whereClause + `
+		ORDER BY t.transaction_date ASC, t.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		var p models.Pocket
+
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.PocketID,
+			&t.TransactionDate,
+			&t.Brand,
+			&t.BrandID,
+			&t.Weight,
+			&t.Purity,
+			&t.FineWeight,
+			&t.PricePerGram,
+			&t.TotalPrice,
+			&t.TotalFees,
+			&t.TotalCost,
+			&t.Description,
+			&t.ReceiptImage,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&p.ID,
+			&p.Name,
+		)
+		if err != nil {
+			return err
+		}
+
+		t.Pocket = &p
+		if err := fn(&t); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *TransactionRepository) FindByID(id, userID string) (*models.Transaction, error) {
 	query := `
 		SELECT 
-			t.id, t.user_id, t.pocket_id, t.transaction_date, t.brand,
-			t.weight, t.price_per_gram, t.total_price, t.description,
+			t.id, t.user_id, t.pocket_id, t.transaction_date, t.brand, t.brand_id,
+			t.weight, t.purity, t.fine_weight, t.price_per_gram, t.total_price, t.total_fees, t.total_cost, t.description,
 			t.receipt_image, t.created_at, t.updated_at,
 			p.id, p.name, p.type_pocket_id,
 			tp.id, tp.name, tp.icon, tp.color
@@ -186,9 +321,14 @@ func (r *TransactionRepository) FindByID(id, userID string) (*models.Transaction
 		&t.PocketID,
 		&t.TransactionDate,
 		&t.Brand,
+		&t.BrandID,
 		&t.Weight,
+		&t.Purity,
+		&t.FineWeight,
 		&t.PricePerGram,
 		&t.TotalPrice,
+		&t.TotalFees,
+		&t.TotalCost,
 		&t.Description,
 		&t.ReceiptImage,
 		&t.CreatedAt,
@@ -213,36 +353,92 @@ func (r *TransactionRepository) FindByID(id, userID string) (*models.Transaction
 	p.TypePocket = tp
 	t.Pocket = p
 
+	t.Fees, err = r.FindFees(t.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return t, nil
 }
 
-func (r *TransactionRepository) Update(transaction *models.Transaction) error {
+// FindFees returns the fee lines of a transaction, largest first
+func (r *TransactionRepository) FindFees(transactionID string) ([]models.TransactionFee, error) {
+	query := `
+		SELECT id, transaction_id, fee_type, amount, description, created_at
+		FROM transaction_fees
+		WHERE transaction_id = $1
+		ORDER BY amount DESC, created_at ASC
+	`
+
+	rows, err := r.db.Query(query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fees := []models.TransactionFee{}
+	for rows.Next() {
+		var fee models.TransactionFee
+		err := rows.Scan(&fee.ID, &fee.TransactionID, &fee.FeeType, &fee.Amount, &fee.Description, &fee.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		fees = append(fees, fee)
+	}
+
+	return fees, rows.Err()
+}
+
+// Update saves the transaction. When replaceFees is set its fee lines are replaced
+// by transaction.Fees in the same database transaction.
+func (r *TransactionRepository) Update(transaction *models.Transaction, replaceFees bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE transactions
-		SET transaction_date = $1, brand = $2, weight = $3, price_per_gram = $4,
-		    total_price = $5, description = $6, updated_at = $7
-		WHERE id = $8 AND user_id = $9
-		RETURNING updated_at
+		SET transaction_date = $1, brand = $2, brand_id = $3, weight = $4, purity = $5, price_per_gram = $6,
+		    total_price = $7, total_fees = $8, description = $9, updated_at = $10
+		WHERE id = $11 AND user_id = $12
+		RETURNING purity, fine_weight, total_cost, updated_at
 	`
 
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		transaction.TransactionDate,
 		transaction.Brand,
+		transaction.BrandID,
 		transaction.Weight,
+		transaction.Purity,
 		transaction.PricePerGram,
 		transaction.TotalPrice,
+		transaction.TotalFees,
 		transaction.Description,
 		time.Now(),
 		transaction.ID,
 		transaction.UserID,
-	).Scan(&transaction.UpdatedAt)
+	).Scan(&transaction.Purity, &transaction.FineWeight, &transaction.TotalCost, &transaction.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return errors.New("transaction not found")
 	}
+	if err != nil {
+		return err
+	}
+
+	if replaceFees {
+		if _, err := tx.Exec(`DELETE FROM transaction_fees WHERE transaction_id = $1`, transaction.ID); err != nil {
+			return err
+		}
+		if err := insertTransactionFees(tx, transaction); err != nil {
+			return err
+		}
+	}
 
-	return err
+	return tx.Commit()
 }
 
 func (r *TransactionRepository) Delete(id, userID string) error {
@@ -292,7 +488,7 @@ func (r *TransactionRepository) UpdateReceiptImage(id, userID, receiptURL string
 func (r *TransactionRepository) GetRecentTransactions(userID string, limit int) ([]models.Transaction, error) {
 	query := `
 		SELECT 
-			t.id, t.pocket_id, t.transaction_date, t.brand, t.weight, t.total_price,
+			t.id, t.pocket_id, t.transaction_date, t.brand, t.brand_id, t.weight, t.purity, t.fine_weight, t.total_price, t.total_fees, t.total_cost,
 			p.id, p.name,
 			tp.color
 		FROM transactions t
@@ -320,8 +516,13 @@ func (r *TransactionRepository) GetRecentTransactions(userID string, limit int)
 			&t.PocketID,
 			&t.TransactionDate,
 			&t.Brand,
+			&t.BrandID,
 			&t.Weight,
+			&t.Purity,
+			&t.FineWeight,
 			&t.TotalPrice,
+			&t.TotalFees,
+			&t.TotalCost,
 			&p.ID,
 			&p.Name,
 			&tp.Color,
@@ -337,3 +538,93 @@ func (r *TransactionRepository) GetRecentTransactions(userID string, limit int)
 
 	return transactions, rows.Err()
 }
+
+// GetReceiptImagesByUser returns all stored receipt references of a user
+func (r *TransactionRepository) GetReceiptImagesByUser(userID string) ([]string, error) {
+	query := `
+		SELECT receipt_image
+		FROM transactions
+		WHERE user_id = $1 AND receipt_image IS NOT NULL AND receipt_image != ''
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []string
+	for rows.Next() {
+		var receipt string
+		if err := rows.Scan(&receipt); err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+// FindAllByUser returns every transaction of a user without pagination, oldest first
+func (r *TransactionRepository) FindAllByUser(userID string) ([]models.Transaction, error) {
+	query := `
+		SELECT 
+			t.id, t.user_id, t.pocket_id, t.transaction_date, t.brand, t.brand_id,
+			t.weight, t.purity, t.fine_weight, t.price_per_gram, t.total_price, t.total_fees, t.total_cost, t.description,
+			t.receipt_image, t.created_at, t.updated_at,
+			p.id, p.name
+		FROM transactions t
+		LEFT JOIN pockets p ON p.id = t.pocket_id
+		WHERE t.user_id = $1
+		ORDER BY t.transaction_date ASC, t.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		var p models.Pocket
+
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.PocketID,
+			&t.TransactionDate,
+			&t.Brand,
+			&t.BrandID,
+			&t.Weight,
+			&t.Purity,
+			&t.FineWeight,
+			&t.PricePerGram,
+			&t.TotalPrice,
+			&t.TotalFees,
+			&t.TotalCost,
+			&t.Description,
+			&t.ReceiptImage,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&p.ID,
+			&p.Name,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Pocket = &p
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
+
+// FindFirstDate returns the date of the user's oldest transaction, nil without transactions
+func (r *TransactionRepository) FindFirstDate(userID string) (*time.Time, error) {
+	var date *time.Time
+	err := r.db.QueryRow(`SELECT MIN(transaction_date) FROM transactions WHERE user_id = $1`, userID).Scan(&date)
+	return date, err
+}
@@ -43,7 +43,7 @@ func (r *UserRepository) Create(user *models.User) error {
 
 func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, full_name, email, phone, password_hash, avatar, created_at, updated_at
+		SELECT id, full_name, email, phone, password_hash, avatar, deleted_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -56,6 +56,7 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 		&user.Phone,
 		&user.Password,
 		&user.Avatar,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -71,7 +72,7 @@ func (r *UserRepository) FindByID(id string) (*models.User, error) {
 	query := `
 		SELECT id, full_name, email, phone, avatar, created_at, updated_at
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	user := &models.User{}
@@ -92,6 +93,34 @@ func (r *UserRepository) FindByID(id string) (*models.User, error) {
 	return user, err
 }
 
+// FindByIDWithPassword returns an active user including the password hash,
+// for operations that require re-entering the password
+func (r *UserRepository) FindByIDWithPassword(id string) (*models.User, error) {
+	query := `
+		SELECT id, full_name, email, phone, password_hash, avatar, created_at, updated_at
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	user := &models.User{}
+	err := r.db.QueryRow(query, id).Scan(
+		&user.ID,
+		&user.FullName,
+		&user.Email,
+		&user.Phone,
+		&user.Password,
+		&user.Avatar,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("user not found")
+	}
+
+	return user, err
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	query := `
 		UPDATE users
@@ -159,3 +188,94 @@ func (r *UserRepository) EmailExists(email string) (bool, error) {
 	err := r.db.QueryRow(query, email).Scan(&exists)
 	return exists, err
 }
+
+// IsActive reports whether the user exists and has not deleted the account
+func (r *UserRepository) IsActive(id string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`
+
+	var active bool
+	err := r.db.QueryRow(query, id).Scan(&active)
+	return active, err
+}
+
+// SoftDelete marks a user as deleted. The account can be restored until it is purged.
+func (r *UserRepository) SoftDelete(id string, deletedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET deleted_at = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, deletedAt, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// Restore clears the soft-delete marker of a user
+func (r *UserRepository) Restore(id string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL, updated_at = $1
+		WHERE id = $2
+	`
+
+	_, err := r.db.Exec(query, time.Now(), id)
+	return err
+}
+
+// FindDeletedBefore returns soft-deleted users whose deletion happened before the given time
+func (r *UserRepository) FindDeletedBefore(before time.Time) ([]models.User, error) {
+	query := `
+		SELECT id, full_name, email, phone, avatar, deleted_at, created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NOT NULL AND deleted_at <= $1
+		ORDER BY deleted_at ASC
+	`
+
+	rows, err := r.db.Query(query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		err := rows.Scan(
+			&u.ID,
+			&u.FullName,
+			&u.Email,
+			&u.Phone,
+			&u.Avatar,
+			&u.DeletedAt,
+			&u.CreatedAt,
+			&u.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// Delete permanently removes a user. Related rows are removed by ON DELETE CASCADE.
+func (r *UserRepository) Delete(id string) error {
+	query := `DELETE FROM users WHERE id = $1`
+
+	_, err := r.db.Exec(query, id)
+	return err
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"nabung-emas-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type ZakatRepository struct {
+	db *sql.DB
+}
+
+func NewZakatRepository(db *sql.DB) *ZakatRepository {
+	return &ZakatRepository{db: db}
+}
+
+// FindLots returns every purchase made on or before the given date, oldest first.
+// Lot weights are fine weights since nisab is measured in pure gold.
+func (r *ZakatRepository) FindLots(userID string, asOf time.Time) ([]models.ZakatLot, error) {
+	query := `
+		SELECT t.id, t.pocket_id, p.name, t.transaction_date, t.brand, t.fine_weight,
+		       COALESCE(p.zakat_exempt, FALSE)
+		FROM transactions t
+		JOIN pockets p ON p.id = t.pocket_id
+		WHERE t.user_id = $1 AND t.transaction_date <= $2
+		ORDER BY t.transaction_date ASC, t.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []models.ZakatLot
+	for rows.Next() {
+		var lot models.ZakatLot
+		err := rows.Scan(
+			&lot.TransactionID,
+			&lot.PocketID,
+			&lot.PocketName,
+			&lot.TransactionDate,
+			&lot.Brand,
+			&lot.Weight,
+			&lot.Exempt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
+
+func (r *ZakatRepository) CreatePayment(payment *models.ZakatPayment) error {
+	query := `
+		INSERT INTO zakat_payments (id, user_id, paid_at, amount, weight, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+
+	payment.ID = uuid.New().String()
+	now := time.Now()
+
+	return r.db.QueryRow(
+		query,
+		payment.ID,
+		payment.UserID,
+		payment.PaidAt,
+		payment.Amount,
+		payment.Weight,
+		payment.Notes,
+		now,
+		now,
+	).Scan(&payment.CreatedAt, &payment.UpdatedAt)
+}
+
+func (r *ZakatRepository) FindPayments(userID string) ([]models.ZakatPayment, error) {
+	query := `
+		SELECT id, user_id, paid_at, amount, weight, notes, created_at, updated_at
+		FROM zakat_payments
+		WHERE user_id = $1
+		ORDER BY paid_at DESC, created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []models.ZakatPayment{}
+	for rows.Next() {
+		var p models.ZakatPayment
+		err := rows.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.PaidAt,
+			&p.Amount,
+			&p.Weight,
+			&p.Notes,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+// GetYearlyHistory sums recorded zakat payments per calendar year, newest first
+func (r *ZakatRepository) GetYearlyHistory(userID string) ([]models.ZakatYearSummary, error) {
+	query := `
+		SELECT 
+			EXTRACT(YEAR FROM paid_at)::INT as year,
+			SUM(amount) as total_paid,
+			COALESCE(SUM(weight), 0) as total_weight,
+			COUNT(*) as payment_count
+		FROM zakat_payments
+		WHERE user_id = $1
+		GROUP BY EXTRACT(YEAR FROM paid_at)
+		ORDER BY year DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.ZakatYearSummary{}
+	for rows.Next() {
+		var h models.ZakatYearSummary
+		if err := rows.Scan(&h.Year, &h.TotalPaid, &h.TotalWeight, &h.PaymentCount); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+func (r *ZakatRepository) DeletePayment(id, userID string) error {
+	query := `DELETE FROM zakat_payments WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("zakat payment not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+// Package returns computes investment returns from dated cash flows and valuations:
+// the money-weighted return (XIRR), which reflects when and how much was invested,
+// and the time-weighted return, which only reflects how the holdings performed.
+// Rates are fractions, e.g. 0.12 for 12%, and years are 365 days.
+package returns
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const daysPerYear = 365.0
+
+// ErrNoSolution is returned when no rate discounts the cash flows to zero, e.g. when
+// every flow has the same sign
+var ErrNoSolution = errors.New("returns: cash flows have no internal rate of return")
+
+// CashFlow is money moving in or out of the investment on a day. Money invested is
+// negative and money received, including the final value of the holdings, positive.
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// Years returns the time between two dates in years of 365 days
+func Years(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / daysPerYear
+}
+
+// Annualize converts a return over the given number of years into a yearly rate
+func Annualize(periodReturn, years float64) float64 {
+	if years <= 0 {
+		return periodReturn
+	}
+	return math.Pow(1+periodReturn, 1/years) - 1
+}
+
+// XIRR returns the annual rate r at which the cash flows are worth zero:
+//
+//	sum(amount_i * (1 + r)^((T - t_i) / 365)) = 0
+//
+// compounded to the date T of the last flow. It is solved for the continuous rate
+// g = ln(1 + r), which keeps short periods with large annualized rates from
+// overflowing, with Newton's method kept inside a bisection bracket.
+func XIRR(flows []CashFlow) (float64, error) {
+	g, err := continuousRate(flows)
+	if err != nil {
+		return 0, err
+	}
+	return math.Expm1(g), nil
+}
+
+// PeriodReturn returns the money-weighted return over the whole period of the cash
+// flows, i.e. the XIRR compounded over the years between the first and last flow
+// instead of annualized
+func PeriodReturn(flows []CashFlow) (float64, error) {
+	g, err := continuousRate(flows)
+	if err != nil {
+		return 0, err
+	}
+
+	first, last := span(flows)
+	return math.Expm1(g * Years(first, last)), nil
+}
+
+const (
+	maxIterations = 200
+	// tolerance is on the continuous rate, well below a basis point a year
+	tolerance = 1e-10
+	// maxRate bounds the bracket search; e^50 is far beyond any real return
+	maxRate = 50.0
+)
+
+func continuousRate(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, ErrNoSolution
+	}
+
+	var hasNegative, hasPositive bool
+	for _, flow := range flows {
+		if flow.Amount < 0 {
+			hasNegative = true
+		}
+		if flow.Amount > 0 {
+			hasPositive = true
+		}
+	}
+	if !hasNegative || !hasPositive {
+		return 0, ErrNoSolution
+	}
+
+	_, end := span(flows)
+	times := make([]float64, len(flows))
+	for i, flow := range flows {
+		times[i] = Years(flow.Date, end)
+	}
+
+	// futureValue and its derivative in g. Flows are compounded to the last date so
+	// every exponent is non-negative.
+	futureValue := func(g float64) (float64, float64) {
+		var value, derivative float64
+		for i, flow := range flows {
+			grown := flow.Amount * math.Exp(g*times[i])
+			value += grown
+			derivative += grown * times[i]
+		}
+		return value, derivative
+	}
+
+	// Find a bracket [low, high] with a sign change by widening around zero
+	low, high := -1.0, 1.0
+	fLow, _ := futureValue(low)
+	fHigh, _ := futureValue(high)
+	for fLow*fHigh > 0 {
+		if low <= -maxRate && high >= maxRate {
+			return 0, ErrNoSolution
+		}
+		low = math.Max(low*2, -maxRate)
+		high = math.Min(high*2, maxRate)
+		fLow, _ = futureValue(low)
+		fHigh, _ = futureValue(high)
+	}
+	if fLow == 0 {
+		return low, nil
+	}
+	if fHigh == 0 {
+		return high, nil
+	}
+
+	g := (low + high) / 2
+	for i := 0; i < maxIterations; i++ {
+		value, derivative := futureValue(g)
+		if value == 0 {
+			return g, nil
+		}
+
+		// Narrow the bracket around the root
+		if (value > 0) == (fLow > 0) {
+			low, fLow = g, value
+		} else {
+			high = g
+		}
+
+		// Take the Newton step when it stays inside the bracket, otherwise bisect
+		next := (low + high) / 2
+		if derivative != 0 {
+			if step := g - value/derivative; step > low && step < high {
+				next = step
+			}
+		}
+
+		if math.Abs(next-g) < tolerance || high-low < tolerance {
+			return next, nil
+		}
+		g = next
+	}
+
+	return g, nil
+}
+
+func span(flows []CashFlow) (time.Time, time.Time) {
+	first, last := flows[0].Date, flows[0].Date
+	for _, flow := range flows[1:] {
+		if flow.Date.Before(first) {
+			first = flow.Date
+		}
+		if flow.Date.After(last) {
+			last = flow.Date
+		}
+	}
+	return first, last
+}
+
+// Valuation is the market value of the holdings on a day just before that day's
+// cash flow, and the flow itself: money invested is positive here, as it is added to
+// the holdings. The last valuation of a series closes the period and has no flow.
+type Valuation struct {
+	Date  time.Time
+	Value float64
+	Flow  float64
+}
+
+// TimeWeighted returns the time-weighted return of a series of valuations by chaining
+// the return of each sub-period between two cash flows:
+//
+//	prod(value_i / (value_(i-1) + flow_(i-1))) - 1
+//
+// so the size and timing of deposits do not affect the result
+func TimeWeighted(valuations []Valuation) (float64, error) {
+	if len(valuations) < 2 {
+		return 0, errors.New("returns: at least two valuations are needed")
+	}
+
+	sorted := make([]Valuation, len(valuations))
+	copy(sorted, valuations)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	growth := 1.0
+	invested := false
+	for i := 1; i < len(sorted); i++ {
+		start := sorted[i-1].Value + sorted[i-1].Flow
+		if start <= 0 {
+			// Nothing was held in this sub-period, so it does not count
+			continue
+		}
+		growth *= sorted[i].Value / start
+		invested = true
+	}
+	if !invested {
+		return 0, errors.New("returns: nothing was invested")
+	}
+
+	return growth - 1, nil
+}
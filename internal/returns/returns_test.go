@@ -0,0 +1,228 @@
+package returns
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func closeTo(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestXIRR(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+	}{
+		{
+			// The example of the XIRR function in spreadsheets
+			name: "spreadsheet example",
+			flows: []CashFlow{
+				{day(2008, 1, 1), -10000},
+				{day(2008, 3, 1), 2750},
+				{day(2008, 10, 30), 4250},
+				{day(2009, 2, 15), 3250},
+				{day(2009, 4, 1), 2750},
+			},
+			want: 0.373362535,
+		},
+		{
+			name: "one year",
+			flows: []CashFlow{
+				{day(2023, 1, 1), -1000},
+				{day(2024, 1, 1), 1100},
+			},
+			want: 0.1,
+		},
+		{
+			// Monthly purchases valued at the end of the year
+			name: "monthly purchases",
+			flows: []CashFlow{
+				{day(2023, 1, 15), -1000000},
+				{day(2023, 2, 15), -1000000},
+				{day(2023, 3, 15), -1000000},
+				{day(2023, 4, 15), -1000000},
+				{day(2023, 12, 31), 4400000},
+			},
+			want: 0.120746421,
+		},
+		{
+			name: "loss",
+			flows: []CashFlow{
+				{day(2022, 1, 1), -1000},
+				{day(2024, 1, 1), 810},
+			},
+			want: math.Pow(0.81, 365.0/730) - 1,
+		},
+		{
+			// Flows out of date order give the same rate
+			name: "unordered",
+			flows: []CashFlow{
+				{day(2024, 1, 1), 1100},
+				{day(2023, 1, 1), -1000},
+			},
+			want: 0.1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := XIRR(tt.flows)
+			if err != nil {
+				t.Fatalf("XIRR: %v", err)
+			}
+			if !closeTo(got, tt.want, 1e-4) {
+				t.Errorf("XIRR = %.9f, want %.9f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXIRRShortPeriod(t *testing.T) {
+	// 5% over 182 days annualizes to more than 10%
+	flows := []CashFlow{
+		{day(2024, 1, 1), -1000},
+		{day(2024, 7, 1), 1050},
+	}
+
+	rate, err := XIRR(flows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := math.Pow(1.05, 365.0/182) - 1; !closeTo(rate, want, 1e-9) {
+		t.Errorf("XIRR = %.9f, want %.9f", rate, want)
+	}
+
+	period, err := PeriodReturn(flows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(period, 0.05, 1e-9) {
+		t.Errorf("PeriodReturn = %.9f, want 0.05", period)
+	}
+
+	// A 50% gain within ten days annualizes to a huge rate without overflowing
+	rate, err = XIRR([]CashFlow{
+		{day(2024, 1, 1), -1000},
+		{day(2024, 1, 11), 1500},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := math.Pow(1.5, 36.5) - 1; !closeTo(rate/want, 1, 1e-6) {
+		t.Errorf("XIRR over ten days = %g, want %g", rate, want)
+	}
+}
+
+func TestXIRRNoSolution(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+	}{
+		{"no flows", nil},
+		{"single flow", []CashFlow{{day(2024, 1, 1), -1000}}},
+		{"only investments", []CashFlow{{day(2024, 1, 1), -1000}, {day(2024, 6, 1), -500}}},
+		{"only receipts", []CashFlow{{day(2024, 1, 1), 1000}, {day(2024, 6, 1), 500}}},
+		{"zero amounts", []CashFlow{{day(2024, 1, 1), 0}, {day(2024, 6, 1), 0}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := XIRR(tt.flows); err != ErrNoSolution {
+			t.Errorf("%s: XIRR error = %v, want ErrNoSolution", tt.name, err)
+		}
+		if _, err := PeriodReturn(tt.flows); err != ErrNoSolution {
+			t.Errorf("%s: PeriodReturn error = %v, want ErrNoSolution", tt.name, err)
+		}
+	}
+}
+
+func TestTimeWeighted(t *testing.T) {
+	tests := []struct {
+		name       string
+		valuations []Valuation
+		want       float64
+	}{
+		{
+			// 10% before and 10% after a deposit chain to 21%, whatever the deposit
+			name: "deposit between periods",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 0, Flow: 1000},
+				{Date: day(2024, 4, 1), Value: 1100, Flow: 5000},
+				{Date: day(2024, 7, 1), Value: 6710},
+			},
+			want: 0.21,
+		},
+		{
+			name: "gain then loss",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 0, Flow: 1000},
+				{Date: day(2024, 2, 1), Value: 1200, Flow: 800},
+				{Date: day(2024, 3, 1), Value: 1800, Flow: 1000},
+				{Date: day(2024, 4, 1), Value: 2800},
+			},
+			want: 1.2*0.9 - 1,
+		},
+		{
+			// A period with nothing held, after selling everything, is left out
+			name: "empty period",
+			valuations: []Valuation{
+				{Date: day(2024, 1, 1), Value: 0, Flow: 1000},
+				{Date: day(2024, 2, 1), Value: 1100, Flow: -1100},
+				{Date: day(2024, 3, 1), Value: 0, Flow: 500},
+				{Date: day(2024, 4, 1), Value: 550},
+			},
+			want: 0.21,
+		},
+		{
+			name: "unordered",
+			valuations: []Valuation{
+				{Date: day(2024, 7, 1), Value: 6710},
+				{Date: day(2024, 1, 1), Value: 0, Flow: 1000},
+				{Date: day(2024, 4, 1), Value: 1100, Flow: 5000},
+			},
+			want: 0.21,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TimeWeighted(tt.valuations)
+			if err != nil {
+				t.Fatalf("TimeWeighted: %v", err)
+			}
+			if !closeTo(got, tt.want, 1e-9) {
+				t.Errorf("TimeWeighted = %.9f, want %.9f", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := TimeWeighted([]Valuation{{Date: day(2024, 1, 1), Value: 1000}}); err == nil {
+		t.Error("TimeWeighted of one valuation succeeded, want an error")
+	}
+	if _, err := TimeWeighted([]Valuation{{Date: day(2024, 1, 1)}, {Date: day(2024, 2, 1)}}); err == nil {
+		t.Error("TimeWeighted without investment succeeded, want an error")
+	}
+}
+
+func TestAnnualize(t *testing.T) {
+	tests := []struct {
+		periodReturn, years, want float64
+	}{
+		{0.21, 2, 0.1},
+		{0.05, 0.5, 0.1025},
+		{0.1, 1, 0.1},
+		{0.1, 0, 0.1},
+	}
+
+	for _, tt := range tests {
+		if got := Annualize(tt.periodReturn, tt.years); !closeTo(got, tt.want, 1e-9) {
+			t.Errorf("Annualize(%v, %v) = %.9f, want %v", tt.periodReturn, tt.years, got, tt.want)
+		}
+	}
+}
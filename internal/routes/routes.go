@@ -2,18 +2,26 @@ package routes
 
 import (
 	"database/sql"
+	"log"
 	"time"
 
 	"nabung-emas-api/internal/config"
+	"nabung-emas-api/internal/exchangerates"
 	"nabung-emas-api/internal/handlers"
 	"nabung-emas-api/internal/middleware"
+	"nabung-emas-api/internal/push"
 	"nabung-emas-api/internal/repositories"
 	"nabung-emas-api/internal/services"
+	"nabung-emas-api/internal/storage"
+	"nabung-emas-api/internal/utils"
 
 	"github.com/labstack/echo/v4"
 )
 
 func Setup(e *echo.Echo, db *sql.DB, cfg *config.Config) {
+	// Initialize file storage
+	fileStorage := storage.NewLocalStorage(cfg.StoragePath)
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	tokenBlacklistRepo := repositories.NewTokenBlacklistRepository(db)
@@ -22,15 +30,71 @@ func Setup(e *echo.Echo, db *sql.DB, cfg *config.Config) {
 	transactionRepo := repositories.NewTransactionRepository(db)
 	analyticsRepo := repositories.NewAnalyticsRepository(db)
 	settingsRepo := repositories.NewSettingsRepository(db)
+	exportRepo := repositories.NewDataExportRepository(db)
+	goldPriceRepo := repositories.NewGoldPriceRepository(db)
+	recurringPlanRepo := repositories.NewRecurringPlanRepository(db)
+	zakatRepo := repositories.NewZakatRepository(db)
+	pledgeRepo := repositories.NewPledgeRepository(db)
+	installmentRepo := repositories.NewInstallmentRepository(db)
+	inventoryRepo := repositories.NewInventoryRepository(db)
+	brandRepo := repositories.NewBrandRepository(db)
+	exchangeRateRepo := repositories.NewExchangeRateRepository(db)
+	benchmarkRepo := repositories.NewBenchmarkRepository(db)
+	snapshotRepo := repositories.NewSnapshotRepository(db)
+	priceAlertRepo := repositories.NewPriceAlertRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	deviceRepo := repositories.NewDeviceRepository(db)
+	streamEventRepo := repositories.NewStreamEventRepository(db)
+
+	// Initialize the exchange rate provider; without one, rates are imported from files
+	exchangeRateProvider, err := exchangerates.NewProvider(cfg.ExchangeRateProvider, cfg.ExchangeRateAPIURL, cfg.ExchangeRateFixturesPath)
+	if err != nil {
+		log.Fatalf("Invalid exchange rate configuration: %v", err)
+	}
+
+	// Initialize push delivery; platforms without credentials are skipped
+	pushSender, err := push.New(push.Config{
+		Provider:           cfg.PushProvider,
+		FCMCredentialsFile: cfg.FCMCredentialsFile,
+		APNsKeyFile:        cfg.APNsKeyFile,
+		APNsKeyID:          cfg.APNsKeyID,
+		APNsTeamID:         cfg.APNsTeamID,
+		APNsTopic:          cfg.APNsTopic,
+		APNsProduction:     cfg.APNsProduction,
+		VAPIDPrivateKey:    cfg.VAPIDPrivateKey,
+		VAPIDSubject:       cfg.VAPIDSubject,
+	})
+	if err != nil {
+		log.Fatalf("Invalid push notification configuration: %v", err)
+	}
 
 	// Initialize services
+	brandService := services.NewBrandService(brandRepo)
+	utils.SetBrandLookup(brandService.IsValid)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo, settingsRepo, exchangeRateProvider)
+	benchmarkService := services.NewBenchmarkService(benchmarkRepo)
+	snapshotService := services.NewSnapshotService(snapshotRepo, goldPriceRepo, exchangeRateService)
 	authService := services.NewAuthService(userRepo, tokenBlacklistRepo, cfg)
-	userService := services.NewUserService(userRepo)
+	userService := services.NewUserService(userRepo, tokenBlacklistRepo, cfg)
 	typePocketService := services.NewTypePocketService(typePocketRepo)
-	pocketService := services.NewPocketService(pocketRepo, typePocketRepo)
-	transactionService := services.NewTransactionService(transactionRepo, pocketRepo)
-	analyticsService := services.NewAnalyticsService(analyticsRepo, transactionRepo, pocketRepo)
+	pushService := services.NewPushService(deviceRepo, pushSender)
+	streamService := services.NewStreamService(streamEventRepo, analyticsRepo, goldPriceRepo, exchangeRateService)
+	notificationService := services.NewNotificationService(notificationRepo, settingsRepo, pushService, streamService)
+	goalService := services.NewGoalService(pocketRepo, analyticsRepo, goldPriceRepo, notificationService)
+	emailService := services.NewEmailService(cfg)
+	pledgeService := services.NewPledgeService(pledgeRepo, pocketRepo, transactionRepo, userRepo, emailService, notificationService)
+	inventoryService := services.NewInventoryService(inventoryRepo, pocketRepo, transactionRepo, brandService)
+	pocketService := services.NewPocketService(pocketRepo, typePocketRepo, goalService, streamService)
+	transactionService := services.NewTransactionService(transactionRepo, pocketRepo, typePocketRepo, settingsRepo, pledgeRepo, brandService, exchangeRateService, goalService, streamService)
+	installmentService := services.NewInstallmentService(installmentRepo, pocketRepo, userRepo, brandService, transactionService, emailService, notificationService)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, transactionRepo, pocketRepo, goldPriceRepo, goalService, pledgeService, installmentService, exchangeRateService, benchmarkService)
 	settingsService := services.NewSettingsService(settingsRepo)
+	exportService := services.NewExportService(exportRepo, userRepo, settingsRepo, pocketRepo, transactionRepo, fileStorage, emailService, cfg)
+	zakatService := services.NewZakatService(zakatRepo, settingsRepo, goldPriceRepo)
+	inheritanceService := services.NewInheritanceService(pocketRepo, goldPriceRepo)
+	recurringPlanService := services.NewRecurringPlanService(recurringPlanRepo, pocketRepo, goldPriceRepo, userRepo, transactionService, brandService, emailService, notificationService)
+	priceAlertService := services.NewPriceAlertService(priceAlertRepo, goldPriceRepo, settingsRepo, userRepo, brandService, emailService, notificationService)
+	goldPriceService := services.NewGoldPriceService(goldPriceRepo, priceAlertService, streamService)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
@@ -40,13 +104,55 @@ func Setup(e *echo.Echo, db *sql.DB, cfg *config.Config) {
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
 	settingsHandler := handlers.NewSettingsHandler(settingsService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	recurringPlanHandler := handlers.NewRecurringPlanHandler(recurringPlanService)
+	zakatHandler := handlers.NewZakatHandler(zakatService)
+	inheritanceHandler := handlers.NewInheritanceHandler(inheritanceService)
+	pledgeHandler := handlers.NewPledgeHandler(pledgeService)
+	installmentHandler := handlers.NewInstallmentHandler(installmentService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	brandHandler := handlers.NewBrandHandler(brandService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+	benchmarkHandler := handlers.NewBenchmarkHandler(benchmarkService)
+	snapshotHandler := handlers.NewSnapshotHandler(snapshotService)
+	priceAlertHandler := handlers.NewPriceAlertHandler(priceAlertService)
+	goldPriceHandler := handlers.NewGoldPriceHandler(goldPriceService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	deviceHandler := handlers.NewDeviceHandler(pushService)
+	streamHandler := handlers.NewStreamHandler(streamService, authService)
 
 	// Initialize auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg, tokenBlacklistRepo)
+	authMiddleware := middleware.NewAuthMiddleware(cfg, tokenBlacklistRepo, userRepo)
 
-	// Initialize and start cleanup service for token blacklist
-	cleanupService := services.NewCleanupService(tokenBlacklistRepo)
+	// Initialize and start cleanup service for token blacklist and deleted accounts
+	cleanupService := services.NewCleanupService(tokenBlacklistRepo, userRepo, transactionRepo, exportRepo, fileStorage, cfg)
 	cleanupService.StartTokenCleanup(24 * time.Hour) // Run cleanup once per day
+	cleanupService.StartAccountPurge(24 * time.Hour) // Purge accounts past the deletion grace period
+
+	// Resume exports interrupted by a restart and remove expired ones
+	exportService.ResumeUnfinished()
+	exportService.StartExpiredCleanup(1 * time.Hour)
+
+	// Generate planned purchases for recurring plans
+	recurringPlanService.StartScheduler(1 * time.Hour)
+
+	// Remind users of pawned gold approaching its due date
+	pledgeService.StartReminders(6 * time.Hour)
+
+	// Remind users of installments approaching their due date
+	installmentService.StartReminders(6 * time.Hour)
+
+	// Fetch the latest exchange rates for display currencies
+	exchangeRateService.StartSync(cfg.ExchangeRateSyncInterval)
+
+	// Record portfolio value snapshots of each finished day
+	snapshotService.StartScheduler(1 * time.Hour)
+
+	// Forward live events stored by any instance to the clients connected to this one
+	if err := streamService.StartListener(cfg.DatabaseURL); err != nil {
+		log.Fatalf("Failed to listen for stream events: %v", err)
+	}
+	streamService.StartCleanup(1 * time.Hour)
 
 	// API v1 group
 	api := e.Group("/api/v1")
@@ -72,15 +178,64 @@ func Setup(e *echo.Echo, db *sql.DB, cfg *config.Config) {
 		typePockets.GET("/:id", typePocketHandler.GetByID)
 	}
 
+	// Public routes - Brands
+	brands := api.Group("/brands")
+	{
+		brands.GET("", brandHandler.GetAll)
+		brands.GET("/:id", brandHandler.GetByID)
+	}
+
+	// Admin routes - Brand management
+	adminBrands := api.Group("/admin/brands", authMiddleware.RequireAuth, authMiddleware.RequireAdmin)
+	{
+		adminBrands.GET("", brandHandler.AdminGetAll)
+		adminBrands.POST("", brandHandler.Create)
+		adminBrands.PATCH("/:id", brandHandler.Update)
+		adminBrands.DELETE("/:id", brandHandler.Delete)
+	}
+
+	// Admin routes - Exchange rates
+	adminExchangeRates := api.Group("/admin/exchange-rates", authMiddleware.RequireAuth, authMiddleware.RequireAdmin)
+	{
+		adminExchangeRates.POST("/import", exchangeRateHandler.Import)
+		adminExchangeRates.POST("/sync", exchangeRateHandler.Sync)
+	}
+
+	// Protected routes - Exchange rates
+	api.GET("/exchange-rates", exchangeRateHandler.GetAll, authMiddleware.RequireAuth)
+
+	// Admin routes - Benchmarks
+	api.POST("/admin/benchmarks/import", benchmarkHandler.Import, authMiddleware.RequireAuth, authMiddleware.RequireAdmin)
+
+	// Admin routes - Portfolio snapshots
+	api.POST("/admin/snapshots/backfill", snapshotHandler.Backfill, authMiddleware.RequireAuth, authMiddleware.RequireAdmin)
+
+	// Admin routes - Gold prices, evaluating price alerts
+	api.POST("/admin/gold-prices", goldPriceHandler.Store, authMiddleware.RequireAuth, authMiddleware.RequireAdmin)
+
+	// Protected routes - Benchmarks
+	benchmarksGroup := api.Group("/benchmarks", authMiddleware.RequireAuth)
+	{
+		benchmarksGroup.GET("", benchmarkHandler.GetAll)
+		benchmarksGroup.GET("/:key/values", benchmarkHandler.GetValues)
+	}
+
 	// Protected routes - User Profile
 	profile := api.Group("/profile", authMiddleware.RequireAuth)
 	{
 		profile.GET("", userHandler.GetProfile)
 		profile.PATCH("", userHandler.UpdateProfile)
+		profile.DELETE("", userHandler.DeleteAccount)
 		profile.POST("/avatar", userHandler.UploadAvatar)
 		profile.POST("/change-password", userHandler.ChangePassword)
+		profile.POST("/export", exportHandler.Request)
+		profile.GET("/export", exportHandler.GetAll)
+		profile.GET("/export/:id", exportHandler.GetByID)
 	}
 
+	// Public routes - Data export download (authorized by signed link)
+	api.GET("/exports/:id/download", exportHandler.Download)
+
 	// Protected routes - Pockets
 	pockets := api.Group("/pockets", authMiddleware.RequireAuth)
 	{
@@ -96,11 +251,62 @@ func Setup(e *echo.Echo, db *sql.DB, cfg *config.Config) {
 	transactions := api.Group("/transactions", authMiddleware.RequireAuth)
 	{
 		transactions.GET("", transactionHandler.GetAll)
+		transactions.GET("/export", transactionHandler.Export)
+		transactions.POST("/import", transactionHandler.Import)
+		transactions.GET("/import/platforms", transactionHandler.GetImportPlatforms)
+		transactions.POST("/import/:platform", transactionHandler.ImportPlatform)
 		transactions.GET("/:id", transactionHandler.GetByID)
 		transactions.POST("", transactionHandler.Create)
 		transactions.PATCH("/:id", transactionHandler.Update)
 		transactions.DELETE("/:id", transactionHandler.Delete)
 		transactions.POST("/:id/receipt", transactionHandler.UploadReceipt)
+		transactions.POST("/:id/inventory", inventoryHandler.CreateFromTransaction)
+	}
+
+	// Protected routes - Physical bar inventory
+	inventory := api.Group("/inventory", authMiddleware.RequireAuth)
+	{
+		inventory.GET("", inventoryHandler.GetAll)
+		inventory.POST("", inventoryHandler.Create)
+		inventory.GET("/locations", inventoryHandler.GetLocations)
+		inventory.GET("/:id", inventoryHandler.GetByID)
+		inventory.PATCH("/:id", inventoryHandler.Update)
+		inventory.DELETE("/:id", inventoryHandler.Delete)
+	}
+
+	// Protected routes - Recurring Plans
+	plans := api.Group("/plans", authMiddleware.RequireAuth)
+	{
+		plans.GET("", recurringPlanHandler.GetAll)
+		plans.POST("", recurringPlanHandler.Create)
+		plans.GET("/purchases", recurringPlanHandler.GetPurchases)
+		plans.POST("/purchases/:id/confirm", recurringPlanHandler.ConfirmPurchase)
+		plans.POST("/purchases/:id/skip", recurringPlanHandler.SkipPurchase)
+		plans.GET("/:id", recurringPlanHandler.GetByID)
+		plans.PATCH("/:id", recurringPlanHandler.Update)
+		plans.DELETE("/:id", recurringPlanHandler.Delete)
+	}
+
+	// Protected routes - Gold pledges (gadai)
+	pledges := api.Group("/pledges", authMiddleware.RequireAuth)
+	{
+		pledges.GET("", pledgeHandler.GetAll)
+		pledges.POST("", pledgeHandler.Create)
+		pledges.GET("/:id", pledgeHandler.GetByID)
+		pledges.PATCH("/:id", pledgeHandler.Update)
+		pledges.DELETE("/:id", pledgeHandler.Delete)
+		pledges.POST("/:id/redeem", pledgeHandler.Redeem)
+	}
+
+	// Protected routes - Installment purchases (cicilan emas)
+	installments := api.Group("/installments", authMiddleware.RequireAuth)
+	{
+		installments.GET("", installmentHandler.GetAll)
+		installments.POST("", installmentHandler.Create)
+		installments.GET("/:id", installmentHandler.GetByID)
+		installments.DELETE("/:id", installmentHandler.Delete)
+		installments.POST("/:id/payments", installmentHandler.RecordPayment)
+		installments.POST("/:id/cancel", installmentHandler.Cancel)
 	}
 
 	// Protected routes - Analytics
@@ -110,9 +316,57 @@ func Setup(e *echo.Echo, db *sql.DB, cfg *config.Config) {
 		analytics.GET("/portfolio", analyticsHandler.GetPortfolio)
 		analytics.GET("/monthly-purchases", analyticsHandler.GetMonthlyPurchases)
 		analytics.GET("/brand-distribution", analyticsHandler.GetBrandDistribution)
+		analytics.GET("/premiums", analyticsHandler.GetPremiums)
+		analytics.GET("/performance", analyticsHandler.GetPerformance)
+		analytics.GET("/benchmarks", analyticsHandler.CompareBenchmarks)
+		analytics.GET("/value-history", snapshotHandler.GetValueHistory)
+		analytics.GET("/dca", analyticsHandler.GetDCAReport)
 		analytics.GET("/trends", analyticsHandler.GetTrends)
+		analytics.GET("/zakat", zakatHandler.GetReport)
+		analytics.POST("/faraid", inheritanceHandler.Simulate)
 	}
 
+	// Protected routes - Zakat payment history
+	zakat := api.Group("/zakat", authMiddleware.RequireAuth)
+	{
+		zakat.GET("/payments", zakatHandler.GetPayments)
+		zakat.POST("/payments", zakatHandler.CreatePayment)
+		zakat.DELETE("/payments/:id", zakatHandler.DeletePayment)
+	}
+
+	// Protected routes - Price alerts
+	priceAlerts := api.Group("/price-alerts", authMiddleware.RequireAuth)
+	{
+		priceAlerts.GET("", priceAlertHandler.GetAll)
+		priceAlerts.POST("", priceAlertHandler.Create)
+		priceAlerts.GET("/events", priceAlertHandler.GetEvents)
+		priceAlerts.GET("/:id", priceAlertHandler.GetByID)
+		priceAlerts.PATCH("/:id", priceAlertHandler.Update)
+		priceAlerts.DELETE("/:id", priceAlertHandler.Delete)
+	}
+
+	// Protected routes - Notifications
+	notifications := api.Group("/notifications", authMiddleware.RequireAuth)
+	{
+		notifications.GET("", notificationHandler.GetAll)
+		notifications.POST("/read-all", notificationHandler.MarkAllRead)
+		notifications.GET("/preferences", notificationHandler.GetPreferences)
+		notifications.PATCH("/preferences", notificationHandler.UpdatePreferences)
+		notifications.POST("/:id/read", notificationHandler.MarkRead)
+	}
+
+	// Protected routes - Push notification devices
+	devices := api.Group("/devices", authMiddleware.RequireAuth)
+	{
+		devices.GET("", deviceHandler.GetAll)
+		devices.POST("", deviceHandler.Register)
+		devices.GET("/vapid-public-key", deviceHandler.GetVAPIDPublicKey)
+		devices.DELETE("/:id", deviceHandler.Delete)
+	}
+
+	// Protected routes - Live event stream (server-sent events)
+	api.GET("/stream", streamHandler.Stream, authMiddleware.RequireStreamAuth)
+
 	// Protected routes - Settings
 	settings := api.Group("/settings", authMiddleware.RequireAuth)
 	{
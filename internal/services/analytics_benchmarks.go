@@ -0,0 +1,119 @@
+package services
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/benchmarks"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/returns"
+)
+
+// CompareBenchmarks simulates placing the rupiah of each purchase day in every
+// benchmark of keys, a comma separated list or empty for all, instead of gold, and
+// values both today. Gold is valued as in GetPerformance.
+func (s *AnalyticsService) CompareBenchmarks(userID, currency, keys string, currentGoldPrice *decimal.Decimal, source string) (*models.BenchmarkComparison, error) {
+	end := today()
+	selected, series, err := s.benchmarkService.Series(keys, end)
+	if err != nil {
+		return nil, err
+	}
+
+	currentGoldPrice, priceDate, err := s.currentGoldPrice(currentGoldPrice, source)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &models.BenchmarkComparison{
+		Currency:         currency,
+		CurrentGoldPrice: *currentGoldPrice,
+		PriceDate:        priceDate,
+		Benchmarks:       []models.BenchmarkResult{},
+		Unavailable:      []string{},
+	}
+
+	transactions, err := s.transactionRepo.FindAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return comparison, nil
+	}
+
+	prices, err := s.goldPriceRepo.FindBetween(transactions[0].TransactionDate.Add(-valuationPriceMaxAge), end, source)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := s.exchangeRateService.NewConverter(currency)
+	days := purchaseDays(transactions)
+
+	comparison.Gold, err = returnPerformance(days, prices, *currentGoldPrice, end, converter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, benchmark := range selected {
+		result, err := benchmarkResult(benchmark, series[i], days, end, converter)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			comparison.Unavailable = append(comparison.Unavailable, benchmark.Key)
+			continue
+		}
+
+		result.GoldDifference = comparison.Gold.CurrentValue.Sub(result.CurrentValue)
+		result.GoldOutperformed = result.GoldDifference.IsPositive()
+		comparison.Benchmarks = append(comparison.Benchmarks, *result)
+	}
+
+	return comparison, nil
+}
+
+// benchmarkResult grows the cost of each purchase day in the benchmark until end. It
+// returns nil when the points do not reach back to the first purchase.
+func benchmarkResult(benchmark benchmarks.Benchmark, points []benchmarks.Point, days []purchaseDay, end time.Time, converter *CurrencyConverter) (*models.BenchmarkResult, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	result := &models.BenchmarkResult{
+		Benchmark:     benchmark.Key,
+		Name:          benchmark.Name,
+		LastValueDate: points[len(points)-1].Date,
+	}
+
+	var flows []returns.CashFlow
+	var endValue float64
+	for _, day := range days {
+		growth, err := benchmarks.Growth(benchmark, points, day.date, end)
+		if err != nil {
+			return nil, nil
+		}
+		endValue += day.cost.Float64() * growth
+
+		cost, err := converter.Convert(day.cost, day.date)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, returns.CashFlow{Date: day.date, Amount: -cost.Float64()})
+		result.TotalCost = result.TotalCost.Add(cost)
+	}
+
+	currentValue, err := converter.Convert(decimal.NewFromFloat(endValue).Round(decimal.MoneyPlaces), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, returns.CashFlow{Date: end, Amount: currentValue.Float64()})
+
+	result.CurrentValue = currentValue
+	result.ProfitLoss = currentValue.Sub(result.TotalCost)
+	if result.TotalCost.IsPositive() {
+		simple := roundTo(result.ProfitLoss.Ratio(result.TotalCost)*100, 2)
+		result.SimpleReturn = &simple
+	}
+	result.MoneyWeightedReturn, result.AnnualizedMoneyWeightedReturn = moneyWeightedReturns(flows, returns.Years(days[0].date, end))
+
+	return result, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/exchangerates"
+	"nabung-emas-api/internal/models"
+)
+
+// GetDCAReport compares the user's purchases, or those of one pocket, with the stored
+// gold prices of the same period. The report stays in rupiah, the currency of the
+// stored prices.
+func (s *AnalyticsService) GetDCAReport(userID string, pocketID *string, source string) (*models.DCAReport, error) {
+	report := &models.DCAReport{
+		Currency: exchangerates.Base,
+		PocketID: pocketID,
+		Months:   []models.DCAMonth{},
+	}
+
+	all, err := s.transactionRepo.FindAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	var transactions []models.Transaction
+	for _, t := range all {
+		if pocketID == nil || t.PocketID == *pocketID {
+			transactions = append(transactions, t)
+		}
+	}
+	if len(transactions) == 0 {
+		return report, nil
+	}
+
+	report.StartDate = dateOf(transactions[0].TransactionDate)
+	report.EndDate = dateOf(transactions[len(transactions)-1].TransactionDate)
+
+	// Load whole months, so each month's market average covers the full month, plus
+	// the week before for the first purchase day's price
+	firstMonth := time.Date(report.StartDate.Year(), report.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastMonthEnd := time.Date(report.EndDate.Year(), report.EndDate.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	prices, err := s.goldPriceRepo.FindBetween(firstMonth.Add(-valuationPriceMaxAge), lastMonthEnd, source)
+	if err != nil {
+		return nil, err
+	}
+	prices = dailyPrices(prices)
+
+	// Average market price of the period and of each month, one price per day
+	var periodSum decimal.Decimal
+	periodDays := 0
+	monthSums := make(map[string]decimal.Decimal)
+	monthDays := make(map[string]int)
+	for _, price := range prices {
+		value := decimal.NewFromFloat(price.PricePerGram)
+		if !price.Date.Before(report.StartDate) && !price.Date.After(report.EndDate) {
+			periodSum = periodSum.Add(value)
+			periodDays++
+		}
+		month := price.Date.Format("2006-01")
+		monthSums[month] = monthSums[month].Add(value)
+		monthDays[month]++
+	}
+	report.MarketPriceDays = periodDays
+	if periodDays > 0 {
+		average, err := periodSum.DivInt(int64(periodDays))
+		if err != nil {
+			return nil, err
+		}
+		average = average.Round(decimal.MoneyPlaces)
+		report.AverageMarketPrice = &average
+	}
+
+	var pricedTotal, spotFineWeight decimal.Decimal
+	monthIndex := make(map[string]int)
+	for _, t := range transactions {
+		report.TransactionCount++
+		report.TotalPrice = report.TotalPrice.Add(t.TotalPrice)
+		report.TotalCost = report.TotalCost.Add(t.TotalCost)
+		report.FineWeight = report.FineWeight.Add(t.FineWeight)
+
+		if spot, ok := valuationPrice(prices, dateOf(t.TransactionDate)); ok {
+			report.PricedTransactions++
+			pricedTotal = pricedTotal.Add(t.TotalPrice)
+			fineWeight, err := t.TotalPrice.Div(spot)
+			if err != nil {
+				return nil, err
+			}
+			spotFineWeight = spotFineWeight.Add(fineWeight)
+		}
+
+		month := t.TransactionDate.Format("2006-01")
+		i, ok := monthIndex[month]
+		if !ok {
+			i = len(report.Months)
+			monthIndex[month] = i
+			report.Months = append(report.Months, models.DCAMonth{Month: month})
+		}
+		m := &report.Months[i]
+		m.TransactionCount++
+		m.TotalPrice = m.TotalPrice.Add(t.TotalPrice)
+		m.TotalCost = m.TotalCost.Add(t.TotalCost)
+		m.FineWeight = m.FineWeight.Add(t.FineWeight)
+	}
+
+	if report.AveragePricePerFineGram, err = report.TotalPrice.Div(report.FineWeight); err != nil {
+		return nil, err
+	}
+	report.AveragePricePerFineGram = report.AveragePricePerFineGram.Round(decimal.MoneyPlaces)
+	if report.AverageCostPerFineGram, err = report.TotalCost.Div(report.FineWeight); err != nil {
+		return nil, err
+	}
+	report.AverageCostPerFineGram = report.AverageCostPerFineGram.Round(decimal.MoneyPlaces)
+
+	if spotFineWeight.IsPositive() {
+		averageSpot, err := pricedTotal.Div(spotFineWeight)
+		if err != nil {
+			return nil, err
+		}
+		averageSpot = averageSpot.Round(decimal.MoneyPlaces)
+		report.AverageSpotAtPurchases = &averageSpot
+		if report.AverageMarketPrice != nil {
+			timing := roundTo(averageSpot.Sub(*report.AverageMarketPrice).Ratio(*report.AverageMarketPrice)*100, 2)
+			report.TimingPercentage = &timing
+		}
+	}
+
+	// Rank months by how the price paid compares with that month's market average
+	for i := range report.Months {
+		m := &report.Months[i]
+		if m.AveragePricePerFineGram, err = m.TotalPrice.Div(m.FineWeight); err != nil {
+			return nil, err
+		}
+		m.AveragePricePerFineGram = m.AveragePricePerFineGram.Round(decimal.MoneyPlaces)
+		if days := monthDays[m.Month]; days > 0 {
+			average, err := monthSums[m.Month].DivInt(int64(days))
+			if err != nil {
+				return nil, err
+			}
+			average = average.Round(decimal.MoneyPlaces)
+			difference := roundTo(m.AveragePricePerFineGram.Sub(average).Ratio(average)*100, 2)
+			m.AverageMarketPrice = &average
+			m.DifferencePercentage = &difference
+
+			if report.BestMonth == nil || difference < *report.BestMonth.DifferencePercentage {
+				report.BestMonth = m
+			}
+			if report.WorstMonth == nil || difference > *report.WorstMonth.DifferencePercentage {
+				report.WorstMonth = m
+			}
+		}
+	}
+	if report.BestMonth != nil {
+		best, worst := *report.BestMonth, *report.WorstMonth
+		report.BestMonth, report.WorstMonth = &best, &worst
+	}
+
+	report.Simulation, err = simulateMonthlyPurchases(report.StartDate, report.EndDate, prices, pricedTotal, spotFineWeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// simulateMonthlyPurchases spends amount in equal parts on the same day of every month
+// from start to end. It returns nil when no month has a stored price.
+func simulateMonthlyPurchases(start, end time.Time, prices []models.GoldPrice, amount, actualSpotFineWeight decimal.Decimal) (*models.DCASimulation, error) {
+	if !amount.IsPositive() {
+		return nil, nil
+	}
+
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	var monthPrices []decimal.Decimal
+	for i := 0; i < months; i++ {
+		if price, ok := valuationPrice(prices, addMonthsClamped(start, i)); ok {
+			monthPrices = append(monthPrices, price)
+		}
+	}
+	if len(monthPrices) == 0 {
+		return nil, nil
+	}
+
+	monthlyAmount, err := amount.DivInt(int64(len(monthPrices)))
+	if err != nil {
+		return nil, err
+	}
+	simulation := &models.DCASimulation{
+		MonthlyAmount:        monthlyAmount.Round(decimal.MoneyPlaces),
+		Months:               len(monthPrices),
+		SkippedMonths:        months - len(monthPrices),
+		ActualSpotFineWeight: actualSpotFineWeight.Round(decimal.WeightPlaces),
+	}
+	for _, price := range monthPrices {
+		simulation.TotalAmount = simulation.TotalAmount.Add(simulation.MonthlyAmount)
+		fineWeight, err := simulation.MonthlyAmount.Div(price)
+		if err != nil {
+			return nil, err
+		}
+		simulation.FineWeight = simulation.FineWeight.Add(fineWeight)
+	}
+	if simulation.AveragePricePerFineGram, err = simulation.TotalAmount.Div(simulation.FineWeight); err != nil {
+		return nil, err
+	}
+	simulation.AveragePricePerFineGram = simulation.AveragePricePerFineGram.Round(decimal.MoneyPlaces)
+	simulation.FineWeight = simulation.FineWeight.Round(decimal.WeightPlaces)
+	simulation.FineWeightDifference = simulation.ActualSpotFineWeight.Sub(simulation.FineWeight)
+	if simulation.FineWeight.IsPositive() {
+		simulation.DifferencePercentage = roundTo(simulation.FineWeightDifference.Ratio(simulation.FineWeight)*100, 2)
+	}
+
+	return simulation, nil
+}
+
+// dailyPrices keeps one price per day of prices ordered by date, the last stored
+func dailyPrices(prices []models.GoldPrice) []models.GoldPrice {
+	var daily []models.GoldPrice
+	for _, price := range prices {
+		if n := len(daily); n > 0 && daily[n-1].Date.Equal(price.Date) {
+			daily[n-1] = price
+			continue
+		}
+		daily = append(daily, price)
+	}
+	return daily
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
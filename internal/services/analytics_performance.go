@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/returns"
+)
+
+// valuationPriceMaxAge is how old a stored gold price may be to value the holdings
+// on a purchase day, the same window the premium analytics compare against
+const valuationPriceMaxAge = 7 * 24 * time.Hour
+
+// purchaseDay adds up the purchases of one day, in rupiah
+type purchaseDay struct {
+	date       time.Time
+	cost       decimal.Decimal
+	fineWeight decimal.Decimal
+	count      int
+}
+
+// GetPerformance computes money-weighted (XIRR) and time-weighted returns of the
+// portfolio and of each pocket. Holdings are valued on every purchase day at the
+// stored gold price and today at currentGoldPrice, in rupiah per gram, or else at the
+// latest stored price. Amounts are converted into currency at the rate of their day.
+func (s *AnalyticsService) GetPerformance(userID, currency string, currentGoldPrice *decimal.Decimal, source string) (*models.PerformanceAnalytics, error) {
+	end := today()
+	currentGoldPrice, priceDate, err := s.currentGoldPrice(currentGoldPrice, source)
+	if err != nil {
+		return nil, err
+	}
+
+	performance := &models.PerformanceAnalytics{
+		Currency:         currency,
+		CurrentGoldPrice: *currentGoldPrice,
+		PriceDate:        priceDate,
+		Pockets:          []models.ReturnPerformance{},
+	}
+
+	transactions, err := s.transactionRepo.FindAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return performance, nil
+	}
+
+	prices, err := s.goldPriceRepo.FindBetween(transactions[0].TransactionDate.Add(-valuationPriceMaxAge), end, source)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := s.exchangeRateService.NewConverter(currency)
+
+	performance.Portfolio, err = returnPerformance(purchaseDays(transactions), prices, *currentGoldPrice, end, converter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions are ordered by date, so each pocket's purchases stay in order
+	byPocket := make(map[string][]models.Transaction)
+	names := make(map[string]string)
+	for _, t := range transactions {
+		byPocket[t.PocketID] = append(byPocket[t.PocketID], t)
+		if t.Pocket != nil {
+			names[t.PocketID] = t.Pocket.Name
+		}
+	}
+
+	for pocketID, pocketTransactions := range byPocket {
+		pocket, err := returnPerformance(purchaseDays(pocketTransactions), prices, *currentGoldPrice, end, converter)
+		if err != nil {
+			return nil, err
+		}
+		pocket.PocketID = pocketID
+		pocket.PocketName = names[pocketID]
+		performance.Pockets = append(performance.Pockets, *pocket)
+	}
+	sort.Slice(performance.Pockets, func(i, j int) bool {
+		return performance.Pockets[i].PocketName < performance.Pockets[j].PocketName
+	})
+
+	return performance, nil
+}
+
+// currentGoldPrice returns the given price dated today, or else the latest stored price
+func (s *AnalyticsService) currentGoldPrice(price *decimal.Decimal, source string) (*decimal.Decimal, time.Time, error) {
+	if price != nil {
+		return price, today(), nil
+	}
+
+	latest, err := s.goldPriceRepo.FindOnOrBefore(time.Now(), source)
+	if err != nil {
+		return nil, time.Time{}, errors.New("no stored gold price, provide current_gold_price")
+	}
+	stored := decimal.NewFromFloat(latest.PricePerGram)
+
+	return &stored, latest.Date, nil
+}
+
+// purchaseDays groups transactions ordered by date into one cash flow per day
+func purchaseDays(transactions []models.Transaction) []purchaseDay {
+	var days []purchaseDay
+	for _, t := range transactions {
+		date := dateOf(t.TransactionDate)
+		if len(days) == 0 || !days[len(days)-1].date.Equal(date) {
+			days = append(days, purchaseDay{date: date})
+		}
+
+		day := &days[len(days)-1]
+		day.cost = day.cost.Add(t.TotalCost)
+		day.fineWeight = day.fineWeight.Add(t.FineWeight)
+		day.count++
+	}
+	return days
+}
+
+// valuationPrice returns the latest price of prices, ordered by date, from the week
+// before day
+func valuationPrice(prices []models.GoldPrice, day time.Time) (decimal.Decimal, bool) {
+	i := sort.Search(len(prices), func(i int) bool { return prices[i].Date.After(day) })
+	if i == 0 || day.Sub(prices[i-1].Date) > valuationPriceMaxAge {
+		return decimal.Zero, false
+	}
+	return decimal.NewFromFloat(prices[i-1].PricePerGram), true
+}
+
+func returnPerformance(days []purchaseDay, prices []models.GoldPrice, currentGoldPrice decimal.Decimal, end time.Time, converter *CurrencyConverter) (*models.ReturnPerformance, error) {
+	performance := &models.ReturnPerformance{FirstTransactionDate: days[0].date}
+
+	var flows []returns.CashFlow
+	var valuations []returns.Valuation
+	for _, day := range days {
+		price, ok := valuationPrice(prices, day.date)
+		if !ok {
+			// Without a market price, the gold is worth what was paid for it that day
+			var err error
+			if price, err = day.cost.Div(day.fineWeight); err != nil {
+				return nil, err
+			}
+			performance.EstimatedValuations++
+		}
+
+		value, err := performance.FineWeight.Mul(price)
+		if err != nil {
+			return nil, err
+		}
+		value, err = converter.Convert(value.Round(decimal.MoneyPlaces), day.date)
+		if err != nil {
+			return nil, err
+		}
+		cost, err := converter.Convert(day.cost, day.date)
+		if err != nil {
+			return nil, err
+		}
+
+		flows = append(flows, returns.CashFlow{Date: day.date, Amount: -cost.Float64()})
+		valuations = append(valuations, returns.Valuation{Date: day.date, Value: value.Float64(), Flow: cost.Float64()})
+
+		performance.TotalCost = performance.TotalCost.Add(cost)
+		performance.FineWeight = performance.FineWeight.Add(day.fineWeight)
+		performance.TransactionCount += day.count
+	}
+
+	currentValue, err := performance.FineWeight.Mul(currentGoldPrice)
+	if err != nil {
+		return nil, err
+	}
+	currentValue, err = converter.Convert(currentValue.Round(decimal.MoneyPlaces), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, returns.CashFlow{Date: end, Amount: currentValue.Float64()})
+	valuations = append(valuations, returns.Valuation{Date: end, Value: currentValue.Float64()})
+
+	performance.CurrentValue = currentValue
+	performance.ProfitLoss = currentValue.Sub(performance.TotalCost)
+	performance.HoldingDays = int(end.Sub(performance.FirstTransactionDate).Hours() / 24)
+	years := returns.Years(performance.FirstTransactionDate, end)
+
+	if performance.TotalCost.IsPositive() {
+		simple := roundTo(performance.ProfitLoss.Ratio(performance.TotalCost)*100, 2)
+		performance.SimpleReturn = &simple
+	}
+
+	performance.MoneyWeightedReturn, performance.AnnualizedMoneyWeightedReturn = moneyWeightedReturns(flows, years)
+
+	if twr, err := returns.TimeWeighted(valuations); err == nil {
+		timeWeighted := roundTo(twr*100, 2)
+		performance.TimeWeightedReturn = &timeWeighted
+		if years >= 1 {
+			annualized := roundTo(returns.Annualize(twr, years)*100, 2)
+			performance.AnnualizedTimeWeightedReturn = &annualized
+		}
+	}
+
+	return performance, nil
+}
+
+// moneyWeightedReturns returns the money-weighted return of the cash flows over the
+// period and, for periods of at least a year, annualized, both as percentages. They
+// are nil when the flows have no solution.
+func moneyWeightedReturns(flows []returns.CashFlow, years float64) (*float64, *float64) {
+	if years <= 0 {
+		return nil, nil
+	}
+
+	periodReturn, err := returns.PeriodReturn(flows)
+	if err != nil {
+		return nil, nil
+	}
+	moneyWeighted := roundTo(periodReturn*100, 2)
+	if years < 1 {
+		return &moneyWeighted, nil
+	}
+	annualized := roundTo(returns.Annualize(periodReturn, years)*100, 2)
+
+	return &moneyWeighted, &annualized
+}
@@ -1,40 +1,89 @@
 package services
 
 import (
+	"time"
+
+	"nabung-emas-api/internal/decimal"
 	"nabung-emas-api/internal/models"
 	"nabung-emas-api/internal/repositories"
 )
 
 type AnalyticsService struct {
-	analyticsRepo   *repositories.AnalyticsRepository
-	transactionRepo *repositories.TransactionRepository
-	pocketRepo      *repositories.PocketRepository
+	analyticsRepo       *repositories.AnalyticsRepository
+	transactionRepo     *repositories.TransactionRepository
+	pocketRepo          *repositories.PocketRepository
+	goldPriceRepo       *repositories.GoldPriceRepository
+	goalService         *GoalService
+	pledgeService       *PledgeService
+	installmentService  *InstallmentService
+	exchangeRateService *ExchangeRateService
+	benchmarkService    *BenchmarkService
 }
 
 func NewAnalyticsService(
 	analyticsRepo *repositories.AnalyticsRepository,
 	transactionRepo *repositories.TransactionRepository,
 	pocketRepo *repositories.PocketRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+	goalService *GoalService,
+	pledgeService *PledgeService,
+	installmentService *InstallmentService,
+	exchangeRateService *ExchangeRateService,
+	benchmarkService *BenchmarkService,
 ) *AnalyticsService {
 	return &AnalyticsService{
-		analyticsRepo:   analyticsRepo,
-		transactionRepo: transactionRepo,
-		pocketRepo:      pocketRepo,
+		analyticsRepo:       analyticsRepo,
+		transactionRepo:     transactionRepo,
+		pocketRepo:          pocketRepo,
+		goldPriceRepo:       goldPriceRepo,
+		goalService:         goalService,
+		pledgeService:       pledgeService,
+		installmentService:  installmentService,
+		exchangeRateService: exchangeRateService,
+		benchmarkService:    benchmarkService,
+	}
+}
+
+// DisplayCurrency resolves the currency analytics are shown in: the requested one or
+// the user's setting. Its rates must reach back to the user's first transaction,
+// otherwise older amounts would be left out of the totals.
+func (s *AnalyticsService) DisplayCurrency(userID, requested string) (string, error) {
+	firstDate, err := s.transactionRepo.FindFirstDate(userID)
+	if err != nil {
+		return "", err
 	}
+
+	return s.exchangeRateService.DisplayCurrencySince(userID, requested, firstDate)
 }
 
-func (s *AnalyticsService) GetDashboard(userID string, currentGoldPrice *float64) (*models.DashboardSummary, error) {
+// GetDashboard summarizes the portfolio in currency. Purchases are converted at the
+// rate of their date, current values and outstanding amounts at the latest rate.
+// The current gold price is given in rupiah per gram.
+func (s *AnalyticsService) GetDashboard(userID, currency string, currentGoldPrice *decimal.Decimal) (*models.DashboardSummary, error) {
+	converter := s.exchangeRateService.NewConverter(currency)
+	now := time.Now()
+
 	// Get portfolio summary
-	portfolio, err := s.analyticsRepo.GetPortfolioSummary(userID)
+	portfolio, err := s.analyticsRepo.GetPortfolioSummary(userID, currency)
 	if err != nil {
 		return nil, err
 	}
+	portfolio.Currency = currency
 
-	// Calculate profit/loss if current price provided
-	if currentGoldPrice != nil && *currentGoldPrice > 0 && portfolio.TotalWeight > 0 {
-		currentValue := portfolio.TotalWeight * (*currentGoldPrice)
-		profitLoss := currentValue - portfolio.TotalValue
-		profitLossPercentage := (profitLoss / portfolio.TotalValue) * 100
+	// Calculate profit/loss against the cost including fees if current price provided.
+	// Gold prices are quoted for fine gold, so jewelry and lower karat gold is valued
+	// by its fine weight.
+	if currentGoldPrice != nil && currentGoldPrice.IsPositive() && portfolio.TotalFineWeight.IsPositive() {
+		currentValue, err := portfolio.TotalFineWeight.Mul(*currentGoldPrice)
+		if err != nil {
+			return nil, err
+		}
+		currentValue, err = converter.Convert(currentValue.Round(decimal.MoneyPlaces), now)
+		if err != nil {
+			return nil, err
+		}
+		profitLoss := currentValue.Sub(portfolio.TotalCost)
+		profitLossPercentage := profitLoss.Ratio(portfolio.TotalCost) * 100
 
 		portfolio.CurrentGoldPrice = currentGoldPrice
 		portfolio.CurrentValue = &currentValue
@@ -42,49 +91,111 @@ func (s *AnalyticsService) GetDashboard(userID string, currentGoldPrice *float64
 		portfolio.ProfitLossPercentage = &profitLossPercentage
 	}
 
+	// Pawned gold is excluded from available weight and its loan shown as a liability
+	pledges, err := s.pledgeService.GetSummary(userID)
+	if err != nil {
+		return nil, err
+	}
+	portfolio.PledgedWeight = pledges.PledgedWeight
+	portfolio.AvailableWeight = portfolio.TotalWeight.Sub(portfolio.PledgedWeight)
+	if err := convertPledgeSummary(converter, pledges, now); err != nil {
+		return nil, err
+	}
+	portfolio.OutstandingLoan = pledges.OutstandingLoan
+
 	// Get recent transactions
 	recentTransactions, err := s.transactionRepo.GetRecentTransactions(userID, 5)
 	if err != nil {
 		return nil, err
 	}
+	for i := range recentTransactions {
+		if err := converter.ConvertTransaction(&recentTransactions[i]); err != nil {
+			return nil, err
+		}
+	}
 
 	topPockets, err := s.pocketRepo.GetTopPockets(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Gold bought on installment is only owned after the last payment, so it is reported separately
+	installments, err := s.installmentService.GetSummary(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertInstallmentSummary(converter, installments, now); err != nil {
+		return nil, err
+	}
+
+	goals, err := s.goalService.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range goals {
+		if err := convertGoal(converter, &goals[i], now); err != nil {
+			return nil, err
+		}
+	}
+
 	return &models.DashboardSummary{
 		Portfolio:          *portfolio,
 		TopPockets:         topPockets,
+		Goals:              goals,
+		Pledges:            *pledges,
+		Installments:       *installments,
 		RecentTransactions: recentTransactions,
 	}, nil
 }
 
-func (s *AnalyticsService) GetPortfolio(userID string, currentGoldPrice *float64) (*models.PortfolioAnalytics, error) {
+func (s *AnalyticsService) GetPortfolio(userID, currency string, currentGoldPrice *decimal.Decimal) (*models.PortfolioAnalytics, error) {
+	converter := s.exchangeRateService.NewConverter(currency)
+	now := time.Now()
+
 	// Get portfolio summary
-	summary, err := s.analyticsRepo.GetPortfolioSummary(userID)
+	summary, err := s.analyticsRepo.GetPortfolioSummary(userID, currency)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get distribution
-	distribution, err := s.analyticsRepo.GetPocketDistribution(userID)
+	distribution, err := s.analyticsRepo.GetPocketDistribution(userID, currency)
 	if err != nil {
 		return nil, err
 	}
 
+	installments, err := s.installmentService.GetSummary(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertInstallmentSummary(converter, installments, now); err != nil {
+		return nil, err
+	}
+
 	analytics := &models.PortfolioAnalytics{
+		Currency:            currency,
 		TotalValue:          summary.TotalValue,
+		TotalFees:           summary.TotalFees,
+		TotalCost:           summary.TotalCost,
 		TotalWeight:         summary.TotalWeight,
+		TotalFineWeight:     summary.TotalFineWeight,
 		AveragePricePerGram: summary.AveragePricePerGram,
 		Distribution:        distribution,
+		Installments:        installments,
 	}
 
-	// Calculate profit/loss if current price provided
-	if currentGoldPrice != nil && *currentGoldPrice > 0 && analytics.TotalWeight > 0 {
-		currentValue := analytics.TotalWeight * (*currentGoldPrice)
-		profitLoss := currentValue - analytics.TotalValue
-		profitLossPercentage := (profitLoss / analytics.TotalValue) * 100
+	// Calculate profit/loss against the cost including fees if current price provided
+	if currentGoldPrice != nil && currentGoldPrice.IsPositive() && analytics.TotalFineWeight.IsPositive() {
+		currentValue, err := analytics.TotalFineWeight.Mul(*currentGoldPrice)
+		if err != nil {
+			return nil, err
+		}
+		currentValue, err = converter.Convert(currentValue.Round(decimal.MoneyPlaces), now)
+		if err != nil {
+			return nil, err
+		}
+		profitLoss := currentValue.Sub(analytics.TotalCost)
+		profitLossPercentage := profitLoss.Ratio(analytics.TotalCost) * 100
 
 		analytics.CurrentMarketPrice = currentGoldPrice
 		analytics.CurrentValue = &currentValue
@@ -95,38 +206,135 @@ func (s *AnalyticsService) GetPortfolio(userID string, currentGoldPrice *float64
 	return analytics, nil
 }
 
-func (s *AnalyticsService) GetMonthlyPurchases(userID string, months int, pocketID *string) (*models.MonthlyPurchaseAnalytics, error) {
+func (s *AnalyticsService) GetMonthlyPurchases(userID, currency string, months int, pocketID *string) (*models.MonthlyPurchaseAnalytics, error) {
 	if months < 1 {
 		months = 6
 	}
 
-	monthlyData, err := s.analyticsRepo.GetMonthlyPurchases(userID, months, pocketID)
+	monthlyData, err := s.analyticsRepo.GetMonthlyPurchases(userID, currency, months, pocketID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Calculate averages
-	var totalWeight, totalAmount float64
+	var totalWeight, totalAmount decimal.Decimal
 	for _, data := range monthlyData {
-		totalWeight += data.Weight
-		totalAmount += data.Amount
+		totalWeight = totalWeight.Add(data.Weight)
+		totalAmount = totalAmount.Add(data.Amount)
 	}
 
-	avgMonthlyPurchase := 0.0
-	if len(monthlyData) > 0 {
-		avgMonthlyPurchase = totalAmount / float64(len(monthlyData))
+	avgMonthlyPurchase, err := totalAmount.DivInt(int64(len(monthlyData)))
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.MonthlyPurchaseAnalytics{
+		Currency:               currency,
 		MonthlyData:            monthlyData,
-		AverageMonthlyPurchase: avgMonthlyPurchase,
+		AverageMonthlyPurchase: avgMonthlyPurchase.Round(decimal.MoneyPlaces),
 		TotalPeriodWeight:      totalWeight,
 		TotalPeriodAmount:      totalAmount,
 	}, nil
 }
 
-func (s *AnalyticsService) GetBrandDistribution(userID string) ([]models.BrandDistribution, error) {
-	return s.analyticsRepo.GetBrandDistribution(userID)
+func (s *AnalyticsService) GetBrandDistribution(userID, currency string) ([]models.BrandDistribution, error) {
+	return s.analyticsRepo.GetBrandDistribution(userID, currency)
+}
+
+// GetPremiums shows per brand how much was paid above the spot price, through the
+// dealer's price per gram and through fees
+func (s *AnalyticsService) GetPremiums(userID, currency, source string) (*models.PremiumAnalytics, error) {
+	brands, err := s.analyticsRepo.GetBrandPremiums(userID, currency, source)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.BrandPremium{Currency: currency, FeesByType: make(map[string]decimal.Decimal)}
+	for i := range brands {
+		if err := applyPremiumTotals(&brands[i]); err != nil {
+			return nil, err
+		}
+
+		bp := brands[i]
+		summary.Weight = summary.Weight.Add(bp.Weight)
+		summary.FineWeight = summary.FineWeight.Add(bp.FineWeight)
+		summary.TransactionCount += bp.TransactionCount
+		summary.TotalCost = summary.TotalCost.Add(bp.TotalCost)
+		summary.TotalFees = summary.TotalFees.Add(bp.TotalFees)
+		for feeType, amount := range bp.FeesByType {
+			summary.FeesByType[feeType] = summary.FeesByType[feeType].Add(amount)
+		}
+		summary.PricedTransactions += bp.PricedTransactions
+		summary.PricedFineWeight = summary.PricedFineWeight.Add(bp.PricedFineWeight)
+		summary.SpotEquivalentCost = summary.SpotEquivalentCost.Add(bp.SpotEquivalentCost)
+		summary.DealerPremium = summary.DealerPremium.Add(bp.DealerPremium)
+		summary.FeePremium = summary.FeePremium.Add(bp.FeePremium)
+	}
+	if err := applyPremiumTotals(&summary); err != nil {
+		return nil, err
+	}
+
+	return &models.PremiumAnalytics{
+		Brands:  brands,
+		Summary: summary,
+	}, nil
+}
+
+func applyPremiumTotals(bp *models.BrandPremium) error {
+	bp.TotalPremium = bp.DealerPremium.Add(bp.FeePremium)
+	perFineGram, err := bp.TotalPremium.Div(bp.PricedFineWeight)
+	if err != nil {
+		return err
+	}
+	bp.PremiumPerFineGram = perFineGram.Round(decimal.MoneyPlaces)
+	bp.PremiumPercentage = 0
+	if bp.SpotEquivalentCost.IsPositive() {
+		bp.PremiumPercentage = roundTo(bp.TotalPremium.Ratio(bp.SpotEquivalentCost)*100, 2)
+	}
+	return nil
+}
+
+// convertPledgeSummary converts outstanding pledge amounts at the rate of on
+func convertPledgeSummary(converter *CurrencyConverter, summary *models.PledgeSummary, on time.Time) error {
+	for _, amount := range []*decimal.Decimal{&summary.OutstandingLoan, &summary.OutstandingFees} {
+		converted, err := converter.Convert(*amount, on)
+		if err != nil {
+			return err
+		}
+		*amount = converted
+	}
+	return nil
+}
+
+// convertInstallmentSummary converts installment amounts at the rate of on
+func convertInstallmentSummary(converter *CurrencyConverter, summary *models.InstallmentSummary, on time.Time) error {
+	for _, amount := range []*decimal.Decimal{&summary.TotalPayable, &summary.TotalPaid, &summary.RemainingAmount} {
+		converted, err := converter.Convert(*amount, on)
+		if err != nil {
+			return err
+		}
+		*amount = converted
+	}
+	return nil
+}
+
+// convertGoal converts the rupiah amounts of a savings goal at the rate of on
+func convertGoal(converter *CurrencyConverter, goal *models.PocketGoal, on time.Time) error {
+	amounts := []*decimal.Decimal{&goal.CurrentAmount, &goal.AverageMonthlyAmount}
+	for _, amount := range []*decimal.Decimal{goal.TargetAmount, goal.RemainingAmount, goal.RequiredMonthlyAmount} {
+		if amount != nil {
+			amounts = append(amounts, amount)
+		}
+	}
+
+	for _, amount := range amounts {
+		converted, err := converter.Convert(*amount, on)
+		if err != nil {
+			return err
+		}
+		*amount = converted
+	}
+	return nil
 }
 
 func (s *AnalyticsService) GetTrends(userID string, period, groupBy string) (*models.TrendAnalytics, error) {
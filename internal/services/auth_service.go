@@ -86,6 +86,14 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.User, *models.Tok
 		return nil, nil, errors.New("invalid email or password")
 	}
 
+	// Logging in during the deletion grace period restores the account
+	if user.DeletedAt != nil {
+		if err := s.userRepo.Restore(user.ID); err != nil {
+			return nil, nil, err
+		}
+		user.DeletedAt = nil
+	}
+
 	// Generate tokens
 	expiry := s.config.JWTExpiry
 	if req.RememberMe {
@@ -121,6 +129,11 @@ func (s *AuthService) RefreshToken(refreshToken string) (*models.TokenResponse,
 		return nil, errors.New("invalid or expired refresh token")
 	}
 
+	// Deleted accounts must log in again to be restored
+	if _, err := s.userRepo.FindByID(claims.UserID); err != nil {
+		return nil, errors.New("invalid or expired refresh token")
+	}
+
 	// Generate new tokens
 	accessToken, err := utils.GenerateToken(claims.UserID, claims.Email, s.config.JWTSecret, s.config.JWTExpiry)
 	if err != nil {
@@ -163,6 +176,28 @@ func (s *AuthService) ResetPassword(token, newPassword string) error {
 	return errors.New("password reset not yet implemented")
 }
 
+// CheckToken checks that an access token accepted earlier is still valid: that it has
+// not been revoked by logging out and that its account has not been deleted since
+func (s *AuthService) CheckToken(accessToken, userID string) error {
+	isBlacklisted, err := s.tokenBlacklistRepo.IsBlacklisted(accessToken)
+	if err != nil {
+		return err
+	}
+	if isBlacklisted {
+		return errors.New("token has been revoked")
+	}
+
+	active, err := s.userRepo.IsActive(userID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return errors.New("account has been deleted")
+	}
+
+	return nil
+}
+
 func (s *AuthService) Logout(accessToken string, userID string) error {
 	// Validate the token to get its expiration time
 	claims, err := utils.ValidateToken(accessToken, s.config.JWTSecret)
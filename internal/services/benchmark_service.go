@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/benchmarks"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+type BenchmarkService struct {
+	benchmarkRepo *repositories.BenchmarkRepository
+}
+
+func NewBenchmarkService(benchmarkRepo *repositories.BenchmarkRepository) *BenchmarkService {
+	return &BenchmarkService{benchmarkRepo: benchmarkRepo}
+}
+
+// GetAll lists the supported benchmarks with the range of their stored values
+func (s *BenchmarkService) GetAll() ([]models.BenchmarkSeries, error) {
+	ranges, err := s.benchmarkRepo.GetRanges()
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]models.BenchmarkSeries, len(benchmarks.All))
+	for i, benchmark := range benchmarks.All {
+		series[i] = ranges[benchmark.Key]
+		series[i].Key = benchmark.Key
+		series[i].Name = benchmark.Name
+		series[i].Description = benchmark.Description
+		series[i].Rate = benchmark.Rate
+	}
+
+	return series, nil
+}
+
+func (s *BenchmarkService) GetValues(key string, startDate, endDate *string) ([]models.BenchmarkValue, error) {
+	if _, ok := benchmarks.Find(key); !ok {
+		return nil, errors.New("benchmark not found")
+	}
+
+	for _, date := range []*string{startDate, endDate} {
+		if date == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", *date); err != nil {
+			return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+	}
+
+	return s.benchmarkRepo.FindAll(key, startDate, endDate)
+}
+
+// Import stores the values of a date,benchmark,value CSV file
+func (s *BenchmarkService) Import(r io.Reader) (*models.BenchmarkImportResult, error) {
+	values, err := benchmarks.ParseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BenchmarkImportResult{Benchmarks: make(map[string]int)}
+	records := make([]models.BenchmarkValue, len(values))
+	for i, value := range values {
+		records[i] = models.BenchmarkValue{
+			Benchmark: value.Benchmark,
+			Date:      value.Date,
+			Value:     value.Value,
+			Source:    "file",
+		}
+		result.Benchmarks[value.Benchmark]++
+	}
+
+	if err := s.benchmarkRepo.Upsert(records); err != nil {
+		return nil, err
+	}
+	result.Imported = len(records)
+
+	return result, nil
+}
+
+// Series returns the stored points of each requested benchmark up to end, ordered by
+// date. keys is a comma separated list; when empty, every benchmark is returned.
+func (s *BenchmarkService) Series(keys string, end time.Time) ([]benchmarks.Benchmark, [][]benchmarks.Point, error) {
+	selected := benchmarks.All
+	if keys != "" {
+		selected = nil
+		for _, key := range strings.Split(keys, ",") {
+			benchmark, ok := benchmarks.Find(strings.ToLower(strings.TrimSpace(key)))
+			if !ok {
+				return nil, nil, errors.New("unknown benchmark: " + key)
+			}
+			selected = append(selected, benchmark)
+		}
+	}
+
+	endDate := end.Format("2006-01-02")
+	series := make([][]benchmarks.Point, len(selected))
+	for i, benchmark := range selected {
+		values, err := s.benchmarkRepo.FindAll(benchmark.Key, nil, &endDate)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, value := range values {
+			series[i] = append(series[i], benchmarks.Point{Date: value.Date, Value: value.Value})
+		}
+	}
+
+	return selected, series, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+// brandCacheTTL bounds how long brand changes made by another instance take to show up
+const brandCacheTTL = 5 * time.Minute
+
+type BrandService struct {
+	brandRepo *repositories.BrandRepository
+
+	mu       sync.RWMutex
+	brands   map[string]*models.Brand // keyed by ID and lower-case name
+	loadedAt time.Time
+}
+
+func NewBrandService(brandRepo *repositories.BrandRepository) *BrandService {
+	return &BrandService{brandRepo: brandRepo}
+}
+
+func (s *BrandService) GetAll(activeOnly bool) ([]models.Brand, error) {
+	return s.brandRepo.FindAll(activeOnly)
+}
+
+func (s *BrandService) GetByID(id string) (*models.Brand, error) {
+	return s.brandRepo.FindByID(id)
+}
+
+// Resolve looks up an active brand by ID or case-insensitive name. Requests may
+// send either, so clients using brand names keep working.
+func (s *BrandService) Resolve(value string) (*models.Brand, error) {
+	brand, err := s.lookup(value)
+	if err != nil {
+		return nil, err
+	}
+	if brand == nil {
+		return nil, errors.New("brand not found")
+	}
+	if !brand.Active {
+		return nil, errors.New("brand is no longer available")
+	}
+	return brand, nil
+}
+
+// IsValid reports whether the value names an active brand. It backs the "brand"
+// validation tag.
+func (s *BrandService) IsValid(value string) bool {
+	brand, err := s.lookup(value)
+	return err == nil && brand != nil && brand.Active
+}
+
+func (s *BrandService) Create(req *models.CreateBrandRequest) (*models.Brand, error) {
+	name := strings.TrimSpace(req.Name)
+	exists, err := s.brandRepo.NameExists(name, "")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("brand name already exists")
+	}
+
+	brand := &models.Brand{
+		Name:    name,
+		LogoURL: req.LogoURL,
+		Color:   req.Color,
+		Active:  true,
+	}
+	if req.Active != nil {
+		brand.Active = *req.Active
+	}
+
+	if err := s.brandRepo.Create(brand); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return brand, nil
+}
+
+func (s *BrandService) Update(id string, req *models.UpdateBrandRequest) (*models.Brand, error) {
+	brand, err := s.brandRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" && name != brand.Name {
+		exists, err := s.brandRepo.NameExists(name, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errors.New("brand name already exists")
+		}
+		brand.Name = name
+	}
+
+	// An empty logo or color clears it
+	if req.LogoURL != nil {
+		if *req.LogoURL == "" {
+			brand.LogoURL = nil
+		} else {
+			if _, err := url.ParseRequestURI(*req.LogoURL); err != nil {
+				return nil, errors.New("invalid logo url")
+			}
+			brand.LogoURL = req.LogoURL
+		}
+	}
+	if req.Color != nil {
+		if *req.Color == "" {
+			brand.Color = nil
+		} else {
+			brand.Color = req.Color
+		}
+	}
+	if req.Active != nil {
+		brand.Active = *req.Active
+	}
+
+	if err := s.brandRepo.Update(brand); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return brand, nil
+}
+
+// Delete removes an unused brand. Brands with records are deactivated instead so
+// history keeps its brand.
+func (s *BrandService) Delete(id string) error {
+	inUse, err := s.brandRepo.IsInUse(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New("brand is in use, deactivate it instead")
+	}
+
+	if err := s.brandRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *BrandService) lookup(value string) (*models.Brand, error) {
+	key := strings.ToLower(strings.TrimSpace(value))
+
+	s.mu.RLock()
+	if s.brands != nil && time.Since(s.loadedAt) < brandCacheTTL {
+		brand := s.brands[key]
+		s.mu.RUnlock()
+		return brand, nil
+	}
+	s.mu.RUnlock()
+
+	brands, err := s.brandRepo.FindAll(false)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]*models.Brand, len(brands)*2)
+	for i := range brands {
+		brand := &brands[i]
+		cache[brand.ID] = brand
+		cache[strings.ToLower(brand.Name)] = brand
+	}
+
+	s.mu.Lock()
+	s.brands = cache
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return cache[key], nil
+}
+
+func (s *BrandService) invalidate() {
+	s.mu.Lock()
+	s.brands = nil
+	s.mu.Unlock()
+}
@@ -2,17 +2,36 @@ package services
 
 import (
 	"log"
+	"nabung-emas-api/internal/config"
 	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/storage"
 	"time"
 )
 
 type CleanupService struct {
 	tokenBlacklistRepo *repositories.TokenBlacklistRepository
+	userRepo           userStore
+	transactionRepo    transactionStore
+	exportRepo         exportStore
+	storage            *storage.LocalStorage
+	config             *config.Config
 }
 
-func NewCleanupService(tokenBlacklistRepo *repositories.TokenBlacklistRepository) *CleanupService {
+func NewCleanupService(
+	tokenBlacklistRepo *repositories.TokenBlacklistRepository,
+	userRepo *repositories.UserRepository,
+	transactionRepo *repositories.TransactionRepository,
+	exportRepo *repositories.DataExportRepository,
+	fileStorage *storage.LocalStorage,
+	cfg *config.Config,
+) *CleanupService {
 	return &CleanupService{
 		tokenBlacklistRepo: tokenBlacklistRepo,
+		userRepo:           userRepo,
+		transactionRepo:    transactionRepo,
+		exportRepo:         exportRepo,
+		storage:            fileStorage,
+		config:             cfg,
 	}
 }
 
@@ -29,3 +48,70 @@ func (s *CleanupService) StartTokenCleanup(interval time.Duration) {
 		}
 	}()
 }
+
+// StartAccountPurge starts a background goroutine that periodically purges accounts
+// whose deletion grace period has ended
+func (s *CleanupService) StartAccountPurge(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			purged, err := s.PurgeDeletedAccounts()
+			if err != nil {
+				log.Printf("Error purging deleted accounts: %v", err)
+			} else if purged > 0 {
+				log.Printf("Successfully purged %d deleted accounts", purged)
+			}
+		}
+	}()
+}
+
+// PurgeDeletedAccounts removes stored files and database rows of every account
+// soft-deleted longer than the grace period ago
+func (s *CleanupService) PurgeDeletedAccounts() (int, error) {
+	cutoff := time.Now().Add(-s.config.AccountDeletionGracePeriod)
+
+	users, err := s.userRepo.FindDeletedBefore(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, user := range users {
+		receipts, err := s.transactionRepo.GetReceiptImagesByUser(user.ID)
+		if err != nil {
+			log.Printf("Error listing receipts of user %s: %v", user.ID, err)
+			continue
+		}
+
+		exportFiles, err := s.exportRepo.GetFileKeysByUser(user.ID)
+		if err != nil {
+			log.Printf("Error listing exports of user %s: %v", user.ID, err)
+			continue
+		}
+
+		files := append(receipts, exportFiles...)
+		if user.Avatar != nil {
+			files = append(files, *user.Avatar)
+		}
+
+		// Files go first: once the rows are gone there is nothing left pointing to them
+		failed := false
+		for _, file := range files {
+			if err := s.storage.Delete(file); err != nil {
+				log.Printf("Error deleting file %s of user %s: %v", file, user.ID, err)
+				failed = true
+			}
+		}
+		if failed {
+			continue
+		}
+
+		if err := s.userRepo.Delete(user.ID); err != nil {
+			log.Printf("Error deleting user %s: %v", user.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/config"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/storage"
+)
+
+// fakeDeletedUsers keeps soft-deleted users in memory
+type fakeDeletedUsers struct {
+	users   []models.User
+	cutoff  time.Time
+	deleted []string
+}
+
+func (f *fakeDeletedUsers) FindDeletedBefore(before time.Time) ([]models.User, error) {
+	f.cutoff = before
+	var users []models.User
+	for _, user := range f.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeDeletedUsers) Delete(id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+// userFiles maps user IDs to stored file keys
+type userFiles map[string][]string
+
+func (f userFiles) GetReceiptImagesByUser(userID string) ([]string, error) {
+	return f[userID], nil
+}
+
+func (f userFiles) GetFileKeysByUser(userID string) ([]string, error) {
+	return f[userID], nil
+}
+
+func writeStoredFile(t *testing.T, fileStorage *storage.LocalStorage, key string) {
+	t.Helper()
+	if err := fileStorage.Save(key, strings.NewReader("content")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func storedFileExists(t *testing.T, fileStorage *storage.LocalStorage, key string) bool {
+	t.Helper()
+	path, err := fileStorage.Path(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	grace := 30 * 24 * time.Hour
+	deletedAgo := func(days int) *time.Time {
+		at := time.Now().AddDate(0, 0, -days)
+		return &at
+	}
+	avatar := "avatars/expired.jpg"
+	externalAvatar := "https://example.com/avatar.jpg"
+
+	users := &fakeDeletedUsers{users: []models.User{
+		{ID: "expired", DeletedAt: deletedAgo(31), Avatar: &avatar},
+		{ID: "in-grace", DeletedAt: deletedAgo(29)},
+		{ID: "stuck", DeletedAt: deletedAgo(40), Avatar: &externalAvatar},
+		{ID: "active"},
+	}}
+	receipts := userFiles{
+		"expired":  {"receipts/expired-1.jpg", "receipts/expired-2.jpg"},
+		"in-grace": {"receipts/in-grace.jpg"},
+		"stuck":    {"receipts/stuck"},
+	}
+	exports := userFiles{"expired": {"exports/expired.zip"}}
+
+	fileStorage := storage.NewLocalStorage(t.TempDir())
+	for _, key := range []string{avatar, "receipts/expired-1.jpg", "receipts/expired-2.jpg", "receipts/in-grace.jpg", "exports/expired.zip"} {
+		writeStoredFile(t, fileStorage, key)
+	}
+	// A directory that is not empty cannot be removed like a file
+	writeStoredFile(t, fileStorage, "receipts/stuck/inner.jpg")
+
+	service := NewCleanupService(nil, nil, nil, nil, fileStorage, &config.Config{AccountDeletionGracePeriod: grace})
+	service.userRepo = users
+	service.transactionRepo = receipts
+	service.exportRepo = exports
+
+	purged, err := service.PurgeDeletedAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wantCutoff := time.Now().Add(-grace); users.cutoff.After(wantCutoff) || wantCutoff.Sub(users.cutoff) > time.Minute {
+		t.Errorf("cutoff = %s, want the grace period before now (%s)", users.cutoff, wantCutoff)
+	}
+
+	// The account whose file could not be removed is retried on the next run
+	if purged != 1 || len(users.deleted) != 1 || users.deleted[0] != "expired" {
+		t.Errorf("purged %d accounts %v, want only the expired one", purged, users.deleted)
+	}
+	for _, key := range []string{avatar, "receipts/expired-1.jpg", "receipts/expired-2.jpg", "exports/expired.zip"} {
+		if storedFileExists(t, fileStorage, key) {
+			t.Errorf("%s of the purged account was kept", key)
+		}
+	}
+	if !storedFileExists(t, fileStorage, "receipts/in-grace.jpg") {
+		t.Error("a receipt of an account within its grace period was removed")
+	}
+	if !storedFileExists(t, fileStorage, filepath.Join("receipts", "stuck", "inner.jpg")) {
+		t.Error("files of the account that was not purged were removed")
+	}
+}
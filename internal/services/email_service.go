@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"nabung-emas-api/internal/config"
+	"strings"
+)
+
+type EmailService struct {
+	config *config.Config
+}
+
+func NewEmailService(cfg *config.Config) *EmailService {
+	return &EmailService{config: cfg}
+}
+
+// Send delivers a plain text email. When SMTP is not configured the message is
+// only logged, so development setups work without a mail server.
+func (s *EmailService) Send(to, subject, body string) error {
+	if s.config.SMTPHost == "" {
+		log.Printf("SMTP not configured, skipping email to %s: %s", to, subject)
+		return nil
+	}
+
+	msg := strings.Join([]string{
+		"From: " + s.config.EmailFrom,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+	}
+
+	addr := s.config.SMTPHost + ":" + s.config.SMTPPort
+	if err := smtp.SendMail(addr, auth, s.config.EmailFrom, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/exchangerates"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+// exchangeRateSyncDays is how many past days each scheduled sync fetches, so rates
+// published late or corrected by the provider are picked up
+const exchangeRateSyncDays = 7
+
+type ExchangeRateService struct {
+	exchangeRateRepo *repositories.ExchangeRateRepository
+	settingsRepo     *repositories.SettingsRepository
+	provider         exchangerates.Provider
+}
+
+func NewExchangeRateService(
+	exchangeRateRepo *repositories.ExchangeRateRepository,
+	settingsRepo *repositories.SettingsRepository,
+	provider exchangerates.Provider,
+) *ExchangeRateService {
+	return &ExchangeRateService{
+		exchangeRateRepo: exchangeRateRepo,
+		settingsRepo:     settingsRepo,
+		provider:         provider,
+	}
+}
+
+func (s *ExchangeRateService) GetAll(currency string, startDate, endDate *string) ([]models.ExchangeRate, error) {
+	currency = strings.ToUpper(currency)
+	if !exchangerates.IsSupported(currency) || currency == exchangerates.Base {
+		return nil, errors.New("unsupported currency")
+	}
+
+	for _, date := range []*string{startDate, endDate} {
+		if date == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", *date); err != nil {
+			return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+	}
+
+	return s.exchangeRateRepo.FindAll(currency, startDate, endDate)
+}
+
+// Import stores the rates of a date,currency,rate CSV file
+func (s *ExchangeRateService) Import(r io.Reader) (*models.ExchangeRateImportResult, error) {
+	rates, err := exchangerates.ParseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store(rates, "file")
+}
+
+// Sync fetches the rates of every supported currency between from and to from the provider
+func (s *ExchangeRateService) Sync(from, to time.Time) (*models.ExchangeRateImportResult, error) {
+	if s.provider == nil {
+		return nil, errors.New("no exchange rate provider is configured")
+	}
+	if to.Before(from) {
+		return nil, errors.New("end date cannot be before start date")
+	}
+
+	var currencies []string
+	for _, currency := range exchangerates.Supported {
+		if currency != exchangerates.Base {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	rates, err := s.provider.Fetch(currencies, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store(rates, s.provider.Name())
+}
+
+func (s *ExchangeRateService) store(rates []exchangerates.Rate, source string) (*models.ExchangeRateImportResult, error) {
+	result := &models.ExchangeRateImportResult{}
+	if len(rates) == 0 {
+		return result, nil
+	}
+
+	records := make([]models.ExchangeRate, len(rates))
+	for i, rate := range rates {
+		records[i] = models.ExchangeRate{
+			Date:     rate.Date,
+			Currency: rate.Currency,
+			Rate:     rate.Rate,
+			Source:   source,
+		}
+
+		date := rate.Date
+		if result.From == nil || date.Before(*result.From) {
+			result.From = &date
+		}
+		if result.To == nil || date.After(*result.To) {
+			result.To = &date
+		}
+	}
+
+	if err := s.exchangeRateRepo.Upsert(records); err != nil {
+		return nil, err
+	}
+	result.Imported = len(records)
+
+	return result, nil
+}
+
+// StartSync starts a background goroutine that fetches the latest rates from the
+// provider. Without a provider, rates are only imported from files.
+func (s *ExchangeRateService) StartSync(interval time.Duration) {
+	if s.provider == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			to := today()
+			result, err := s.Sync(to.AddDate(0, 0, -exchangeRateSyncDays), to)
+			if err != nil {
+				log.Printf("Error syncing exchange rates: %v", err)
+			} else if result.Imported > 0 {
+				log.Printf("Successfully synced %d exchange rates", result.Imported)
+			}
+		}
+	}()
+}
+
+// DisplayCurrency returns the requested currency, falling back to the user's setting
+func (s *ExchangeRateService) DisplayCurrency(userID, requested string) (string, error) {
+	if requested != "" {
+		requested = strings.ToUpper(requested)
+		if !exchangerates.IsSupported(requested) {
+			return "", errors.New("unsupported currency")
+		}
+		return requested, nil
+	}
+
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil {
+		return "", err
+	}
+	if settings.Currency == "" {
+		return exchangerates.Base, nil
+	}
+
+	return settings.Currency, nil
+}
+
+// CheckHistory makes sure amounts dated since the given day can be converted,
+// instead of silently leaving out amounts older than the first stored rate
+func (s *ExchangeRateService) CheckHistory(currency string, since time.Time) error {
+	if currency == exchangerates.Base {
+		return nil
+	}
+
+	earliest, err := s.exchangeRateRepo.FindEarliestDate(currency)
+	if err != nil {
+		return err
+	}
+	if earliest == nil || earliest.After(since) {
+		return fmt.Errorf("no %s exchange rate on or before %s, import earlier rates first", currency, since.Format("2006-01-02"))
+	}
+
+	return nil
+}
+
+// DisplayCurrencySince resolves the display currency like DisplayCurrency and checks
+// that its rates reach back to since, the date of the oldest amount to convert
+func (s *ExchangeRateService) DisplayCurrencySince(userID, requested string, since *time.Time) (string, error) {
+	currency, err := s.DisplayCurrency(userID, requested)
+	if err != nil {
+		return "", err
+	}
+	if since != nil {
+		if err := s.CheckHistory(currency, *since); err != nil {
+			return "", err
+		}
+	}
+
+	return currency, nil
+}
+
+// NewConverter returns a converter from rupiah into the given currency
+func (s *ExchangeRateService) NewConverter(currency string) *CurrencyConverter {
+	return &CurrencyConverter{
+		Currency: currency,
+		repo:     s.exchangeRateRepo,
+		rates:    make(map[string]decimal.Decimal),
+	}
+}
+
+// CurrencyConverter converts rupiah amounts into a display currency at the rate of
+// their date, caching the rate of each day it has looked up
+type CurrencyConverter struct {
+	Currency string
+	repo     *repositories.ExchangeRateRepository
+	rates    map[string]decimal.Decimal
+}
+
+// Convert converts an amount at the latest rate on or before the given day
+func (c *CurrencyConverter) Convert(amount decimal.Decimal, on time.Time) (decimal.Decimal, error) {
+	if c.Currency == exchangerates.Base {
+		return amount, nil
+	}
+
+	day := on.Format("2006-01-02")
+	rate, ok := c.rates[day]
+	if !ok {
+		stored, err := c.repo.FindOnOrBefore(c.Currency, on)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("no %s exchange rate on or before %s", c.Currency, day)
+		}
+		rate = stored.Rate
+		c.rates[day] = rate
+	}
+
+	converted, err := amount.Div(rate)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return converted.Round(decimal.MoneyPlaces), nil
+}
+
+// ConvertTransaction converts the amounts of a transaction at the rate of its date
+func (c *CurrencyConverter) ConvertTransaction(t *models.Transaction) error {
+	t.Currency = c.Currency
+	if c.Currency == exchangerates.Base {
+		return nil
+	}
+
+	for _, amount := range []*decimal.Decimal{&t.PricePerGram, &t.TotalPrice, &t.TotalFees} {
+		converted, err := c.Convert(*amount, t.TransactionDate)
+		if err != nil {
+			return err
+		}
+		*amount = converted
+	}
+	t.TotalCost = t.TotalPrice.Add(t.TotalFees)
+
+	for i := range t.Fees {
+		converted, err := c.Convert(t.Fees[i].Amount, t.TransactionDate)
+		if err != nil {
+			return err
+		}
+		t.Fees[i].Amount = converted
+	}
+
+	return nil
+}
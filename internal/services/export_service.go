@@ -0,0 +1,435 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"nabung-emas-api/internal/config"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/storage"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+type ExportService struct {
+	exportRepo      *repositories.DataExportRepository
+	userRepo        *repositories.UserRepository
+	settingsRepo    *repositories.SettingsRepository
+	pocketRepo      *repositories.PocketRepository
+	transactionRepo *repositories.TransactionRepository
+	storage         *storage.LocalStorage
+	emailService    *EmailService
+	config          *config.Config
+}
+
+func NewExportService(
+	exportRepo *repositories.DataExportRepository,
+	userRepo *repositories.UserRepository,
+	settingsRepo *repositories.SettingsRepository,
+	pocketRepo *repositories.PocketRepository,
+	transactionRepo *repositories.TransactionRepository,
+	fileStorage *storage.LocalStorage,
+	emailService *EmailService,
+	cfg *config.Config,
+) *ExportService {
+	return &ExportService{
+		exportRepo:      exportRepo,
+		userRepo:        userRepo,
+		settingsRepo:    settingsRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+		storage:         fileStorage,
+		emailService:    emailService,
+		config:          cfg,
+	}
+}
+
+// Request queues a new personal data export and starts building it in the background
+func (s *ExportService) Request(userID string) (*models.DataExport, error) {
+	unfinished, err := s.exportRepo.HasUnfinished(userID)
+	if err != nil {
+		return nil, err
+	}
+	if unfinished {
+		return nil, errors.New("an export is already in progress")
+	}
+
+	export := &models.DataExport{UserID: userID}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, err
+	}
+
+	go s.process(export.ID, userID)
+
+	return export, nil
+}
+
+func (s *ExportService) GetAll(userID string) ([]models.DataExport, error) {
+	exports, err := s.exportRepo.FindAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range exports {
+		s.attachDownloadURL(&exports[i])
+	}
+
+	return exports, nil
+}
+
+func (s *ExportService) GetByID(id, userID string) (*models.DataExport, error) {
+	export, err := s.exportRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachDownloadURL(export)
+	return export, nil
+}
+
+// OpenDownload validates a signed download link and opens the export archive
+func (s *ExportService) OpenDownload(id, token string) (*os.File, string, error) {
+	export, err := s.exportRepo.FindByIDUnscoped(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.checkDownload(export, token, time.Now()); err != nil {
+		return nil, "", err
+	}
+
+	f, err := s.storage.Open(*export.FileKey)
+	if err != nil {
+		return nil, "", errors.New("export file not found")
+	}
+
+	filename := fmt.Sprintf("emasgo-export-%s.zip", export.CreatedAt.Format("20060102"))
+	return f, filename, nil
+}
+
+// checkDownload verifies that token is the signed download link of the completed export
+// and has not expired at now
+func (s *ExportService) checkDownload(export *models.DataExport, token string, now time.Time) error {
+	if export.Status != models.ExportStatusCompleted || export.FileKey == nil || export.ExpiresAt == nil {
+		return errors.New("export not found")
+	}
+	if now.After(*export.ExpiresAt) {
+		return errors.New("download link has expired")
+	}
+
+	expected := s.signDownload(export.ID, *export.ExpiresAt)
+	if !hmac.Equal([]byte(expected), []byte(token)) {
+		return errors.New("invalid download link")
+	}
+
+	return nil
+}
+
+// ResumeUnfinished restarts exports interrupted by a server restart. Every server
+// does this at startup; claiming the export lets only one of them build it.
+func (s *ExportService) ResumeUnfinished() {
+	exports, err := s.exportRepo.FindUnfinished()
+	if err != nil {
+		log.Printf("Error loading unfinished exports: %v", err)
+		return
+	}
+
+	for _, export := range exports {
+		go s.process(export.ID, export.UserID)
+	}
+}
+
+// StartExpiredCleanup starts a background goroutine that periodically removes
+// exports whose download link has expired
+func (s *ExportService) StartExpiredCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			exports, err := s.exportRepo.FindExpired(time.Now().UTC())
+			if err != nil {
+				log.Printf("Error loading expired exports: %v", err)
+				continue
+			}
+
+			for _, export := range exports {
+				if export.FileKey != nil {
+					if err := s.storage.Delete(*export.FileKey); err != nil {
+						log.Printf("Error deleting export file %s: %v", *export.FileKey, err)
+						continue
+					}
+				}
+				if err := s.exportRepo.Delete(export.ID); err != nil {
+					log.Printf("Error deleting export %s: %v", export.ID, err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *ExportService) process(exportID, userID string) {
+	claimed, err := s.exportRepo.Claim(exportID)
+	if err != nil {
+		log.Printf("Error starting export %s: %v", exportID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		s.fail(exportID, err)
+		return
+	}
+
+	fileKey := fmt.Sprintf("exports/%s/%s.zip", userID, exportID)
+	size, err := s.buildArchive(fileKey, user)
+	if err != nil {
+		s.storage.Delete(fileKey)
+		s.fail(exportID, err)
+		return
+	}
+
+	expiresAt := s.linkExpiry(time.Now())
+	if err := s.exportRepo.MarkCompleted(exportID, fileKey, size, expiresAt); err != nil {
+		s.fail(exportID, err)
+		return
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nYour EmasGo data export is ready. Download it here:\n\n%s\n\nThe link expires on %s.\n",
+		user.FullName,
+		s.downloadURL(exportID, expiresAt),
+		expiresAt.Format("2 January 2006 15:04 MST"),
+	)
+	if err := s.emailService.Send(user.Email, "Your EmasGo data export is ready", body); err != nil {
+		log.Printf("Error notifying user %s about export %s: %v", userID, exportID, err)
+	}
+}
+
+// linkExpiry returns when a download link created at now expires. It is in UTC:
+// expires_at is stored without a time zone and read back as UTC, and the link
+// signature must match the value read back.
+func (s *ExportService) linkExpiry(now time.Time) time.Time {
+	return now.UTC().Add(s.config.ExportLinkExpiry).Truncate(time.Second)
+}
+
+func (s *ExportService) fail(exportID string, cause error) {
+	log.Printf("Export %s failed: %v", exportID, cause)
+	if err := s.exportRepo.MarkFailed(exportID, "Failed to build export"); err != nil {
+		log.Printf("Error marking export %s as failed: %v", exportID, err)
+	}
+}
+
+func (s *ExportService) buildArchive(fileKey string, user *models.User) (int64, error) {
+	settings, err := s.settingsRepo.FindByUserID(user.ID)
+	if err != nil {
+		return 0, err
+	}
+	pockets, err := s.pocketRepo.FindAllByUser(user.ID)
+	if err != nil {
+		return 0, err
+	}
+	transactions, err := s.transactionRepo.FindAllByUser(user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := s.storage.Create(fileKey)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	jsonFiles := map[string]interface{}{
+		"profile.json":      user,
+		"settings.json":     settings,
+		"pockets.json":      pockets,
+		"transactions.json": transactions,
+	}
+	for name, data := range jsonFiles {
+		if err := writeZipJSON(zw, name, data); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writeZipCSV(zw, "pockets.csv", pocketsCSV(pockets)); err != nil {
+		return 0, err
+	}
+
+	// Receipts are referenced from transactions.csv by their path inside the archive
+	receiptPaths := make(map[string]string)
+	for _, t := range transactions {
+		if t.ReceiptImage == nil || !s.storage.IsLocalKey(*t.ReceiptImage) {
+			continue
+		}
+
+		archivePath := "receipts/" + t.ID + filepath.Ext(*t.ReceiptImage)
+		if err := s.copyToZip(zw, archivePath, *t.ReceiptImage); err != nil {
+			log.Printf("Skipping receipt %s in export: %v", *t.ReceiptImage, err)
+			continue
+		}
+		receiptPaths[t.ID] = archivePath
+	}
+
+	if err := writeZipCSV(zw, "transactions.csv", transactionsCSV(transactions, receiptPaths)); err != nil {
+		return 0, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+func (s *ExportService) copyToZip(zw *zip.Writer, name, key string) error {
+	src, err := s.storage.Open(key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, src)
+	return err
+}
+
+func (s *ExportService) attachDownloadURL(export *models.DataExport) {
+	if export.Status != models.ExportStatusCompleted || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return
+	}
+
+	url := s.downloadURL(export.ID, *export.ExpiresAt)
+	export.DownloadURL = &url
+}
+
+func (s *ExportService) downloadURL(exportID string, expiresAt time.Time) string {
+	return fmt.Sprintf("%s/api/v1/exports/%s/download?token=%s", s.config.APIBaseURL, exportID, s.signDownload(exportID, expiresAt))
+}
+
+// signDownload derives the download token from the export ID and its expiry,
+// so links cannot be reused for other exports or extended
+func (s *ExportService) signDownload(exportID string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, []byte(s.config.JWTSecret))
+	mac.Write([]byte("export:" + exportID + ":" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func writeZipJSON(zw *zip.Writer, name string, data interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func writeZipCSV(zw *zip.Writer, name string, records [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func pocketsCSV(pockets []models.Pocket) [][]string {
+	records := [][]string{{
+		"id", "name", "type", "description", "target_weight",
+		"total_weight", "total_price", "created_at",
+	}}
+
+	for _, p := range pockets {
+		typeName := ""
+		if p.TypePocket != nil {
+			typeName = p.TypePocket.Name
+		}
+
+		records = append(records, []string{
+			p.ID,
+			p.Name,
+			typeName,
+			stringValue(p.Description),
+			decimalPtrValue(p.TargetWeight),
+			p.AggregateTotalWeight.String(),
+			p.AggregateTotalPrice.String(),
+			p.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return records
+}
+
+func transactionsCSV(transactions []models.Transaction, receiptPaths map[string]string) [][]string {
+	records := [][]string{{
+		"id", "transaction_date", "pocket", "brand", "weight",
+		"price_per_gram", "total_price", "total_fees", "total_cost", "description", "receipt",
+	}}
+
+	for _, t := range transactions {
+		pocketName := ""
+		if t.Pocket != nil {
+			pocketName = t.Pocket.Name
+		}
+
+		records = append(records, []string{
+			t.ID,
+			t.TransactionDate.Format("2006-01-02"),
+			pocketName,
+			t.Brand,
+			t.Weight.String(),
+			t.PricePerGram.String(),
+			t.TotalPrice.String(),
+			t.TotalFees.String(),
+			t.TotalCost.String(),
+			stringValue(t.Description),
+			receiptPaths[t.ID],
+		})
+	}
+
+	return records
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func decimalPtrValue(d *decimal.Decimal) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
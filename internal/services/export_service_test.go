@@ -0,0 +1,129 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/config"
+	"nabung-emas-api/internal/models"
+)
+
+func newTestExportService(secret string) *ExportService {
+	return NewExportService(nil, nil, nil, nil, nil, nil, nil, &config.Config{
+		APIBaseURL: "https://api.example.com",
+		JWTSecret:  secret,
+	})
+}
+
+func completedExport(id string, expiresAt time.Time) *models.DataExport {
+	fileKey := "exports/" + id + ".zip"
+	return &models.DataExport{ID: id, Status: models.ExportStatusCompleted, FileKey: &fileKey, ExpiresAt: &expiresAt}
+}
+
+// downloadToken returns the token of the export's download URL
+func downloadToken(t *testing.T, export *models.DataExport) string {
+	t.Helper()
+	if export.DownloadURL == nil {
+		t.Fatal("export has no download URL")
+	}
+	link, err := url.Parse(*export.DownloadURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/api/v1/exports/" + export.ID + "/download"; link.Path != want {
+		t.Errorf("download path = %s, want %s", link.Path, want)
+	}
+	return link.Query().Get("token")
+}
+
+func TestExportDownloadLink(t *testing.T) {
+	service := newTestExportService("secret")
+	now := time.Now().Truncate(time.Second)
+	expiresAt := now.Add(24 * time.Hour)
+
+	export := completedExport("e1", expiresAt)
+	service.attachDownloadURL(export)
+	if !strings.HasPrefix(*export.DownloadURL, "https://api.example.com/") {
+		t.Errorf("download URL = %s, want it on the API base URL", *export.DownloadURL)
+	}
+	token := downloadToken(t, export)
+
+	if err := service.checkDownload(export, token, now); err != nil {
+		t.Fatalf("checkDownload of the link: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		export  *models.DataExport
+		token   string
+		now     time.Time
+		wantErr string
+	}{
+		{"expired", export, token, expiresAt.Add(time.Second), "download link has expired"},
+		{"tampered token", export, strings.Repeat("0", len(token)), now, "invalid download link"},
+		{"empty token", export, "", now, "invalid download link"},
+		// A link is bound to its export and to its expiry
+		{"other export", completedExport("e2", expiresAt), token, now, "invalid download link"},
+		{"extended expiry", completedExport("e1", expiresAt.Add(time.Hour)), token, now, "invalid download link"},
+		{"other secret", export, newTestExportService("other").signDownload("e1", expiresAt), now, "invalid download link"},
+		{"not completed", &models.DataExport{ID: "e1", Status: models.ExportStatusProcessing, ExpiresAt: &expiresAt}, token, now, "export not found"},
+	}
+
+	for _, tt := range tests {
+		err := service.checkDownload(tt.export, tt.token, tt.now)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: checkDownload error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExportDownloadURLOnlyWhileValid(t *testing.T) {
+	service := newTestExportService("secret")
+
+	expired := completedExport("e1", time.Now().Add(-time.Minute))
+	service.attachDownloadURL(expired)
+	if expired.DownloadURL != nil {
+		t.Errorf("expired export has download URL %s", *expired.DownloadURL)
+	}
+
+	pending := &models.DataExport{ID: "e2", Status: models.ExportStatusPending}
+	service.attachDownloadURL(pending)
+	if pending.DownloadURL != nil {
+		t.Errorf("pending export has download URL %s", *pending.DownloadURL)
+	}
+}
+
+// The link in the email is signed before expires_at is stored. The column has no time
+// zone, so it must still verify when the server runs in WIB and the value is read back as UTC.
+func TestExportDownloadLinkOutsideUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("WIB", 7*60*60)
+	defer func() { time.Local = local }()
+
+	service := newTestExportService("secret")
+	service.config.ExportLinkExpiry = 24 * time.Hour
+
+	now := time.Now()
+	expiresAt := service.linkExpiry(now)
+	link, err := url.Parse(service.downloadURL("e1", expiresAt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What a TIMESTAMP column returns: the stored wall clock, in UTC
+	stored := time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(),
+		expiresAt.Hour(), expiresAt.Minute(), expiresAt.Second(), 0, time.UTC)
+	if !stored.Equal(expiresAt) {
+		t.Fatalf("stored expiry = %s, want %s", stored, expiresAt)
+	}
+
+	export := completedExport("e1", stored)
+	if err := service.checkDownload(export, link.Query().Get("token"), now); err != nil {
+		t.Fatalf("checkDownload of the emailed link: %v", err)
+	}
+	if err := service.checkDownload(export, link.Query().Get("token"), now.Add(25*time.Hour)); err == nil {
+		t.Error("checkDownload accepted the link after it expired")
+	}
+}
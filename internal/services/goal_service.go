@@ -0,0 +1,237 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/exchangerates"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
+)
+
+// goalHistoryMonths is the window of past purchases used to estimate the saving rate
+const goalHistoryMonths = 6
+
+type GoalService struct {
+	pocketRepo          *repositories.PocketRepository
+	analyticsRepo       *repositories.AnalyticsRepository
+	goldPriceRepo       *repositories.GoldPriceRepository
+	notificationService *NotificationService
+}
+
+func NewGoalService(
+	pocketRepo *repositories.PocketRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+	notificationService *NotificationService,
+) *GoalService {
+	return &GoalService{
+		pocketRepo:          pocketRepo,
+		analyticsRepo:       analyticsRepo,
+		goldPriceRepo:       goldPriceRepo,
+		notificationService: notificationService,
+	}
+}
+
+// GetAll evaluates every pocket of the user that has a savings target
+func (s *GoalService) GetAll(userID string) ([]models.PocketGoal, error) {
+	pockets, err := s.pocketRepo.FindWithGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	goals := []models.PocketGoal{}
+	for i := range pockets {
+		goal, err := s.Evaluate(&pockets[i])
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, *goal)
+	}
+
+	return goals, nil
+}
+
+// Evaluate computes progress, the monthly rate needed to meet the target date and a
+// projected completion date based on the pocket's recent purchase history.
+// It returns nil when the pocket has no target.
+func (s *GoalService) Evaluate(pocket *models.Pocket) (*models.PocketGoal, error) {
+	if pocket.TargetWeight == nil && pocket.TargetAmount == nil {
+		return nil, nil
+	}
+
+	goal := &models.PocketGoal{
+		PocketID:      pocket.ID,
+		PocketName:    pocket.Name,
+		TargetWeight:  pocket.TargetWeight,
+		TargetAmount:  pocket.TargetAmount,
+		TargetDate:    pocket.TargetDate,
+		CurrentWeight: pocket.AggregateTotalWeight,
+		CurrentAmount: pocket.AggregateTotalCost,
+	}
+
+	monthly, err := s.analyticsRepo.GetMonthlyPurchases(pocket.UserID, exchangerates.Base, goalHistoryMonths, &pocket.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, data := range monthly {
+		goal.AverageMonthlyWeight = goal.AverageMonthlyWeight.Add(data.Weight)
+		goal.AverageMonthlyAmount = goal.AverageMonthlyAmount.Add(data.Amount)
+	}
+	if goal.AverageMonthlyWeight, err = goal.AverageMonthlyWeight.DivInt(goalHistoryMonths); err != nil {
+		return nil, err
+	}
+	if goal.AverageMonthlyAmount, err = goal.AverageMonthlyAmount.DivInt(goalHistoryMonths); err != nil {
+		return nil, err
+	}
+	goal.AverageMonthlyWeight = goal.AverageMonthlyWeight.Round(decimal.WeightPlaces)
+	goal.AverageMonthlyAmount = goal.AverageMonthlyAmount.Round(decimal.MoneyPlaces)
+
+	// Weight targets take precedence when both are set
+	var target, current, rate decimal.Decimal
+	if pocket.TargetWeight != nil {
+		goal.TargetType = models.GoalTargetWeight
+		target, current, rate = *goal.TargetWeight, goal.CurrentWeight, goal.AverageMonthlyWeight
+		goal.RemainingWeight = target.Sub(current).Max(decimal.Zero)
+	} else {
+		goal.TargetType = models.GoalTargetAmount
+		target, current, rate = *goal.TargetAmount, goal.CurrentAmount, goal.AverageMonthlyAmount
+		remainingAmount := target.Sub(current).Max(decimal.Zero)
+		goal.RemainingAmount = &remainingAmount
+		if price := s.referencePrice(pocket); price.IsPositive() {
+			remainingWeight, err := remainingAmount.Div(price)
+			if err != nil {
+				return nil, err
+			}
+			goal.RemainingWeight = remainingWeight.Round(decimal.WeightPlaces)
+		}
+	}
+
+	goal.ProgressPercentage = roundTo(math.Min(current.Ratio(target)*100, 100), 2)
+	goal.Reached = !current.LessThan(target)
+
+	now := today()
+	if goal.Reached {
+		return goal, nil
+	}
+
+	if pocket.TargetDate != nil {
+		months := monthsUntil(now, *pocket.TargetDate)
+		goal.MonthsRemaining = &months
+
+		divisor := int64(months)
+		if divisor < 1 {
+			divisor = 1
+		}
+		requiredWeight, err := goal.RemainingWeight.DivInt(divisor)
+		if err != nil {
+			return nil, err
+		}
+		requiredWeight = requiredWeight.Round(decimal.WeightPlaces)
+		goal.RequiredMonthlyWeight = &requiredWeight
+		if goal.RemainingAmount != nil {
+			requiredAmount, err := goal.RemainingAmount.DivInt(divisor)
+			if err != nil {
+				return nil, err
+			}
+			requiredAmount = requiredAmount.Round(decimal.MoneyPlaces)
+			goal.RequiredMonthlyAmount = &requiredAmount
+		}
+	}
+
+	if rate.IsPositive() {
+		monthsNeeded := int(math.Ceil(target.Sub(current).Ratio(rate)))
+		projected := addMonthsClamped(now, monthsNeeded)
+		goal.ProjectedCompletionDate = &projected
+
+		if pocket.TargetDate != nil {
+			onTrack := !projected.After(*pocket.TargetDate)
+			goal.OnTrack = &onTrack
+		}
+	} else if pocket.TargetDate != nil {
+		onTrack := false
+		goal.OnTrack = &onTrack
+	}
+
+	return goal, nil
+}
+
+// CheckReached notifies the user when the pocket's goal has just been reached. It is
+// called after changes to the pocket's transactions or target; failures are logged.
+func (s *GoalService) CheckReached(pocketID, userID string) {
+	pocket, err := s.pocketRepo.FindByID(pocketID, userID)
+	if err != nil {
+		return
+	}
+
+	goal, err := s.Evaluate(pocket)
+	if err != nil {
+		log.Printf("Error evaluating goal of pocket %s: %v", pocketID, err)
+		return
+	}
+	reached := goal != nil && goal.Reached
+
+	changed, err := s.pocketRepo.SetGoalReached(pocket.ID, reached)
+	if err != nil {
+		log.Printf("Error recording goal of pocket %s: %v", pocketID, err)
+		return
+	}
+	if !changed || !reached {
+		return
+	}
+
+	// Announced in the background, so the change that reached the goal is not held up
+	// by push delivery
+	go s.notificationService.Notify(Notice{
+		UserID: userID,
+		Type:   models.NotificationGoalReached,
+		Data:   map[string]string{"pocket_id": pocket.ID},
+		Text: func(language string) (string, string) {
+			var target string
+			if goal.TargetType == models.GoalTargetWeight {
+				target = utils.FormatDecimal(*goal.TargetWeight, 3, language) + " g"
+			} else {
+				target = "Rp " + utils.FormatDecimal(*goal.TargetAmount, 0, language)
+			}
+
+			if language == "id" {
+				return "Target tabungan tercapai", fmt.Sprintf("Kantong %s telah mencapai target %s.", pocket.Name, target)
+			}
+			return "Savings goal reached", fmt.Sprintf("Your pocket %s reached its goal of %s.", pocket.Name, target)
+		},
+	})
+}
+
+// referencePrice converts rupiah targets into grams, preferring the latest stored
+// market price and falling back to the pocket's average purchase price
+func (s *GoalService) referencePrice(pocket *models.Pocket) decimal.Decimal {
+	if price, err := s.goldPriceRepo.FindOnOrBefore(time.Now(), ""); err == nil {
+		return decimal.NewFromFloat(price.PricePerGram).Round(decimal.MoneyPlaces)
+	}
+	price, err := pocket.AggregateTotalPrice.Div(pocket.AggregateTotalWeight)
+	if err != nil {
+		return decimal.Zero
+	}
+	return price
+}
+
+// monthsUntil counts whole calendar months between two dates, never negative
+func monthsUntil(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
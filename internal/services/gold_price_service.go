@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+type GoldPriceService struct {
+	goldPriceRepo     *repositories.GoldPriceRepository
+	priceAlertService *PriceAlertService
+	streamService     *StreamService
+}
+
+func NewGoldPriceService(
+	goldPriceRepo *repositories.GoldPriceRepository,
+	priceAlertService *PriceAlertService,
+	streamService *StreamService,
+) *GoldPriceService {
+	return &GoldPriceService{
+		goldPriceRepo:     goldPriceRepo,
+		priceAlertService: priceAlertService,
+		streamService:     streamService,
+	}
+}
+
+// Store saves daily gold prices, replacing those already stored for the same day and
+// source. Each source's newest price, when this request stored it, is sent to connected
+// clients and price alerts are evaluated against it in the background.
+func (s *GoldPriceService) Store(req *models.StoreGoldPricesRequest) (*models.StoreGoldPricesResult, error) {
+	prices := make([]models.GoldPrice, 0, len(req.Prices))
+	for i, input := range req.Prices {
+		date, err := time.Parse("2006-01-02", input.Date)
+		if err != nil {
+			return nil, fmt.Errorf("price %d: invalid date format, use YYYY-MM-DD", i+1)
+		}
+		if date.After(today()) {
+			return nil, fmt.Errorf("price %d: date cannot be in the future", i+1)
+		}
+		prices = append(prices, models.GoldPrice{
+			Date:         date,
+			PricePerGram: input.PricePerGram,
+			Source:       input.Source,
+		})
+	}
+
+	if err := s.goldPriceRepo.Upsert(prices); err != nil {
+		return nil, errors.New("failed to store gold prices")
+	}
+
+	// The newest price of each source in the request, the last given for a day
+	newest := make(map[string]int)
+	var sources []string
+	for i, price := range prices {
+		j, ok := newest[price.Source]
+		if !ok {
+			sources = append(sources, price.Source)
+		}
+		if !ok || !price.Date.Before(prices[j].Date) {
+			newest[price.Source] = i
+		}
+	}
+
+	result := &models.StoreGoldPricesResult{Stored: len(prices), Latest: []models.GoldPrice{}}
+	for _, source := range sources {
+		price := prices[newest[source]]
+		latestDate, err := s.goldPriceRepo.FindLatestDate(source)
+		if err != nil {
+			return nil, err
+		}
+		if latestDate != nil && latestDate.After(price.Date) {
+			continue
+		}
+		result.Latest = append(result.Latest, price)
+	}
+
+	latest := result.Latest
+	go func() {
+		for _, price := range latest {
+			s.streamService.PublishGoldPrice(price)
+			s.priceAlertService.Evaluate(price)
+		}
+	}()
+
+	return result, nil
+}
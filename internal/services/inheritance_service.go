@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/faraid"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
+)
+
+type InheritanceService struct {
+	pocketRepo    *repositories.PocketRepository
+	goldPriceRepo *repositories.GoldPriceRepository
+}
+
+func NewInheritanceService(pocketRepo *repositories.PocketRepository, goldPriceRepo *repositories.GoldPriceRepository) *InheritanceService {
+	return &InheritanceService{
+		pocketRepo:    pocketRepo,
+		goldPriceRepo: goldPriceRepo,
+	}
+}
+
+// Simulate distributes the user's gold (all pockets or a single pocket) among the given
+// heirs according to faraid, in grams and in rupiah at the stored gold price
+func (s *InheritanceService) Simulate(userID string, req *models.FaraidRequest) (*models.FaraidDistribution, error) {
+	result, err := faraid.Distribute(faraid.Heirs{
+		Husband:   req.Heirs.Husband,
+		Wives:     req.Heirs.Wives,
+		Sons:      req.Heirs.Sons,
+		Daughters: req.Heirs.Daughters,
+		Father:    req.Heirs.Father,
+		Mother:    req.Heirs.Mother,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := &models.FaraidDistribution{
+		Awl:    result.Awl,
+		Radd:   result.Radd,
+		Shares: []models.FaraidShare{},
+	}
+
+	if req.PocketID != nil && *req.PocketID != "" {
+		pocket, err := s.pocketRepo.FindByID(*req.PocketID, userID)
+		if err != nil {
+			return nil, err
+		}
+		distribution.PocketID = &pocket.ID
+		distribution.PocketName = &pocket.Name
+		distribution.TotalWeight = pocket.AggregateTotalWeight.Float64()
+	} else {
+		pockets, err := s.pocketRepo.FindAllByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, pocket := range pockets {
+			distribution.TotalWeight += pocket.AggregateTotalWeight.Float64()
+		}
+	}
+	distribution.TotalWeight = roundTo(distribution.TotalWeight, 3)
+
+	if req.GoldPrice != nil {
+		distribution.GoldPrice = req.GoldPrice
+	} else if price, err := s.goldPriceRepo.FindOnOrBefore(time.Now(), ""); err == nil {
+		distribution.GoldPrice = &price.PricePerGram
+	}
+	if distribution.GoldPrice != nil {
+		totalValue := roundTo(distribution.TotalWeight*(*distribution.GoldPrice), 2)
+		distribution.TotalValue = &totalValue
+	}
+
+	for _, share := range result.Shares {
+		fraction, _ := share.Fraction.Float64()
+		weight := distribution.TotalWeight * fraction
+
+		item := models.FaraidShare{
+			Heir:            share.Heir,
+			Count:           share.Count,
+			Basis:           share.Basis,
+			Fraction:        share.Fraction.RatString(),
+			Percentage:      roundTo(fraction*100, 4),
+			Weight:          roundTo(weight, 3),
+			WeightPerPerson: roundTo(weight/float64(share.Count), 3),
+		}
+		if distribution.GoldPrice != nil {
+			value := roundTo(weight*(*distribution.GoldPrice), 2)
+			valuePerPerson := roundTo(value/float64(share.Count), 2)
+			item.Value = &value
+			item.ValuePerPerson = &valuePerPerson
+		}
+
+		distribution.Shares = append(distribution.Shares, item)
+	}
+
+	unallocated, _ := result.Unallocated.Float64()
+	distribution.UnallocatedWeight = roundTo(distribution.TotalWeight*unallocated, 3)
+	distribution.Summary = faraidSummary(distribution, result.Unallocated)
+
+	return distribution, nil
+}
+
+// faraidSummary renders the distribution as plain text suitable for printing
+func faraidSummary(d *models.FaraidDistribution, unallocated *big.Rat) string {
+	var b strings.Builder
+
+	b.WriteString("FARAID DISTRIBUTION SIMULATION\n")
+	b.WriteString(strings.Repeat("=", 30) + "\n")
+	if d.PocketName != nil {
+		fmt.Fprintf(&b, "Pocket       : %s\n", *d.PocketName)
+	} else {
+		b.WriteString("Pocket       : All pockets\n")
+	}
+	fmt.Fprintf(&b, "Total gold   : %s g\n", utils.FormatNumber(d.TotalWeight, 3, "en"))
+	if d.GoldPrice != nil && d.TotalValue != nil {
+		fmt.Fprintf(&b, "Gold price   : Rp %s/g\n", utils.FormatNumber(*d.GoldPrice, 2, "en"))
+		fmt.Fprintf(&b, "Total value  : Rp %s\n", utils.FormatNumber(*d.TotalValue, 2, "en"))
+	}
+	b.WriteString("\n")
+
+	for _, share := range d.Shares {
+		fmt.Fprintf(&b, "%-8s x%d  %-11s %-7s %s g", share.Heir, share.Count, share.Basis, share.Fraction, utils.FormatNumber(share.Weight, 3, "en"))
+		if share.Value != nil {
+			fmt.Fprintf(&b, "  Rp %s", utils.FormatNumber(*share.Value, 2, "en"))
+		}
+		b.WriteString("\n")
+		if share.Count > 1 {
+			fmt.Fprintf(&b, "         each: %s g", utils.FormatNumber(share.WeightPerPerson, 3, "en"))
+			if share.ValuePerPerson != nil {
+				fmt.Fprintf(&b, "  Rp %s", utils.FormatNumber(*share.ValuePerPerson, 2, "en"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if d.Awl {
+		b.WriteString("\n'Awl applied: fixed shares exceeded the estate and were reduced proportionally.\n")
+	}
+	if d.Radd {
+		b.WriteString("\nRadd applied: the surplus was returned to the fixed-share heirs other than the spouse.\n")
+	}
+	if unallocated.Sign() > 0 {
+		fmt.Fprintf(&b, "\nUnallocated %s (%s g) passes to more distant relatives or the Baitul Mal.\n", unallocated.RatString(), utils.FormatNumber(d.UnallocatedWeight, 3, "en"))
+	}
+
+	b.WriteString("\nThis is a simulation for common cases only; consult a religious court or scholar for a binding ruling.\n")
+
+	return b.String()
+}
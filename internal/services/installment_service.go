@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
+)
+
+// installmentReminderDays is how long before the due date the user is reminded to pay
+const installmentReminderDays = 3
+
+type InstallmentService struct {
+	installmentRepo     *repositories.InstallmentRepository
+	pocketRepo          *repositories.PocketRepository
+	userRepo            *repositories.UserRepository
+	brandService        *BrandService
+	transactionService  *TransactionService
+	emailService        *EmailService
+	notificationService *NotificationService
+}
+
+func NewInstallmentService(
+	installmentRepo *repositories.InstallmentRepository,
+	pocketRepo *repositories.PocketRepository,
+	userRepo *repositories.UserRepository,
+	brandService *BrandService,
+	transactionService *TransactionService,
+	emailService *EmailService,
+	notificationService *NotificationService,
+) *InstallmentService {
+	return &InstallmentService{
+		installmentRepo:     installmentRepo,
+		pocketRepo:          pocketRepo,
+		userRepo:            userRepo,
+		brandService:        brandService,
+		transactionService:  transactionService,
+		emailService:        emailService,
+		notificationService: notificationService,
+	}
+}
+
+func (s *InstallmentService) GetAll(userID string, status *string) ([]models.InstallmentContract, error) {
+	contracts, err := s.installmentRepo.FindAll(userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contracts {
+		applyInstallmentTotals(&contracts[i])
+	}
+
+	return contracts, nil
+}
+
+// GetByID returns the contract with its full payment schedule
+func (s *InstallmentService) GetByID(id, userID string) (*models.InstallmentContract, error) {
+	contract, err := s.installmentRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.installmentRepo.FindSchedule(contract.ID)
+	if err != nil {
+		return nil, err
+	}
+	contract.Schedule = schedule
+	applyInstallmentTotals(contract)
+
+	return contract, nil
+}
+
+func (s *InstallmentService) Create(userID string, req *models.CreateInstallmentRequest) (*models.InstallmentContract, error) {
+	pocket, err := s.pocketRepo.FindByID(req.PocketID, userID)
+	if err != nil {
+		return nil, errors.New("pocket not found")
+	}
+
+	contractDate, err := time.Parse("2006-01-02", req.ContractDate)
+	if err != nil {
+		return nil, errors.New("invalid contract date format, use YYYY-MM-DD")
+	}
+	if contractDate.After(today()) {
+		return nil, errors.New("contract date cannot be in the future")
+	}
+
+	firstDueDate := addMonthsClamped(contractDate, 1)
+	if req.FirstDueDate != nil && *req.FirstDueDate != "" {
+		firstDueDate, err = time.Parse("2006-01-02", *req.FirstDueDate)
+		if err != nil {
+			return nil, errors.New("invalid first due date format, use YYYY-MM-DD")
+		}
+		if firstDueDate.Before(contractDate) {
+			return nil, errors.New("first due date cannot be before contract date")
+		}
+	}
+
+	cashPrice := roundTo(req.Weight*req.PricePerGram, 2)
+	if req.DownPayment >= cashPrice {
+		return nil, errors.New("down payment must be less than the gold price")
+	}
+	financed := cashPrice - req.DownPayment
+
+	// Providers quote either a flat margin on the financed amount or the monthly installment
+	var margin, monthly float64
+	switch {
+	case req.MonthlyInstallment != nil:
+		monthly = roundTo(*req.MonthlyInstallment, 2)
+		margin = roundTo(monthly*float64(req.TenorMonths)-financed, 2)
+		if margin < 0 {
+			return nil, errors.New("monthly installments do not cover the financed amount")
+		}
+	case req.MarginRate != nil:
+		margin = roundTo(financed*(*req.MarginRate)/100, 2)
+		monthly = math.Ceil((financed + margin) / float64(req.TenorMonths))
+	default:
+		return nil, errors.New("either margin rate or monthly installment is required")
+	}
+
+	brand, err := s.brandService.Resolve(req.Brand)
+	if err != nil {
+		return nil, err
+	}
+
+	contract := &models.InstallmentContract{
+		UserID:             userID,
+		PocketID:           pocket.ID,
+		Provider:           req.Provider,
+		Brand:              brand.Name,
+		BrandID:            brand.ID,
+		Weight:             req.Weight,
+		PricePerGram:       req.PricePerGram,
+		DownPayment:        req.DownPayment,
+		TenorMonths:        req.TenorMonths,
+		MarginAmount:       margin,
+		MonthlyInstallment: monthly,
+		ContractDate:       contractDate,
+		FirstDueDate:       firstDueDate,
+		Status:             models.InstallmentStatusActive,
+		Notes:              req.Notes,
+		Pocket:             &models.Pocket{ID: pocket.ID, Name: pocket.Name},
+	}
+
+	// Rounding up the monthly amount is settled on the last installment
+	remaining := roundTo(financed+margin, 2)
+	schedule := make([]models.InstallmentPayment, req.TenorMonths)
+	for i := range schedule {
+		amount := monthly
+		if i == len(schedule)-1 {
+			amount = remaining
+		}
+		remaining = roundTo(remaining-amount, 2)
+
+		schedule[i] = models.InstallmentPayment{
+			Sequence:  i + 1,
+			DueDate:   addMonthsClamped(firstDueDate, i),
+			AmountDue: amount,
+		}
+	}
+
+	if err := s.installmentRepo.Create(contract, schedule); err != nil {
+		return nil, err
+	}
+
+	contract.Schedule = schedule
+	contract.TotalPaid = contract.DownPayment
+	contract.NextDueDate = &schedule[0].DueDate
+	applyInstallmentTotals(contract)
+
+	return contract, nil
+}
+
+// RecordPayment pays the next outstanding installment. Paying the last one completes the
+// contract and records the gold as a transaction in the pocket.
+func (s *InstallmentService) RecordPayment(id, userID string, req *models.RecordInstallmentPaymentRequest) (*models.InstallmentContract, error) {
+	contract, err := s.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if contract.Status != models.InstallmentStatusActive {
+		return nil, errors.New("installment contract is not active")
+	}
+
+	paidAt, err := time.Parse("2006-01-02", req.PaidAt)
+	if err != nil {
+		return nil, errors.New("invalid paid date format, use YYYY-MM-DD")
+	}
+	if paidAt.After(today()) {
+		return nil, errors.New("paid date cannot be in the future")
+	}
+	if paidAt.Before(contract.ContractDate) {
+		return nil, errors.New("paid date cannot be before contract date")
+	}
+
+	var payment *models.InstallmentPayment
+	unpaid := 0
+	for i := range contract.Schedule {
+		if contract.Schedule[i].PaidAt != nil {
+			continue
+		}
+		if payment == nil {
+			payment = &contract.Schedule[i]
+		}
+		unpaid++
+	}
+	if payment == nil {
+		return nil, errors.New("all installments have been paid")
+	}
+
+	amount := payment.AmountDue
+	if req.Amount != nil {
+		amount = roundTo(*req.Amount, 2)
+	}
+	payment.PaidAmount = &amount
+	payment.PaidAt = &paidAt
+
+	var completion *models.Transaction
+	if unpaid == 1 {
+		completion, err = s.completionTransaction(userID, contract, paidAt, roundTo(contract.TotalPaid+amount, 2))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.installmentRepo.RecordPayment(payment, contract, completion); err != nil {
+		return nil, err
+	}
+	if completion != nil {
+		s.transactionService.created(completion)
+	}
+
+	return s.GetByID(id, userID)
+}
+
+// completionTransaction builds the purchase a completed contract records, under the same
+// rules as other transactions. The gold is priced at the contract's cash price and what
+// was paid on top of it, the margin, is a fee: it adds to the cost basis, not to the
+// price per gram.
+func (s *InstallmentService) completionTransaction(userID string, contract *models.InstallmentContract, paidAt time.Time, totalPaid float64) (*models.Transaction, error) {
+	description := fmt.Sprintf("Installment purchase from %s (%d months)", contract.Provider, contract.TenorMonths)
+	req := &models.CreateTransactionRequest{
+		PocketID:        contract.PocketID,
+		TransactionDate: paidAt.Format("2006-01-02"),
+		Brand:           contract.BrandID,
+		Weight:          contract.Weight,
+		PricePerGram:    contract.PricePerGram,
+		TotalPrice:      contract.CashPrice,
+		Description:     &description,
+	}
+
+	if margin := roundTo(totalPaid-contract.CashPrice, 2); margin > 0 {
+		marginDescription := "Installment margin"
+		req.Fees = []models.TransactionFeeRequest{
+			{FeeType: models.FeeTypeOther, Amount: margin, Description: &marginDescription},
+		}
+	} else if margin < 0 {
+		// Paid less than the cash price, e.g. after a discount from the provider
+		req.PricePerGram = roundTo(totalPaid/contract.Weight, 2)
+		req.TotalPrice = totalPaid
+	}
+
+	return s.transactionService.newTransactionFromRequest(userID, req)
+}
+
+func (s *InstallmentService) Cancel(id, userID string) error {
+	contract, err := s.installmentRepo.FindByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if contract.Status != models.InstallmentStatusActive {
+		return errors.New("only active installment contracts can be cancelled")
+	}
+
+	return s.installmentRepo.UpdateStatus(id, userID, models.InstallmentStatusCancelled)
+}
+
+func (s *InstallmentService) Delete(id, userID string) error {
+	return s.installmentRepo.Delete(id, userID)
+}
+
+// GetSummary totals active contracts; their gold is not yet part of the portfolio
+func (s *InstallmentService) GetSummary(userID string) (*models.InstallmentSummary, error) {
+	status := models.InstallmentStatusActive
+	contracts, err := s.GetAll(userID, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.InstallmentSummary{}
+	for _, contract := range contracts {
+		summary.ActiveContracts++
+		summary.Weight = summary.Weight.Add(decimal.NewFromFloat(contract.Weight).Round(decimal.WeightPlaces))
+		summary.TotalPayable = summary.TotalPayable.Add(decimal.NewFromFloat(contract.TotalPayable).Round(decimal.MoneyPlaces))
+		summary.TotalPaid = summary.TotalPaid.Add(decimal.NewFromFloat(contract.TotalPaid).Round(decimal.MoneyPlaces))
+		summary.RemainingAmount = summary.RemainingAmount.Add(decimal.NewFromFloat(contract.RemainingAmount).Round(decimal.MoneyPlaces))
+		if contract.NextDueDate != nil && (summary.NextDueDate == nil || contract.NextDueDate.Before(*summary.NextDueDate)) {
+			summary.NextDueDate = contract.NextDueDate
+		}
+	}
+
+	return summary, nil
+}
+
+// StartReminders starts a background goroutine that periodically reminds users
+// of installments approaching their due date
+func (s *InstallmentService) StartReminders(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		s.SendDueReminders()
+		for range ticker.C {
+			s.SendDueReminders()
+		}
+	}()
+}
+
+func (s *InstallmentService) SendDueReminders() {
+	now := today()
+
+	payments, err := s.installmentRepo.FindDueForReminder(now.AddDate(0, 0, installmentReminderDays))
+	if err != nil {
+		log.Printf("Error loading installments due for reminder: %v", err)
+		return
+	}
+
+	for i := range payments {
+		payment := &payments[i]
+
+		contract, err := s.installmentRepo.FindByID(payment.ContractID, payment.UserID)
+		if err != nil {
+			continue
+		}
+		user, err := s.userRepo.FindByID(payment.UserID)
+		if err != nil {
+			continue
+		}
+
+		overdue := payment.DueDate.Before(now)
+		subject := "Your gold installment is due soon"
+		if overdue {
+			subject = "Your gold installment is overdue"
+		}
+		message := fmt.Sprintf(
+			"Installment %d of %d of your %.3f g gold from %s, Rp %.0f, is due on %s.",
+			payment.Sequence, contract.TenorMonths, contract.Weight, contract.Provider, payment.AmountDue,
+			payment.DueDate.Format("2 January 2006"),
+		)
+		// Each channel is delivered on its own, so a failed email still leaves the
+		// in-app and push reminders
+		if s.notificationService.Preference(payment.UserID, models.NotificationInstallmentDue).Email {
+			body := fmt.Sprintf("Hi %s,\n\n%s\n", user.FullName, message)
+			if err := s.emailService.Send(user.Email, subject, body); err != nil {
+				log.Printf("Error sending installment reminder to user %s: %v", payment.UserID, err)
+			}
+		}
+
+		s.notificationService.Notify(Notice{
+			UserID: payment.UserID,
+			Type:   models.NotificationInstallmentDue,
+			Data:   map[string]string{"installment_id": contract.ID},
+			Text: func(language string) (string, string) {
+				if language != "id" {
+					return subject, message
+				}
+				title := "Cicilan emas Anda segera jatuh tempo"
+				if overdue {
+					title = "Cicilan emas Anda sudah lewat jatuh tempo"
+				}
+				return title, fmt.Sprintf(
+					"Cicilan ke-%d dari %d untuk emas %s g Anda di %s sebesar Rp %s jatuh tempo pada %s.",
+					payment.Sequence, contract.TenorMonths, utils.FormatNumber(contract.Weight, 3, language), contract.Provider,
+					utils.FormatNumber(payment.AmountDue, 0, language), utils.FormatDate(payment.DueDate, language),
+				)
+			},
+		})
+
+		if err := s.installmentRepo.MarkReminded(payment.ID); err != nil {
+			log.Printf("Error marking installment %s as reminded: %v", payment.ID, err)
+		}
+	}
+}
+
+func applyInstallmentTotals(contract *models.InstallmentContract) {
+	contract.CashPrice = roundTo(contract.Weight*contract.PricePerGram, 2)
+	contract.TotalPayable = roundTo(contract.CashPrice+contract.MarginAmount, 2)
+	contract.TotalPaid = roundTo(contract.TotalPaid, 2)
+	contract.RemainingAmount = 0
+	if contract.Status == models.InstallmentStatusActive {
+		contract.RemainingAmount = roundTo(math.Max(contract.TotalPayable-contract.TotalPaid, 0), 2)
+	}
+}
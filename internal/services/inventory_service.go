@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+type InventoryService struct {
+	inventoryRepo   *repositories.InventoryRepository
+	pocketRepo      *repositories.PocketRepository
+	transactionRepo *repositories.TransactionRepository
+	brandService    *BrandService
+}
+
+func NewInventoryService(
+	inventoryRepo *repositories.InventoryRepository,
+	pocketRepo *repositories.PocketRepository,
+	transactionRepo *repositories.TransactionRepository,
+	brandService *BrandService,
+) *InventoryService {
+	return &InventoryService{
+		inventoryRepo:   inventoryRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+		brandService:    brandService,
+	}
+}
+
+func (s *InventoryService) Search(userID string, q, brand, pocketID, location *string, page, limit int) ([]models.InventoryItem, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	return s.inventoryRepo.Search(userID, q, brand, pocketID, location, page, limit)
+}
+
+func (s *InventoryService) GetByID(id, userID string) (*models.InventoryItem, error) {
+	return s.inventoryRepo.FindByID(id, userID)
+}
+
+func (s *InventoryService) GetLocations(userID string) ([]models.StorageLocationSummary, error) {
+	return s.inventoryRepo.GetLocations(userID)
+}
+
+func (s *InventoryService) Create(userID string, req *models.CreateInventoryItemRequest) (*models.InventoryItem, error) {
+	if _, err := s.pocketRepo.FindByID(req.PocketID, userID); err != nil {
+		return nil, errors.New("pocket not found")
+	}
+
+	brand, err := s.brandService.Resolve(req.Brand)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TransactionID != nil && *req.TransactionID != "" {
+		transaction, err := s.transactionRepo.FindByID(*req.TransactionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if transaction.PocketID != req.PocketID || transaction.BrandID != brand.ID {
+			return nil, errors.New("item pocket and brand must match the transaction")
+		}
+		if err := s.checkTransactionWeight(transaction, req.PieceWeight); err != nil {
+			return nil, err
+		}
+	}
+
+	item := &models.InventoryItem{
+		UserID:          userID,
+		PocketID:        req.PocketID,
+		TransactionID:   req.TransactionID,
+		Brand:           brand.Name,
+		BrandID:         brand.ID,
+		SerialNumber:    normalizeSerial(req.SerialNumber),
+		PieceWeight:     req.PieceWeight,
+		Packaging:       req.Packaging,
+		StorageLocation: req.StorageLocation,
+		Notes:           req.Notes,
+	}
+
+	if err := s.checkSerial(item, ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.inventoryRepo.CreateBatch([]*models.InventoryItem{item}); err != nil {
+		return nil, err
+	}
+
+	return s.inventoryRepo.FindByID(item.ID, userID)
+}
+
+// CreateFromTransaction splits a purchase into physical pieces, e.g. 5g x 2, taking the
+// pocket and brand from the transaction. Serial numbers are assigned to pieces in order.
+func (s *InventoryService) CreateFromTransaction(transactionID, userID string, req *models.CreateTransactionInventoryRequest) ([]models.InventoryItem, error) {
+	transaction, err := s.transactionRepo.FindByID(transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*models.InventoryItem
+	var totalWeight float64
+	seen := make(map[string]bool)
+
+	for i, piece := range req.Pieces {
+		if len(piece.SerialNumbers) > piece.Quantity {
+			return nil, fmt.Errorf("pieces[%d]: more serial numbers than pieces", i)
+		}
+
+		for n := 0; n < piece.Quantity; n++ {
+			item := &models.InventoryItem{
+				UserID:          userID,
+				PocketID:        transaction.PocketID,
+				TransactionID:   &transaction.ID,
+				Brand:           transaction.Brand,
+				BrandID:         transaction.BrandID,
+				PieceWeight:     piece.PieceWeight,
+				Packaging:       piece.Packaging,
+				StorageLocation: piece.StorageLocation,
+			}
+			if n < len(piece.SerialNumbers) {
+				item.SerialNumber = normalizeSerial(&piece.SerialNumbers[n])
+			}
+
+			if item.SerialNumber != nil {
+				if seen[*item.SerialNumber] {
+					return nil, fmt.Errorf("serial number %s is listed more than once", *item.SerialNumber)
+				}
+				seen[*item.SerialNumber] = true
+
+				if err := s.checkSerial(item, ""); err != nil {
+					return nil, err
+				}
+			}
+
+			totalWeight += piece.PieceWeight
+			items = append(items, item)
+		}
+	}
+
+	if err := s.checkTransactionWeight(transaction, totalWeight); err != nil {
+		return nil, err
+	}
+
+	if err := s.inventoryRepo.CreateBatch(items); err != nil {
+		return nil, err
+	}
+
+	created := make([]models.InventoryItem, 0, len(items))
+	for _, item := range items {
+		if transaction.Pocket != nil {
+			item.Pocket = &models.Pocket{ID: transaction.Pocket.ID, Name: transaction.Pocket.Name}
+		}
+		created = append(created, *item)
+	}
+
+	return created, nil
+}
+
+func (s *InventoryService) Update(id, userID string, req *models.UpdateInventoryItemRequest) (*models.InventoryItem, error) {
+	item, err := s.inventoryRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PocketID != "" && req.PocketID != item.PocketID {
+		if _, err := s.pocketRepo.FindByID(req.PocketID, userID); err != nil {
+			return nil, errors.New("pocket not found")
+		}
+		item.PocketID = req.PocketID
+	}
+	if req.SerialNumber != nil {
+		// An empty string removes the serial number
+		item.SerialNumber = normalizeSerial(req.SerialNumber)
+		if err := s.checkSerial(item, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.Packaging != nil {
+		item.Packaging = req.Packaging
+	}
+	if req.StorageLocation != nil {
+		item.StorageLocation = req.StorageLocation
+	}
+	if req.Notes != nil {
+		item.Notes = req.Notes
+	}
+
+	if err := s.inventoryRepo.Update(item); err != nil {
+		return nil, err
+	}
+
+	return s.inventoryRepo.FindByID(item.ID, userID)
+}
+
+func (s *InventoryService) Delete(id, userID string) error {
+	return s.inventoryRepo.Delete(id, userID)
+}
+
+func (s *InventoryService) checkSerial(item *models.InventoryItem, excludeID string) error {
+	if item.SerialNumber == nil {
+		return nil
+	}
+
+	exists, err := s.inventoryRepo.SerialExists(item.UserID, item.Brand, *item.SerialNumber, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("a %s bar with serial number %s already exists", item.Brand, *item.SerialNumber)
+	}
+
+	return nil
+}
+
+// checkTransactionWeight prevents recording more pieces than the purchase weighed
+func (s *InventoryService) checkTransactionWeight(transaction *models.Transaction, additional float64) error {
+	recorded, err := s.inventoryRepo.GetTransactionWeight(transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	if roundTo(recorded+additional, 3) > transaction.Weight.Float64() {
+		return fmt.Errorf("pieces exceed the transaction weight (%.3f g recorded of %s g)", recorded, transaction.Weight.StringFixed(3))
+	}
+
+	return nil
+}
+
+// normalizeSerial trims and upper-cases serial numbers so the same certificate
+// cannot be entered twice with different spacing or case
+func normalizeSerial(serial *string) *string {
+	if serial == nil {
+		return nil
+	}
+
+	normalized := strings.ToUpper(strings.TrimSpace(*serial))
+	if normalized == "" {
+		return nil
+	}
+
+	return &normalized
+}
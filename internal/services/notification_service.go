@@ -0,0 +1,264 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/push"
+	"nabung-emas-api/internal/repositories"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+)
+
+// notificationPublisher is implemented by StreamService
+type notificationPublisher interface {
+	PublishNotification(notification models.Notification, unreadCount int)
+}
+
+type NotificationService struct {
+	notificationRepo notificationStore
+	settingsRepo     settingsStore
+	pushService      *PushService
+	streamService    notificationPublisher
+}
+
+func NewNotificationService(
+	notificationRepo *repositories.NotificationRepository,
+	settingsRepo *repositories.SettingsRepository,
+	pushService *PushService,
+	streamService *StreamService,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		settingsRepo:     settingsRepo,
+		pushService:      pushService,
+		streamService:    streamService,
+	}
+}
+
+// Notice is an event to notify a user of. Text renders its title and body in a
+// language, so every device receives it in its own language.
+type Notice struct {
+	UserID string
+	Type   string
+	Data   map[string]string
+	Text   func(language string) (title, body string)
+	// SkipInApp and SkipPush leave out channels turned off for the event itself, e.g.
+	// on a price alert
+	SkipInApp bool
+	SkipPush  bool
+}
+
+// Notify adds the notice to the user's notification center, which also sends it to the
+// user's connected clients, and pushes it to the user's devices, as allowed by the
+// user's preferences for its type and, for push, the push notifications setting.
+// Failures are logged, so the event that caused the notice still completes. It
+// reports the channels the notice went out through.
+func (s *NotificationService) Notify(notice Notice) (stored, pushed bool) {
+	preference := s.Preference(notice.UserID, notice.Type)
+
+	language, pushEnabled := "en", false
+	if settings, err := s.settingsRepo.FindByUserID(notice.UserID); err == nil {
+		language = settings.Language
+		pushEnabled = settings.PushNotifications
+	}
+
+	data := map[string]string{"type": notice.Type}
+	for key, value := range notice.Data {
+		data[key] = value
+	}
+
+	if !notice.SkipInApp && preference.InApp {
+		title, body := notice.Text(language)
+		notification := &models.Notification{
+			UserID: notice.UserID,
+			Type:   notice.Type,
+			Title:  title,
+			Body:   body,
+			Data:   notice.Data,
+		}
+		if err := s.notificationRepo.Create(notification); err != nil {
+			log.Printf("Error creating %s notification for user %s: %v", notice.Type, notice.UserID, err)
+		} else {
+			stored = true
+			data["notification_id"] = notification.ID
+			s.publish(*notification)
+		}
+	}
+
+	if !notice.SkipPush && preference.Push && pushEnabled {
+		pushed = s.pushService.Send(notice.UserID, language, func(language string) push.Message {
+			title, body := notice.Text(language)
+			return push.Message{Title: title, Body: body, Data: data}
+		})
+	}
+
+	return stored, pushed
+}
+
+// publish sends the new notification to the user's connected clients
+func (s *NotificationService) publish(notification models.Notification) {
+	unreadCount, err := s.notificationRepo.CountUnread(notification.UserID)
+	if err != nil {
+		log.Printf("Error counting unread notifications of user %s: %v", notification.UserID, err)
+		return
+	}
+	s.streamService.PublishNotification(notification, unreadCount)
+}
+
+// Preference returns the channels the user receives notifications of the type through.
+// Every channel is on until the user changes it.
+func (s *NotificationService) Preference(userID, notificationType string) models.NotificationPreference {
+	preference := models.NotificationPreference{Type: notificationType, InApp: true, Email: true, Push: true}
+
+	preferences, err := s.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		log.Printf("Error loading notification preferences of user %s: %v", userID, err)
+		return preference
+	}
+	if stored, ok := preferences[notificationType]; ok {
+		return stored
+	}
+
+	return preference
+}
+
+// GetAll returns a page of the user's notifications, newest first, with the unread
+// count. cursor is the next_cursor of the previous page, empty for the first page.
+func (s *NotificationService) GetAll(userID, cursor string, limit int, unreadOnly bool) (*models.NotificationList, error) {
+	if limit < 1 {
+		limit = defaultNotificationPageSize
+	}
+	if limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+
+	var beforeTime *time.Time
+	var beforeID *string
+	if cursor != "" {
+		createdAt, id, err := decodeNotificationCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		beforeTime, beforeID = &createdAt, &id
+	}
+
+	// One extra row tells whether there is a next page
+	notifications, err := s.notificationRepo.FindPage(userID, beforeTime, beforeID, unreadOnly, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.NotificationList{Notifications: notifications}
+	if len(notifications) > limit {
+		list.Notifications = notifications[:limit]
+		last := list.Notifications[limit-1]
+		list.NextCursor = encodeNotificationCursor(last.CreatedAt, last.ID)
+	}
+
+	list.UnreadCount, err = s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *NotificationService) MarkRead(id, userID string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return errors.New("notification not found")
+	}
+	return s.notificationRepo.MarkRead(id, userID)
+}
+
+func (s *NotificationService) MarkAllRead(userID string) (int, error) {
+	return s.notificationRepo.MarkAllRead(userID)
+}
+
+// GetPreferences returns the preferences of every notification type
+func (s *NotificationService) GetPreferences(userID string) ([]models.NotificationPreference, error) {
+	stored, err := s.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preference, ok := stored[notificationType]
+		if !ok {
+			preference = models.NotificationPreference{Type: notificationType, InApp: true, Email: true, Push: true}
+		}
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences changes the given channels of the given types, keeping the others
+func (s *NotificationService) UpdatePreferences(userID string, req *models.UpdateNotificationPreferencesRequest) ([]models.NotificationPreference, error) {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*models.NotificationPreference)
+	for i := range preferences {
+		byType[preferences[i].Type] = &preferences[i]
+	}
+
+	var changed []models.NotificationPreference
+	for _, input := range req.Preferences {
+		preference := byType[input.Type]
+		if input.InApp != nil {
+			preference.InApp = *input.InApp
+		}
+		if input.Email != nil {
+			preference.Email = *input.Email
+		}
+		if input.Push != nil {
+			preference.Push = *input.Push
+		}
+		changed = append(changed, *preference)
+	}
+
+	if err := s.notificationRepo.UpsertPreferences(userID, changed); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// The cursor is the creation time and ID of the last notification of a page
+func encodeNotificationCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeNotificationCursor(cursor string) (time.Time, string, error) {
+	invalid := errors.New("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", invalid
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return time.Time{}, "", invalid
+	}
+
+	return createdAt, parts[1], nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/push"
+)
+
+// fakeNotificationStore keeps notifications and preferences in memory
+type fakeNotificationStore struct {
+	notifications []models.Notification
+	preferences   map[string]map[string]models.NotificationPreference
+}
+
+func (f *fakeNotificationStore) Create(notification *models.Notification) error {
+	notification.ID = fmt.Sprintf("n%d", len(f.notifications)+1)
+	notification.CreatedAt = time.Now()
+	f.notifications = append(f.notifications, *notification)
+	return nil
+}
+
+func (f *fakeNotificationStore) FindPage(userID string, beforeTime *time.Time, beforeID *string, unreadOnly bool, limit int) ([]models.Notification, error) {
+	return nil, nil
+}
+
+func (f *fakeNotificationStore) CountUnread(userID string) (int, error) {
+	count := 0
+	for _, notification := range f.notifications {
+		if notification.UserID == userID && !notification.Read {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeNotificationStore) MarkRead(id, userID string) error {
+	return errors.New("notification not found")
+}
+
+func (f *fakeNotificationStore) MarkAllRead(userID string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeNotificationStore) FindPreferences(userID string) (map[string]models.NotificationPreference, error) {
+	preferences := make(map[string]models.NotificationPreference)
+	for notificationType, preference := range f.preferences[userID] {
+		preferences[notificationType] = preference
+	}
+	return preferences, nil
+}
+
+func (f *fakeNotificationStore) UpsertPreferences(userID string, preferences []models.NotificationPreference) error {
+	if f.preferences == nil {
+		f.preferences = make(map[string]map[string]models.NotificationPreference)
+	}
+	if f.preferences[userID] == nil {
+		f.preferences[userID] = make(map[string]models.NotificationPreference)
+	}
+	for _, preference := range preferences {
+		f.preferences[userID][preference.Type] = preference
+	}
+	return nil
+}
+
+type fakeSettings map[string]*models.UserSettings
+
+func (f fakeSettings) FindByUserID(userID string) (*models.UserSettings, error) {
+	if settings, ok := f[userID]; ok {
+		return settings, nil
+	}
+	return nil, errors.New("settings not found")
+}
+
+// fakePublisher records the notifications sent to connected clients
+type fakePublisher struct {
+	unreadCounts []int
+}
+
+func (f *fakePublisher) PublishNotification(notification models.Notification, unreadCount int) {
+	f.unreadCounts = append(f.unreadCounts, unreadCount)
+}
+
+type notificationFixture struct {
+	service   *NotificationService
+	store     *fakeNotificationStore
+	devices   *fakeDeviceStore
+	sender    *push.FakeSender
+	publisher *fakePublisher
+}
+
+// newNotificationFixture sets up user u1 with Indonesian settings, push enabled and a
+// phone without a language of its own and a tablet in English
+func newNotificationFixture() *notificationFixture {
+	f := &notificationFixture{
+		store: &fakeNotificationStore{},
+		devices: &fakeDeviceStore{devices: []models.PushDevice{
+			{ID: "phone", UserID: "u1", Platform: push.PlatformFCM, Token: "phone"},
+			{ID: "tablet", UserID: "u1", Platform: push.PlatformAPNs, Token: "tablet", Language: stringPtr("en")},
+		}},
+		sender:    push.NewFakeSender(),
+		publisher: &fakePublisher{},
+	}
+
+	pushService := NewPushService(nil, f.sender)
+	pushService.deviceRepo = f.devices
+	f.service = NewNotificationService(nil, nil, pushService, nil)
+	f.service.notificationRepo = f.store
+	f.service.settingsRepo = fakeSettings{"u1": {UserID: "u1", Language: "id", PushNotifications: true}}
+	f.service.streamService = f.publisher
+
+	return f
+}
+
+func goalNotice(userID string) Notice {
+	return Notice{
+		UserID: userID,
+		Type:   models.NotificationGoalReached,
+		Data:   map[string]string{"pocket_id": "p1"},
+		Text: func(language string) (string, string) {
+			if language == "id" {
+				return "Target tercapai", "Target kantong Anda tercapai."
+			}
+			return "Goal reached", "Your pocket's goal was reached."
+		},
+	}
+}
+
+func TestNotifyLanguages(t *testing.T) {
+	f := newNotificationFixture()
+
+	stored, pushed := f.service.Notify(goalNotice("u1"))
+	if !stored || !pushed {
+		t.Fatalf("Notify = %v, %v; want stored and pushed", stored, pushed)
+	}
+
+	// The notification center uses the user's language
+	if len(f.store.notifications) != 1 || f.store.notifications[0].Title != "Target tercapai" {
+		t.Errorf("stored notifications = %+v, want one in Indonesian", f.store.notifications)
+	}
+	if len(f.publisher.unreadCounts) != 1 || f.publisher.unreadCounts[0] != 1 {
+		t.Errorf("published unread counts = %v, want [1]", f.publisher.unreadCounts)
+	}
+
+	// Devices use their own language, or else the user's
+	titles := make(map[string]string)
+	for _, delivery := range f.sender.Deliveries() {
+		titles[delivery.Device.Token] = delivery.Message.Title
+		if delivery.Message.Data["type"] != models.NotificationGoalReached ||
+			delivery.Message.Data["pocket_id"] != "p1" ||
+			delivery.Message.Data["notification_id"] != f.store.notifications[0].ID {
+			t.Errorf("push data = %v", delivery.Message.Data)
+		}
+	}
+	if titles["phone"] != "Target tercapai" || titles["tablet"] != "Goal reached" {
+		t.Errorf("push titles = %v, want Indonesian on the phone and English on the tablet", titles)
+	}
+}
+
+func TestNotifyPreferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		preference *models.NotificationPreference
+		settings   *models.UserSettings
+		notice     func(Notice) Notice
+		wantStored bool
+		wantPushed bool
+	}{
+		{
+			name:       "every channel on by default",
+			wantStored: true,
+			wantPushed: true,
+		},
+		{
+			name:       "in-app off for the type",
+			preference: &models.NotificationPreference{Type: models.NotificationGoalReached, InApp: false, Email: true, Push: true},
+			wantPushed: true,
+		},
+		{
+			name:       "push off for the type",
+			preference: &models.NotificationPreference{Type: models.NotificationGoalReached, InApp: true, Email: true, Push: false},
+			wantStored: true,
+		},
+		{
+			// Turning off another type leaves this one on
+			name:       "other type off",
+			preference: &models.NotificationPreference{Type: models.NotificationPledgeDue},
+			wantStored: true,
+			wantPushed: true,
+		},
+		{
+			name:       "push notifications setting off",
+			settings:   &models.UserSettings{UserID: "u1", Language: "en", PushNotifications: false},
+			wantStored: true,
+		},
+		{
+			name:       "channels skipped by the notice",
+			notice:     func(n Notice) Notice { n.SkipInApp, n.SkipPush = true, true; return n },
+			wantStored: false,
+			wantPushed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newNotificationFixture()
+			if tt.preference != nil {
+				f.store.UpsertPreferences("u1", []models.NotificationPreference{*tt.preference})
+			}
+			if tt.settings != nil {
+				f.service.settingsRepo = fakeSettings{"u1": tt.settings}
+			}
+			notice := goalNotice("u1")
+			if tt.notice != nil {
+				notice = tt.notice(notice)
+			}
+
+			stored, pushed := f.service.Notify(notice)
+			if stored != tt.wantStored || pushed != tt.wantPushed {
+				t.Errorf("Notify = %v, %v; want %v, %v", stored, pushed, tt.wantStored, tt.wantPushed)
+			}
+			if got := len(f.store.notifications) > 0; got != tt.wantStored {
+				t.Errorf("notification stored = %v, want %v", got, tt.wantStored)
+			}
+			if got := len(f.sender.Deliveries()) > 0; got != tt.wantPushed {
+				t.Errorf("push sent = %v, want %v", got, tt.wantPushed)
+			}
+		})
+	}
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	f := newNotificationFixture()
+
+	off := false
+	preferences, err := f.service.UpdatePreferences("u1", &models.UpdateNotificationPreferencesRequest{
+		Preferences: []models.NotificationPreferenceInput{
+			{Type: models.NotificationPriceAlert, Push: &off},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(preferences) != len(models.NotificationTypes) {
+		t.Fatalf("got %d preferences, want one per type", len(preferences))
+	}
+	for _, preference := range preferences {
+		wantPush := preference.Type != models.NotificationPriceAlert
+		if !preference.InApp || !preference.Email || preference.Push != wantPush {
+			t.Errorf("%s: %+v, want only push off for price alerts", preference.Type, preference)
+		}
+	}
+
+	// Only the changed type is stored; the other channels of it are kept
+	stored := f.store.preferences["u1"]
+	if len(stored) != 1 || !stored[models.NotificationPriceAlert].InApp || stored[models.NotificationPriceAlert].Push {
+		t.Errorf("stored preferences = %+v", stored)
+	}
+	if got := f.service.Preference("u1", models.NotificationPriceAlert); got.Push || !got.Email {
+		t.Errorf("Preference = %+v, want push off", got)
+	}
+}
+
+func TestNotificationCursor(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 8, 30, 15, 123456000, time.UTC)
+	id := "0b7e2f3c-5d1a-4c8e-9f6b-2a3d4e5f6a7b"
+
+	gotTime, gotID, err := decodeNotificationCursor(encodeNotificationCursor(createdAt, id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotTime.Equal(createdAt) || gotID != id {
+		t.Errorf("decoded %s, %s; want %s, %s", gotTime, gotID, createdAt, id)
+	}
+
+	for _, cursor := range []string{"", "not base64!", encodeNotificationCursor(createdAt, "not-a-uuid")} {
+		if _, _, err := decodeNotificationCursor(cursor); err == nil {
+			t.Errorf("decodeNotificationCursor(%q) succeeded, want an error", cursor)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
+)
+
+// pledgeReminderDays is how long before the due date the user is reminded to redeem or extend
+const pledgeReminderDays = 7
+
+// defaultFeePeriodDays matches the 15-day "sewa modal" period used by Pegadaian
+const defaultFeePeriodDays = 15
+
+type PledgeService struct {
+	pledgeRepo          *repositories.PledgeRepository
+	pocketRepo          *repositories.PocketRepository
+	transactionRepo     *repositories.TransactionRepository
+	userRepo            *repositories.UserRepository
+	emailService        *EmailService
+	notificationService *NotificationService
+}
+
+func NewPledgeService(
+	pledgeRepo *repositories.PledgeRepository,
+	pocketRepo *repositories.PocketRepository,
+	transactionRepo *repositories.TransactionRepository,
+	userRepo *repositories.UserRepository,
+	emailService *EmailService,
+	notificationService *NotificationService,
+) *PledgeService {
+	return &PledgeService{
+		pledgeRepo:          pledgeRepo,
+		pocketRepo:          pocketRepo,
+		transactionRepo:     transactionRepo,
+		userRepo:            userRepo,
+		emailService:        emailService,
+		notificationService: notificationService,
+	}
+}
+
+func (s *PledgeService) GetAll(userID string, status *string) ([]models.GoldPledge, error) {
+	pledges, err := s.pledgeRepo.FindAll(userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pledges {
+		applyPledgeCosts(&pledges[i], today())
+	}
+
+	return pledges, nil
+}
+
+func (s *PledgeService) GetByID(id, userID string) (*models.GoldPledge, error) {
+	pledge, err := s.pledgeRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyPledgeCosts(pledge, today())
+	return pledge, nil
+}
+
+func (s *PledgeService) Create(userID string, req *models.CreatePledgeRequest) (*models.GoldPledge, error) {
+	pocket, err := s.pocketRepo.FindByID(req.PocketID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pledgedAt, err := time.Parse("2006-01-02", req.PledgedAt)
+	if err != nil {
+		return nil, errors.New("invalid pledged date format, use YYYY-MM-DD")
+	}
+	dueDate, err := time.Parse("2006-01-02", req.DueDate)
+	if err != nil {
+		return nil, errors.New("invalid due date format, use YYYY-MM-DD")
+	}
+	if pledgedAt.After(today()) {
+		return nil, errors.New("pledged date cannot be in the future")
+	}
+	if dueDate.Before(pledgedAt) {
+		return nil, errors.New("due date cannot be before pledged date")
+	}
+
+	// Only gold that is not already pledged can be pawned: in the pocket, against every
+	// pledge on it, and for a pledge of one transaction also in that transaction
+	pocketPledged, err := s.pledgeRepo.GetPledgedWeight(pocket.ID, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	available := pocket.AggregateTotalWeight.Sub(pocketPledged)
+	if req.TransactionID != nil && *req.TransactionID != "" {
+		transaction, err := s.transactionRepo.FindByID(*req.TransactionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if transaction.PocketID != pocket.ID {
+			return nil, errors.New("transaction does not belong to this pocket")
+		}
+		transactionPledged, err := s.pledgeRepo.GetPledgedWeight(pocket.ID, req.TransactionID, "")
+		if err != nil {
+			return nil, err
+		}
+		available = available.Min(transaction.Weight.Sub(transactionPledged))
+	}
+	if decimal.NewFromFloat(req.Weight).Round(decimal.WeightPlaces).GreaterThan(available) {
+		return nil, fmt.Errorf("only %s g is available to pledge", available.Max(decimal.Zero).StringFixed(decimal.WeightPlaces))
+	}
+
+	feePeriodDays := req.FeePeriodDays
+	if feePeriodDays == 0 {
+		feePeriodDays = defaultFeePeriodDays
+	}
+
+	pledge := &models.GoldPledge{
+		UserID:        userID,
+		PocketID:      pocket.ID,
+		TransactionID: req.TransactionID,
+		Lender:        req.Lender,
+		Weight:        req.Weight,
+		LoanPrincipal: req.LoanPrincipal,
+		FeeRate:       req.FeeRate,
+		FeePeriodDays: feePeriodDays,
+		PledgedAt:     pledgedAt,
+		DueDate:       dueDate,
+		Status:        models.PledgeStatusActive,
+		Notes:         req.Notes,
+		Pocket:        &models.Pocket{ID: pocket.ID, Name: pocket.Name},
+	}
+
+	if err := s.pledgeRepo.Create(pledge); err != nil {
+		return nil, err
+	}
+
+	applyPledgeCosts(pledge, today())
+	return pledge, nil
+}
+
+func (s *PledgeService) Update(id, userID string, req *models.UpdatePledgeRequest) (*models.GoldPledge, error) {
+	pledge, err := s.pledgeRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pledge.Status != models.PledgeStatusActive {
+		return nil, errors.New("only active pledges can be updated")
+	}
+
+	if req.Lender != "" {
+		pledge.Lender = req.Lender
+	}
+	if req.LoanPrincipal != nil {
+		pledge.LoanPrincipal = *req.LoanPrincipal
+	}
+	if req.FeeRate != nil {
+		pledge.FeeRate = *req.FeeRate
+	}
+	if req.FeePeriodDays != nil {
+		pledge.FeePeriodDays = *req.FeePeriodDays
+	}
+	if req.DueDate != nil {
+		dueDate, err := time.Parse("2006-01-02", *req.DueDate)
+		if err != nil {
+			return nil, errors.New("invalid due date format, use YYYY-MM-DD")
+		}
+		if dueDate.Before(pledge.PledgedAt) {
+			return nil, errors.New("due date cannot be before pledged date")
+		}
+		if !dueDate.Equal(pledge.DueDate) {
+			// An extension gets its own reminder
+			pledge.RemindedAt = nil
+		}
+		pledge.DueDate = dueDate
+	}
+	if req.Notes != nil {
+		pledge.Notes = req.Notes
+	}
+
+	if err := s.pledgeRepo.Update(pledge); err != nil {
+		return nil, err
+	}
+
+	applyPledgeCosts(pledge, today())
+	return pledge, nil
+}
+
+// Redeem closes the loan and releases the gold. The redemption amount defaults to the
+// principal plus the fees accrued up to the redemption date.
+func (s *PledgeService) Redeem(id, userID string, req *models.RedeemPledgeRequest) (*models.GoldPledge, error) {
+	pledge, err := s.pledgeRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pledge.Status != models.PledgeStatusActive {
+		return nil, errors.New("pledge has already been redeemed")
+	}
+
+	redeemedAt := today()
+	if req.RedeemedAt != nil && *req.RedeemedAt != "" {
+		redeemedAt, err = time.Parse("2006-01-02", *req.RedeemedAt)
+		if err != nil {
+			return nil, errors.New("invalid redeemed date format, use YYYY-MM-DD")
+		}
+		if redeemedAt.Before(pledge.PledgedAt) {
+			return nil, errors.New("redeemed date cannot be before pledged date")
+		}
+		if redeemedAt.After(today()) {
+			return nil, errors.New("redeemed date cannot be in the future")
+		}
+	}
+
+	amount := pledge.LoanPrincipal + pledgeFee(pledge, redeemedAt)
+	if req.RedemptionAmount != nil {
+		amount = *req.RedemptionAmount
+	}
+	amount = roundTo(amount, 2)
+
+	pledge.Status = models.PledgeStatusRedeemed
+	pledge.RedeemedAt = &redeemedAt
+	pledge.RedemptionAmount = &amount
+
+	if err := s.pledgeRepo.Update(pledge); err != nil {
+		return nil, err
+	}
+
+	applyPledgeCosts(pledge, today())
+	return pledge, nil
+}
+
+func (s *PledgeService) Delete(id, userID string) error {
+	return s.pledgeRepo.Delete(id, userID)
+}
+
+// GetSummary totals the user's active pledges for the dashboard
+func (s *PledgeService) GetSummary(userID string) (*models.PledgeSummary, error) {
+	pledges, err := s.pledgeRepo.FindActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	summary := &models.PledgeSummary{}
+	for i := range pledges {
+		pledge := &pledges[i]
+		applyPledgeCosts(pledge, now)
+
+		summary.ActivePledges++
+		summary.PledgedWeight = summary.PledgedWeight.Add(decimal.NewFromFloat(pledge.Weight).Round(decimal.WeightPlaces))
+		summary.OutstandingLoan = summary.OutstandingLoan.Add(decimal.NewFromFloat(pledge.OutstandingLoan).Round(decimal.MoneyPlaces))
+		summary.OutstandingFees = summary.OutstandingFees.Add(decimal.NewFromFloat(pledge.AccruedFee).Round(decimal.MoneyPlaces))
+		if pledge.DueDate.Before(now) {
+			summary.OverduePledges++
+		}
+		if summary.NextDueDate == nil || pledge.DueDate.Before(*summary.NextDueDate) {
+			dueDate := pledge.DueDate
+			summary.NextDueDate = &dueDate
+		}
+	}
+
+	return summary, nil
+}
+
+// StartReminders starts a background goroutine that periodically reminds users
+// of pledges approaching their due date
+func (s *PledgeService) StartReminders(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		s.SendDueReminders()
+		for range ticker.C {
+			s.SendDueReminders()
+		}
+	}()
+}
+
+func (s *PledgeService) SendDueReminders() {
+	now := today()
+
+	pledges, err := s.pledgeRepo.FindDueForReminder(now.AddDate(0, 0, pledgeReminderDays))
+	if err != nil {
+		log.Printf("Error loading pledges due for reminder: %v", err)
+		return
+	}
+
+	for i := range pledges {
+		pledge := &pledges[i]
+		applyPledgeCosts(pledge, pledge.DueDate)
+
+		user, err := s.userRepo.FindByID(pledge.UserID)
+		if err != nil {
+			continue
+		}
+
+		subject := "Your gold pledge is due soon"
+		if pledge.DueDate.Before(now) {
+			subject = "Your gold pledge is overdue"
+		}
+		message := fmt.Sprintf(
+			"Your %.3f g pledge at %s is due on %s. Redeem or extend it to avoid losing the gold; the amount to redeem on the due date is about Rp %.0f.",
+			pledge.Weight, pledge.Lender, pledge.DueDate.Format("2 January 2006"), pledge.OutstandingLoan,
+		)
+		// Each channel is delivered on its own, so a failed email still leaves the
+		// in-app and push reminders
+		if s.notificationService.Preference(pledge.UserID, models.NotificationPledgeDue).Email {
+			body := fmt.Sprintf("Hi %s,\n\n%s\n", user.FullName, message)
+			if err := s.emailService.Send(user.Email, subject, body); err != nil {
+				log.Printf("Error sending pledge reminder to user %s: %v", pledge.UserID, err)
+			}
+		}
+
+		s.notificationService.Notify(Notice{
+			UserID: pledge.UserID,
+			Type:   models.NotificationPledgeDue,
+			Data:   map[string]string{"pledge_id": pledge.ID},
+			Text: func(language string) (string, string) {
+				if language != "id" {
+					return subject, message
+				}
+				title := "Gadai emas Anda segera jatuh tempo"
+				if pledge.DueDate.Before(now) {
+					title = "Gadai emas Anda sudah lewat jatuh tempo"
+				}
+				return title, fmt.Sprintf(
+					"Gadai %s g Anda di %s jatuh tempo pada %s. Tebus atau perpanjang agar emas tidak hilang; jumlah tebusan pada tanggal jatuh tempo sekitar Rp %s.",
+					utils.FormatNumber(pledge.Weight, 3, language), pledge.Lender, utils.FormatDate(pledge.DueDate, language),
+					utils.FormatNumber(pledge.OutstandingLoan, 0, language),
+				)
+			},
+		})
+
+		if err := s.pledgeRepo.MarkReminded(pledge.ID); err != nil {
+			log.Printf("Error marking pledge %s as reminded: %v", pledge.ID, err)
+		}
+	}
+}
+
+// pledgeFee is the fee accrued up to the given date: the fee rate of the principal for
+// every started fee period, with at least one period charged
+func pledgeFee(pledge *models.GoldPledge, until time.Time) float64 {
+	periodDays := pledge.FeePeriodDays
+	if periodDays <= 0 {
+		periodDays = defaultFeePeriodDays
+	}
+
+	days := int(until.Sub(pledge.PledgedAt).Hours() / 24)
+	periods := int(math.Ceil(float64(days) / float64(periodDays)))
+	if periods < 1 {
+		periods = 1
+	}
+
+	return roundTo(pledge.LoanPrincipal*pledge.FeeRate/100*float64(periods), 2)
+}
+
+func applyPledgeCosts(pledge *models.GoldPledge, asOf time.Time) {
+	if pledge.Status != models.PledgeStatusActive {
+		pledge.AccruedFee = 0
+		pledge.OutstandingLoan = 0
+		if pledge.RedemptionAmount != nil {
+			pledge.AccruedFee = roundTo(math.Max(*pledge.RedemptionAmount-pledge.LoanPrincipal, 0), 2)
+		}
+		return
+	}
+
+	pledge.AccruedFee = pledgeFee(pledge, asOf)
+	pledge.OutstandingLoan = roundTo(pledge.LoanPrincipal+pledge.AccruedFee, 2)
+}
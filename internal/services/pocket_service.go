@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"log"
+	"nabung-emas-api/internal/decimal"
 	"nabung-emas-api/internal/models"
 	"nabung-emas-api/internal/repositories"
 )
@@ -10,12 +11,16 @@ import (
 type PocketService struct {
 	pocketRepo     *repositories.PocketRepository
 	typePocketRepo *repositories.TypePocketRepository
+	goalService    *GoalService
+	streamService  *StreamService
 }
 
-func NewPocketService(pocketRepo *repositories.PocketRepository, typePocketRepo *repositories.TypePocketRepository) *PocketService {
+func NewPocketService(pocketRepo *repositories.PocketRepository, typePocketRepo *repositories.TypePocketRepository, goalService *GoalService, streamService *StreamService) *PocketService {
 	return &PocketService{
 		pocketRepo:     pocketRepo,
 		typePocketRepo: typePocketRepo,
+		goalService:    goalService,
+		streamService:  streamService,
 	}
 }
 
@@ -31,7 +36,18 @@ func (s *PocketService) GetAll(userID string, typePocketID *string, page, limit
 }
 
 func (s *PocketService) GetByID(id, userID string) (*models.Pocket, error) {
-	return s.pocketRepo.FindByID(id, userID)
+	pocket, err := s.pocketRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	goal, err := s.goalService.Evaluate(pocket)
+	if err != nil {
+		return nil, err
+	}
+	pocket.Goal = goal
+
+	return pocket, nil
 }
 
 func (s *PocketService) Create(userID string, req *models.CreatePocketRequest) (*models.Pocket, error) {
@@ -53,12 +69,23 @@ func (s *PocketService) Create(userID string, req *models.CreatePocketRequest) (
 		return nil, errors.New("pocket name already exists")
 	}
 
+	targetDate, err := parseOptionalDate(req.TargetDate, "target date")
+	if err != nil {
+		return nil, err
+	}
+	if targetDate != nil && targetDate.Before(today()) {
+		return nil, errors.New("target date cannot be in the past")
+	}
+
 	pocket := &models.Pocket{
 		UserID:       userID,
 		TypePocketID: req.TypePocketID,
 		Name:         req.Name,
 		Description:  req.Description,
-		TargetWeight: req.TargetWeight,
+		TargetWeight: decimalPtr(req.TargetWeight),
+		TargetAmount: decimalPtr(req.TargetAmount),
+		TargetDate:   targetDate,
+		ZakatExempt:  req.ZakatExempt,
 	}
 
 	if err := s.pocketRepo.Create(pocket); err != nil {
@@ -90,7 +117,24 @@ func (s *PocketService) Update(id, userID string, req *models.UpdatePocketReques
 		pocket.Description = req.Description
 	}
 	if req.TargetWeight != nil {
-		pocket.TargetWeight = req.TargetWeight
+		pocket.TargetWeight = decimalPtr(req.TargetWeight)
+	}
+	if req.TargetAmount != nil {
+		pocket.TargetAmount = decimalPtr(req.TargetAmount)
+	}
+	if req.TargetDate != nil {
+		// An empty string removes the deadline
+		targetDate, err := parseOptionalDate(req.TargetDate, "target date")
+		if err != nil {
+			return nil, err
+		}
+		if targetDate != nil && targetDate.Before(today()) {
+			return nil, errors.New("target date cannot be in the past")
+		}
+		pocket.TargetDate = targetDate
+	}
+	if req.ZakatExempt != nil {
+		pocket.ZakatExempt = *req.ZakatExempt
 	}
 
 	if req.TypePocketID != "" {
@@ -110,24 +154,39 @@ func (s *PocketService) Update(id, userID string, req *models.UpdatePocketReques
 		return nil, err
 	}
 
+	s.goalService.CheckReached(pocket.ID, userID)
+
 	return pocket, nil
 }
 
 func (s *PocketService) Delete(id, userID string) error {
-	return s.pocketRepo.Delete(id, userID)
+	if err := s.pocketRepo.Delete(id, userID); err != nil {
+		return err
+	}
+
+	// The pocket's transactions are removed with it
+	go s.streamService.PublishPortfolio(userID)
+
+	return nil
 }
 
-func (s *PocketService) GetStats(id, userID string, currentGoldPrice *float64) (*models.PocketStats, error) {
+func (s *PocketService) GetStats(id, userID string, currentGoldPrice *decimal.Decimal) (*models.PocketStats, error) {
 	stats, err := s.pocketRepo.GetStats(id, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate profit/loss if current price provided
-	if currentGoldPrice != nil && *currentGoldPrice > 0 && stats.TotalWeight > 0 {
-		currentValue := stats.TotalWeight * (*currentGoldPrice)
-		profitLoss := currentValue - stats.TotalValue
-		profitLossPercentage := (profitLoss / stats.TotalValue) * 100
+	stats.TotalFees = stats.TotalCost.Sub(stats.TotalValue)
+
+	// Calculate profit/loss against the cost including fees if current price provided
+	if currentGoldPrice != nil && currentGoldPrice.IsPositive() && stats.FineWeight.IsPositive() {
+		currentValue, err := stats.FineWeight.Mul(*currentGoldPrice)
+		if err != nil {
+			return nil, err
+		}
+		currentValue = currentValue.Round(decimal.MoneyPlaces)
+		profitLoss := currentValue.Sub(stats.TotalCost)
+		profitLossPercentage := profitLoss.Ratio(stats.TotalCost) * 100
 
 		stats.CurrentGoldPrice = currentGoldPrice
 		stats.CurrentValue = &currentValue
@@ -137,3 +196,12 @@ func (s *PocketService) GetStats(id, userID string, currentGoldPrice *float64) (
 
 	return stats, nil
 }
+
+// decimalPtr converts an optional request value to its exact stored form
+func decimalPtr(f *float64) *decimal.Decimal {
+	if f == nil {
+		return nil
+	}
+	d := decimal.NewFromFloat(*f)
+	return &d
+}
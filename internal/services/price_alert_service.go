@@ -0,0 +1,402 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
+)
+
+const defaultAlertCooldownHours = 24
+
+type PriceAlertService struct {
+	alertRepo           *repositories.PriceAlertRepository
+	goldPriceRepo       *repositories.GoldPriceRepository
+	settingsRepo        *repositories.SettingsRepository
+	userRepo            *repositories.UserRepository
+	brandService        *BrandService
+	emailService        *EmailService
+	notificationService *NotificationService
+}
+
+func NewPriceAlertService(
+	alertRepo *repositories.PriceAlertRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+	settingsRepo *repositories.SettingsRepository,
+	userRepo *repositories.UserRepository,
+	brandService *BrandService,
+	emailService *EmailService,
+	notificationService *NotificationService,
+) *PriceAlertService {
+	return &PriceAlertService{
+		alertRepo:           alertRepo,
+		goldPriceRepo:       goldPriceRepo,
+		settingsRepo:        settingsRepo,
+		userRepo:            userRepo,
+		brandService:        brandService,
+		emailService:        emailService,
+		notificationService: notificationService,
+	}
+}
+
+func (s *PriceAlertService) GetAll(userID string) ([]models.PriceAlert, error) {
+	return s.alertRepo.FindAll(userID)
+}
+
+func (s *PriceAlertService) GetByID(id, userID string) (*models.PriceAlert, error) {
+	return s.alertRepo.FindByID(id, userID)
+}
+
+func (s *PriceAlertService) Create(userID string, req *models.CreatePriceAlertRequest) (*models.PriceAlert, error) {
+	alert := &models.PriceAlert{
+		UserID:        userID,
+		AlertType:     req.AlertType,
+		PriceSource:   req.PriceSource,
+		NotifyEmail:   true,
+		NotifyPush:    true,
+		NotifyInApp:   true,
+		CooldownHours: defaultAlertCooldownHours,
+		Active:        true,
+	}
+	if req.Threshold != nil {
+		threshold := decimal.NewFromFloat(*req.Threshold)
+		alert.Threshold = &threshold
+	}
+	if req.Brand != nil {
+		if err := s.setBrand(alert, *req.Brand); err != nil {
+			return nil, err
+		}
+	}
+	if req.NotifyEmail != nil {
+		alert.NotifyEmail = *req.NotifyEmail
+	}
+	if req.NotifyPush != nil {
+		alert.NotifyPush = *req.NotifyPush
+	}
+	if req.NotifyInApp != nil {
+		alert.NotifyInApp = *req.NotifyInApp
+	}
+	if req.CooldownHours != nil {
+		alert.CooldownHours = *req.CooldownHours
+	}
+
+	if err := validatePriceAlert(alert); err != nil {
+		return nil, err
+	}
+
+	if err := s.alertRepo.Create(alert); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+func (s *PriceAlertService) Update(id, userID string, req *models.UpdatePriceAlertRequest) (*models.PriceAlert, error) {
+	alert, err := s.alertRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PriceSource != nil {
+		// An empty source watches prices of every source
+		alert.PriceSource = req.PriceSource
+		if *req.PriceSource == "" {
+			alert.PriceSource = nil
+		}
+	}
+	if req.Brand != nil {
+		if err := s.setBrand(alert, *req.Brand); err != nil {
+			return nil, err
+		}
+	}
+	if req.Threshold != nil {
+		threshold := decimal.NewFromFloat(*req.Threshold)
+		alert.Threshold = &threshold
+	}
+	if req.NotifyEmail != nil {
+		alert.NotifyEmail = *req.NotifyEmail
+	}
+	if req.NotifyPush != nil {
+		alert.NotifyPush = *req.NotifyPush
+	}
+	if req.NotifyInApp != nil {
+		alert.NotifyInApp = *req.NotifyInApp
+	}
+	if req.CooldownHours != nil {
+		alert.CooldownHours = *req.CooldownHours
+	}
+	if req.Active != nil {
+		alert.Active = *req.Active
+	}
+
+	if err := validatePriceAlert(alert); err != nil {
+		return nil, err
+	}
+
+	if err := s.alertRepo.Update(alert); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+func (s *PriceAlertService) Delete(id, userID string) error {
+	return s.alertRepo.Delete(id, userID)
+}
+
+// GetEvents returns the alerts that fired for the user with in-app delivery
+func (s *PriceAlertService) GetEvents(userID string, page, limit int) ([]models.PriceAlertEvent, int, error) {
+	return s.alertRepo.FindEvents(userID, page, limit)
+}
+
+func (s *PriceAlertService) setBrand(alert *models.PriceAlert, value string) error {
+	brand, err := s.brandService.Resolve(value)
+	if err != nil {
+		return err
+	}
+	alert.Brand = &brand.Name
+	alert.BrandID = &brand.ID
+	return nil
+}
+
+func validatePriceAlert(alert *models.PriceAlert) error {
+	if alert.AlertType == models.PriceAlertBelowAverageCost {
+		if alert.Threshold != nil {
+			return errors.New("below_average_cost alerts compare with your average cost and take no threshold")
+		}
+		return nil
+	}
+
+	if alert.Threshold == nil {
+		return errors.New("threshold is required")
+	}
+	if alert.BrandID != nil {
+		return errors.New("brand is only used by below_average_cost alerts")
+	}
+	if alert.AlertType == models.PriceAlertDailyChange && alert.Threshold.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("daily change threshold cannot exceed 100 percent")
+	}
+
+	return nil
+}
+
+// Evaluate checks the alerts watching the source of a newly stored price and delivers
+// those that fire. An alert fires at most once per price date and stays quiet for its
+// cooldown afterwards.
+func (s *PriceAlertService) Evaluate(price models.GoldPrice) {
+	alerts, err := s.alertRepo.FindActiveForSource(price.Source)
+	if err != nil {
+		log.Printf("Error loading price alerts for %s: %v", price.Source, err)
+		return
+	}
+
+	// The previous price of the source, loaded once for daily change alerts
+	var previous *models.GoldPrice
+	previousLoaded := false
+	previousPrice := func() *models.GoldPrice {
+		if !previousLoaded {
+			previousLoaded = true
+			stored, err := s.goldPriceRepo.FindOnOrBefore(price.Date.AddDate(0, 0, -1), price.Source)
+			if err == nil && price.Date.Sub(stored.Date) <= valuationPriceMaxAge {
+				previous = stored
+			}
+		}
+		return previous
+	}
+
+	fired := 0
+	for i := range alerts {
+		alert := &alerts[i]
+		if alert.LastPriceDate != nil && !price.Date.After(*alert.LastPriceDate) {
+			continue
+		}
+		if alert.LastTriggeredAt != nil && time.Since(*alert.LastTriggeredAt) < time.Duration(alert.CooldownHours)*time.Hour {
+			continue
+		}
+
+		event, err := s.check(alert, price, previousPrice)
+		if err != nil {
+			log.Printf("Error checking price alert %s: %v", alert.ID, err)
+			continue
+		}
+		if event == nil {
+			continue
+		}
+
+		// Recorded before delivery, so an alert fires at most once even when delivery
+		// fails halfway or another instance evaluates the same price
+		language, emailEnabled := s.alertSettings(alert.UserID)
+		event.Message = priceAlertMessage(alert, event, language)
+		recorded, err := s.alertRepo.RecordTrigger(event)
+		if err != nil {
+			log.Printf("Error recording price alert %s: %v", alert.ID, err)
+			continue
+		}
+		if !recorded {
+			continue
+		}
+
+		s.deliver(alert, event, language, emailEnabled)
+		if err := s.alertRepo.RecordDelivery(event); err != nil {
+			log.Printf("Error recording delivery of price alert %s: %v", alert.ID, err)
+		}
+		fired++
+	}
+
+	if fired > 0 {
+		log.Printf("Successfully delivered %d price alerts for %s", fired, price.Source)
+	}
+}
+
+// check returns the event of the alert when the price meets its condition
+func (s *PriceAlertService) check(alert *models.PriceAlert, price models.GoldPrice, previousPrice func() *models.GoldPrice) (*models.PriceAlertEvent, error) {
+	current := decimal.NewFromFloat(price.PricePerGram)
+	event := &models.PriceAlertEvent{
+		AlertID:      alert.ID,
+		UserID:       alert.UserID,
+		AlertType:    alert.AlertType,
+		PriceDate:    price.Date,
+		PriceSource:  price.Source,
+		PricePerGram: current,
+	}
+
+	switch alert.AlertType {
+	case models.PriceAlertAbove:
+		if current.LessThan(*alert.Threshold) {
+			return nil, nil
+		}
+		event.ReferenceValue = alert.Threshold
+
+	case models.PriceAlertBelow:
+		if current.GreaterThan(*alert.Threshold) {
+			return nil, nil
+		}
+		event.ReferenceValue = alert.Threshold
+
+	case models.PriceAlertDailyChange:
+		previous := previousPrice()
+		if previous == nil || previous.PricePerGram <= 0 {
+			return nil, nil
+		}
+		change := roundTo((price.PricePerGram-previous.PricePerGram)/previous.PricePerGram*100, 2)
+		if decimal.NewFromFloat(change).Abs().LessThan(*alert.Threshold) {
+			return nil, nil
+		}
+		previousValue := decimal.NewFromFloat(previous.PricePerGram)
+		event.ReferenceValue = &previousValue
+		event.ChangePercentage = &change
+
+	case models.PriceAlertBelowAverageCost:
+		average, err := s.alertRepo.GetAverageCost(alert.UserID, alert.BrandID)
+		if err != nil {
+			return nil, err
+		}
+		if average == nil || !current.LessThan(*average) {
+			return nil, nil
+		}
+		event.ReferenceValue = average
+
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+// alertSettings returns the user's language and whether the user receives emails
+func (s *PriceAlertService) alertSettings(userID string) (language string, emailEnabled bool) {
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil {
+		return "en", true
+	}
+	return settings.Language, settings.EmailNotifications
+}
+
+// deliver sends the alert through the channels enabled on the alert, the user's
+// settings and the user's price alert notification preferences, and records them on
+// the event
+func (s *PriceAlertService) deliver(alert *models.PriceAlert, event *models.PriceAlertEvent, language string, emailEnabled bool) {
+	if alert.NotifyEmail && emailEnabled && s.notificationService.Preference(alert.UserID, models.NotificationPriceAlert).Email {
+		user, err := s.userRepo.FindByID(alert.UserID)
+		if err == nil {
+			greeting := "Hi"
+			if language == "id" {
+				greeting = "Halo"
+			}
+			body := fmt.Sprintf("%s %s,\n\n%s\n", greeting, user.FullName, event.Message)
+			if err := s.emailService.Send(user.Email, priceAlertTitle(language), body); err != nil {
+				log.Printf("Error sending price alert to user %s: %v", alert.UserID, err)
+			} else {
+				event.DeliveredEmail = true
+			}
+		}
+	}
+
+	event.InApp, event.DeliveredPush = s.notificationService.Notify(Notice{
+		UserID: alert.UserID,
+		Type:   models.NotificationPriceAlert,
+		Data: map[string]string{
+			"alert_id":   alert.ID,
+			"price_date": event.PriceDate.Format("2006-01-02"),
+		},
+		Text: func(language string) (string, string) {
+			return priceAlertTitle(language), priceAlertMessage(alert, event, language)
+		},
+		SkipInApp: !alert.NotifyInApp,
+		SkipPush:  !alert.NotifyPush,
+	})
+}
+
+func priceAlertTitle(language string) string {
+	if language == "id" {
+		return "Peringatan harga emas"
+	}
+	return "Gold price alert"
+}
+
+func priceAlertMessage(alert *models.PriceAlert, event *models.PriceAlertEvent, language string) string {
+	price := utils.FormatDecimal(event.PricePerGram, 0, language)
+	reference := ""
+	if event.ReferenceValue != nil {
+		reference = utils.FormatDecimal(*event.ReferenceValue, 0, language)
+	}
+
+	if language == "id" {
+		switch alert.AlertType {
+		case models.PriceAlertAbove:
+			return fmt.Sprintf("Harga emas (%s) Rp %s/g, mencapai atau di atas batas peringatan Anda Rp %s/g.", event.PriceSource, price, reference)
+		case models.PriceAlertBelow:
+			return fmt.Sprintf("Harga emas (%s) Rp %s/g, mencapai atau di bawah batas peringatan Anda Rp %s/g.", event.PriceSource, price, reference)
+		case models.PriceAlertDailyChange:
+			return fmt.Sprintf("Harga emas (%s) berubah %s%% dari harga sebelumnya Rp %s/g menjadi Rp %s/g.",
+				event.PriceSource, utils.FormatNumber(*event.ChangePercentage, 2, language), reference, price)
+		default:
+			message := fmt.Sprintf("Harga emas (%s) Rp %s/g di bawah rata-rata biaya Anda Rp %s/g", event.PriceSource, price, reference)
+			if alert.Brand != nil {
+				message += " untuk " + *alert.Brand
+			}
+			return message + "."
+		}
+	}
+
+	switch alert.AlertType {
+	case models.PriceAlertAbove:
+		return fmt.Sprintf("The gold price (%s) is Rp %s/g, at or above your alert of Rp %s/g.", event.PriceSource, price, reference)
+	case models.PriceAlertBelow:
+		return fmt.Sprintf("The gold price (%s) is Rp %s/g, at or below your alert of Rp %s/g.", event.PriceSource, price, reference)
+	case models.PriceAlertDailyChange:
+		return fmt.Sprintf("The gold price (%s) moved %s%% from the previous Rp %s/g to Rp %s/g.",
+			event.PriceSource, utils.FormatNumber(*event.ChangePercentage, 2, language), reference, price)
+	default:
+		message := fmt.Sprintf("The gold price (%s) of Rp %s/g is below your average cost of Rp %s/g", event.PriceSource, price, reference)
+		if alert.Brand != nil {
+			message += " for " + *alert.Brand
+		}
+		return message + "."
+	}
+}
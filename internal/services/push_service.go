@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/push"
+	"nabung-emas-api/internal/repositories"
+)
+
+type PushService struct {
+	deviceRepo deviceStore
+	sender     push.PushSender
+}
+
+// NewPushService takes a nil sender when no push platform is configured; devices can
+// still be registered, but nothing is sent
+func NewPushService(deviceRepo *repositories.DeviceRepository, sender push.PushSender) *PushService {
+	return &PushService{
+		deviceRepo: deviceRepo,
+		sender:     sender,
+	}
+}
+
+func (s *PushService) GetDevices(userID string) ([]models.PushDevice, error) {
+	return s.deviceRepo.FindByUser(userID)
+}
+
+func (s *PushService) RegisterDevice(userID string, req *models.RegisterDeviceRequest) (*models.PushDevice, error) {
+	device := &models.PushDevice{
+		UserID:   userID,
+		Platform: req.Platform,
+		Token:    strings.TrimSpace(req.Token),
+		Language: req.Language,
+		Name:     req.Name,
+	}
+
+	if req.Platform == push.PlatformWebPush {
+		if req.Keys == nil {
+			return nil, errors.New("keys are required for webpush subscriptions")
+		}
+		if err := push.CheckWebPushEndpoint(device.Token); err != nil {
+			return nil, errors.New("webpush token must be the subscription endpoint of a browser push service")
+		}
+		device.P256DH = &req.Keys.P256DH
+		device.Auth = &req.Keys.Auth
+	} else if req.Keys != nil {
+		return nil, errors.New("keys are only used by webpush subscriptions")
+	}
+
+	if err := s.deviceRepo.Upsert(device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (s *PushService) DeleteDevice(id, userID string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return errors.New("device not found")
+	}
+	return s.deviceRepo.Delete(id, userID)
+}
+
+// VAPIDPublicKey returns the key browsers subscribe to Web Push with
+func (s *PushService) VAPIDPublicKey() (string, error) {
+	key := push.VAPIDPublicKey(s.sender)
+	if key == "" {
+		return "", errors.New("web push is not configured")
+	}
+	return key, nil
+}
+
+// Send delivers a message to every device of the user, rendered in the device's
+// language or else in language. Devices their provider rejects are removed. It
+// reports whether any device received the message.
+func (s *PushService) Send(userID, language string, message func(language string) push.Message) bool {
+	if s.sender == nil {
+		return false
+	}
+
+	devices, err := s.deviceRepo.FindByUser(userID)
+	if err != nil {
+		log.Printf("Error loading push devices of user %s: %v", userID, err)
+		return false
+	}
+
+	delivered := false
+	for _, device := range devices {
+		deviceLanguage := language
+		if device.Language != nil {
+			deviceLanguage = *device.Language
+		}
+
+		target := push.Device{Platform: device.Platform, Token: device.Token}
+		if device.P256DH != nil && device.Auth != nil {
+			target.P256DH, target.Auth = *device.P256DH, *device.Auth
+		}
+
+		err := s.sender.Send(target, message(deviceLanguage))
+		if errors.Is(err, push.ErrInvalidToken) {
+			if err := s.deviceRepo.Prune(device.ID); err != nil {
+				log.Printf("Error removing invalid push device %s: %v", device.ID, err)
+			} else {
+				log.Printf("Removed push device %s rejected by %s", device.ID, device.Platform)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Error sending push to device %s: %v", device.ID, err)
+			continue
+		}
+
+		delivered = true
+		if err := s.deviceRepo.MarkUsed(device.ID); err != nil {
+			log.Printf("Error updating push device %s: %v", device.ID, err)
+		}
+	}
+
+	return delivered
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/push"
+)
+
+// fakeDeviceStore keeps push devices in memory
+type fakeDeviceStore struct {
+	devices []models.PushDevice
+	pruned  []string
+	used    []string
+}
+
+func (f *fakeDeviceStore) Upsert(device *models.PushDevice) error {
+	f.devices = append(f.devices, *device)
+	return nil
+}
+
+func (f *fakeDeviceStore) FindByUser(userID string) ([]models.PushDevice, error) {
+	var devices []models.PushDevice
+	for _, device := range f.devices {
+		if device.UserID == userID {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+func (f *fakeDeviceStore) Delete(id, userID string) error {
+	return errors.New("device not found")
+}
+
+func (f *fakeDeviceStore) Prune(id string) error {
+	f.pruned = append(f.pruned, id)
+	for i, device := range f.devices {
+		if device.ID == id {
+			f.devices = append(f.devices[:i], f.devices[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeDeviceStore) MarkUsed(id string) error {
+	f.used = append(f.used, id)
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// greeting renders a message the way notices do, in the given language
+func greeting(language string) push.Message {
+	if language == "id" {
+		return push.Message{Title: "Halo", Body: "Harga emas naik"}
+	}
+	return push.Message{Title: "Hello", Body: "The gold price rose"}
+}
+
+func TestPushSendPrunesInvalidTokens(t *testing.T) {
+	store := &fakeDeviceStore{devices: []models.PushDevice{
+		{ID: "phone", UserID: "u1", Platform: push.PlatformFCM, Token: "phone-token"},
+		{ID: "old-tablet", UserID: "u1", Platform: push.PlatformAPNs, Token: "uninstalled"},
+		{ID: "other", UserID: "u2", Platform: push.PlatformFCM, Token: "other-token"},
+	}}
+	sender := push.NewFakeSender()
+	sender.MarkInvalid("uninstalled")
+	service := NewPushService(nil, sender)
+	service.deviceRepo = store
+
+	if !service.Send("u1", "en", greeting) {
+		t.Fatal("Send reported no delivery")
+	}
+
+	deliveries := sender.Deliveries()
+	if len(deliveries) != 1 || deliveries[0].Device.Token != "phone-token" {
+		t.Fatalf("deliveries = %+v, want one to phone-token", deliveries)
+	}
+	if len(store.pruned) != 1 || store.pruned[0] != "old-tablet" {
+		t.Errorf("pruned = %v, want [old-tablet]", store.pruned)
+	}
+	if len(store.used) != 1 || store.used[0] != "phone" {
+		t.Errorf("marked used = %v, want [phone]", store.used)
+	}
+
+	// The pruned device is not tried again
+	sender.MarkInvalid("phone-token")
+	if service.Send("u1", "en", greeting) {
+		t.Error("Send reported a delivery to rejected devices only")
+	}
+	if len(store.devices) != 1 || store.devices[0].ID != "other" {
+		t.Errorf("devices left = %+v, want only the other user's", store.devices)
+	}
+}
+
+func TestPushSendPerDeviceLanguage(t *testing.T) {
+	store := &fakeDeviceStore{devices: []models.PushDevice{
+		{ID: "a", UserID: "u1", Platform: push.PlatformFCM, Token: "default-language"},
+		{ID: "b", UserID: "u1", Platform: push.PlatformFCM, Token: "english", Language: stringPtr("en")},
+		{ID: "c", UserID: "u1", Platform: push.PlatformWebPush, Token: "https://fcm.googleapis.com/fcm/send/c",
+			P256DH: stringPtr("key"), Auth: stringPtr("secret")},
+	}}
+	sender := push.NewFakeSender()
+	service := NewPushService(nil, sender)
+	service.deviceRepo = store
+
+	service.Send("u1", "id", greeting)
+
+	want := map[string]string{
+		"default-language":                      "Halo",
+		"english":                               "Hello",
+		"https://fcm.googleapis.com/fcm/send/c": "Halo",
+	}
+	deliveries := sender.Deliveries()
+	if len(deliveries) != len(want) {
+		t.Fatalf("got %d deliveries, want %d", len(deliveries), len(want))
+	}
+	for _, delivery := range deliveries {
+		if title := want[delivery.Device.Token]; delivery.Message.Title != title {
+			t.Errorf("%s received %q, want %q", delivery.Device.Token, delivery.Message.Title, title)
+		}
+		if delivery.Device.Platform == push.PlatformWebPush && (delivery.Device.P256DH != "key" || delivery.Device.Auth != "secret") {
+			t.Errorf("webpush device sent without its keys: %+v", delivery.Device)
+		}
+	}
+}
+
+func TestPushSendWithoutSender(t *testing.T) {
+	store := &fakeDeviceStore{devices: []models.PushDevice{{ID: "a", UserID: "u1", Token: "token"}}}
+	service := NewPushService(nil, nil)
+	service.deviceRepo = store
+
+	if service.Send("u1", "en", greeting) {
+		t.Error("Send without a sender reported a delivery")
+	}
+}
+
+func TestRegisterWebPushDevice(t *testing.T) {
+	store := &fakeDeviceStore{}
+	service := NewPushService(nil, push.NewFakeSender())
+	service.deviceRepo = store
+	keys := &models.WebPushKeys{P256DH: "key", Auth: "secret"}
+
+	accepted := []string{
+		"https://fcm.googleapis.com/fcm/send/abc",
+		"https://updates.push.services.mozilla.com/wpush/v2/abc",
+		"https://web.push.apple.com/abc",
+		"https://wns2-by3p.notify.windows.com/w/?token=abc",
+	}
+	for _, endpoint := range accepted {
+		req := &models.RegisterDeviceRequest{Platform: push.PlatformWebPush, Token: endpoint, Keys: keys}
+		if _, err := service.RegisterDevice("u1", req); err != nil {
+			t.Errorf("RegisterDevice(%s): %v", endpoint, err)
+		}
+	}
+
+	// Endpoints are requested by the server, so they cannot point anywhere else
+	rejected := []string{
+		"http://fcm.googleapis.com/fcm/send/abc",
+		"https://127.0.0.1/push",
+		"https://169.254.169.254/latest/meta-data",
+		"https://localhost/push",
+		"https://push.example/abc",
+		"https://fcm.googleapis.com.evil.example/abc",
+		"https://evilpush.apple.com/abc",
+		"https://fcm.googleapis.com:8443/abc",
+		"https://user@fcm.googleapis.com/abc",
+	}
+	for _, endpoint := range rejected {
+		req := &models.RegisterDeviceRequest{Platform: push.PlatformWebPush, Token: endpoint, Keys: keys}
+		if _, err := service.RegisterDevice("u1", req); err == nil {
+			t.Errorf("RegisterDevice(%s) accepted the endpoint", endpoint)
+		}
+	}
+
+	if len(store.devices) != len(accepted) {
+		t.Errorf("stored %d devices, want %d", len(store.devices), len(accepted))
+	}
+}
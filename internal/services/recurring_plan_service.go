@@ -0,0 +1,540 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
+	"time"
+)
+
+// plannedPurchaseGraceDays is how long a pending planned purchase waits for
+// confirmation before it is considered missed
+const plannedPurchaseGraceDays = 3
+
+type RecurringPlanService struct {
+	planRepo            *repositories.RecurringPlanRepository
+	pocketRepo          *repositories.PocketRepository
+	goldPriceRepo       *repositories.GoldPriceRepository
+	userRepo            *repositories.UserRepository
+	transactionService  *TransactionService
+	brandService        *BrandService
+	emailService        *EmailService
+	notificationService *NotificationService
+}
+
+func NewRecurringPlanService(
+	planRepo *repositories.RecurringPlanRepository,
+	pocketRepo *repositories.PocketRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+	userRepo *repositories.UserRepository,
+	transactionService *TransactionService,
+	brandService *BrandService,
+	emailService *EmailService,
+	notificationService *NotificationService,
+) *RecurringPlanService {
+	return &RecurringPlanService{
+		planRepo:            planRepo,
+		pocketRepo:          pocketRepo,
+		goldPriceRepo:       goldPriceRepo,
+		userRepo:            userRepo,
+		transactionService:  transactionService,
+		brandService:        brandService,
+		emailService:        emailService,
+		notificationService: notificationService,
+	}
+}
+
+func (s *RecurringPlanService) GetAll(userID string) ([]models.RecurringPlan, error) {
+	return s.planRepo.FindAll(userID)
+}
+
+func (s *RecurringPlanService) GetByID(id, userID string) (*models.RecurringPlan, error) {
+	return s.planRepo.FindByID(id, userID)
+}
+
+func (s *RecurringPlanService) Create(userID string, req *models.CreateRecurringPlanRequest) (*models.RecurringPlan, error) {
+	// Validate pocket belongs to user
+	if _, err := s.pocketRepo.FindByID(req.PocketID, userID); err != nil {
+		return nil, errors.New("pocket not found")
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, errors.New("invalid start date format, use YYYY-MM-DD")
+	}
+
+	endDate, err := parseOptionalDate(req.EndDate, "end date")
+	if err != nil {
+		return nil, err
+	}
+	if endDate != nil && endDate.Before(startDate) {
+		return nil, errors.New("end date cannot be before start date")
+	}
+
+	brand, err := s.brandService.Resolve(req.Brand)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.RecurringPlan{
+		UserID:      userID,
+		PocketID:    req.PocketID,
+		Brand:       brand.Name,
+		BrandID:     brand.ID,
+		Frequency:   req.Frequency,
+		AmountType:  req.AmountType,
+		Amount:      req.Amount,
+		PriceSource: req.PriceSource,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		AutoPost:    req.AutoPost,
+		Active:      true,
+	}
+	if err := s.checkMinimumWeight(plan); err != nil {
+		return nil, err
+	}
+	// Past occurrences of a new plan are not generated
+	plan.NextDueDate = firstDueDateFrom(plan, today())
+
+	if err := s.planRepo.Create(plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (s *RecurringPlanService) Update(id, userID string, req *models.UpdateRecurringPlanRequest) (*models.RecurringPlan, error) {
+	plan, err := s.planRepo.FindByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if req.Brand != "" {
+		brand, err := s.brandService.Resolve(req.Brand)
+		if err != nil {
+			return nil, err
+		}
+		plan.Brand = brand.Name
+		plan.BrandID = brand.ID
+	}
+	if req.AmountType != "" {
+		plan.AmountType = req.AmountType
+	}
+	if req.Amount != nil {
+		plan.Amount = *req.Amount
+	}
+	if req.PriceSource != nil {
+		plan.PriceSource = req.PriceSource
+	}
+	if req.AutoPost != nil {
+		plan.AutoPost = *req.AutoPost
+	}
+	if req.EndDate != nil {
+		endDate, err := parseOptionalDate(req.EndDate, "end date")
+		if err != nil {
+			return nil, err
+		}
+		if endDate != nil && endDate.Before(plan.StartDate) {
+			return nil, errors.New("end date cannot be before start date")
+		}
+		plan.EndDate = endDate
+	}
+	if req.Active != nil {
+		plan.Active = *req.Active
+	}
+	if req.AmountType != "" || req.Amount != nil || req.PriceSource != nil {
+		if err := s.checkMinimumWeight(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resuming a plan or changing its end date skips occurrences in the past
+	if plan.Active && (req.Active != nil || req.EndDate != nil) {
+		plan.NextDueDate = firstDueDateFrom(plan, today())
+	}
+
+	if err := s.planRepo.Update(plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (s *RecurringPlanService) Delete(id, userID string) error {
+	return s.planRepo.Delete(id, userID)
+}
+
+func (s *RecurringPlanService) GetPurchases(userID string, status *string) ([]models.PlannedPurchase, error) {
+	return s.planRepo.FindPurchases(userID, status)
+}
+
+// ConfirmPurchase records a pending or missed planned purchase as a transaction
+func (s *RecurringPlanService) ConfirmPurchase(id, userID string, req *models.ConfirmPlannedPurchaseRequest) (*models.Transaction, error) {
+	purchase, err := s.planRepo.FindPurchaseByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if purchase.Status != models.PlannedPurchasePending && purchase.Status != models.PlannedPurchaseMissed {
+		return nil, errors.New("planned purchase is already " + purchase.Status)
+	}
+
+	plan, err := s.planRepo.FindByID(purchase.PlanID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	weight := purchase.Weight
+	if req.Weight != nil {
+		weight = req.Weight
+	}
+	pricePerGram := purchase.PricePerGram
+	if req.PricePerGram != nil {
+		pricePerGram = req.PricePerGram
+	}
+	if weight == nil || pricePerGram == nil {
+		return nil, errors.New("no gold price was stored for the due date, provide weight and price_per_gram")
+	}
+	if *weight < models.MinTransactionWeight {
+		return nil, fmt.Errorf("weight must be at least %g grams", models.MinTransactionWeight)
+	}
+
+	transactionDate := purchase.DueDate.Format("2006-01-02")
+	if req.TransactionDate != nil {
+		transactionDate = *req.TransactionDate
+	}
+
+	description := req.Description
+	if description == nil {
+		text := fmt.Sprintf("Recurring %s purchase", plan.Frequency)
+		description = &text
+	}
+
+	totalPrice, err := plannedTotalPrice(*weight, *pricePerGram)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.pocketRepo.FindByID(purchase.PocketID, userID); err != nil {
+		return nil, errors.New("pocket not found")
+	}
+	transaction, err := s.transactionService.newTransactionFromRequest(userID, &models.CreateTransactionRequest{
+		PocketID:        purchase.PocketID,
+		TransactionDate: transactionDate,
+		Brand:           plan.BrandID,
+		Weight:          *weight,
+		PricePerGram:    *pricePerGram,
+		TotalPrice:      totalPrice,
+		Description:     description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.planRepo.ConfirmPurchase(purchase.ID, transaction); err != nil {
+		return nil, err
+	}
+	s.transactionService.created(transaction)
+
+	return transaction, nil
+}
+
+func (s *RecurringPlanService) SkipPurchase(id, userID string) error {
+	purchase, err := s.planRepo.FindPurchaseByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if purchase.Status != models.PlannedPurchasePending && purchase.Status != models.PlannedPurchaseMissed {
+		return errors.New("planned purchase is already " + purchase.Status)
+	}
+
+	return s.planRepo.UpdatePurchaseStatus(purchase.ID, models.PlannedPurchaseSkipped, nil)
+}
+
+// StartScheduler starts a background goroutine that periodically generates
+// planned purchases for due plans and flags missed ones
+func (s *RecurringPlanService) StartScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		s.RunScheduler()
+		for range ticker.C {
+			s.RunScheduler()
+		}
+	}()
+}
+
+// RunScheduler processes every due occurrence up to today, catching up on
+// occurrences missed while the server was down
+func (s *RecurringPlanService) RunScheduler() {
+	now := today()
+
+	plans, err := s.planRepo.FindDue(now)
+	if err != nil {
+		log.Printf("Error loading due recurring plans: %v", err)
+		return
+	}
+
+	for i := range plans {
+		plan := &plans[i]
+		for plan.NextDueDate != nil && !plan.NextDueDate.After(now) {
+			if err := s.generatePurchase(plan, *plan.NextDueDate); err != nil {
+				log.Printf("Error generating purchase for plan %s: %v", plan.ID, err)
+				break
+			}
+			plan.NextDueDate = nextDueDate(plan, *plan.NextDueDate)
+		}
+		if plan.NextDueDate == nil {
+			plan.Active = false
+		}
+
+		if err := s.planRepo.Update(plan); err != nil {
+			log.Printf("Error advancing recurring plan %s: %v", plan.ID, err)
+		}
+	}
+
+	s.flagMissedPurchases(now)
+}
+
+func (s *RecurringPlanService) generatePurchase(plan *models.RecurringPlan, dueDate time.Time) error {
+	purchase := &models.PlannedPurchase{
+		PlanID:   plan.ID,
+		UserID:   plan.UserID,
+		PocketID: plan.PocketID,
+		DueDate:  dueDate,
+		Status:   models.PlannedPurchasePending,
+	}
+
+	source := ""
+	if plan.PriceSource != nil {
+		source = *plan.PriceSource
+	}
+	if price, err := s.goldPriceRepo.FindOnOrBefore(dueDate, source); err == nil {
+		weight := plannedWeight(plan, price.PricePerGram)
+		total, err := plannedTotalPrice(weight, price.PricePerGram)
+		if err != nil {
+			return err
+		}
+
+		purchase.Weight = &weight
+		purchase.PricePerGram = &price.PricePerGram
+		purchase.TotalPrice = &total
+
+		// The price rose since the plan was set up: the amount no longer buys the
+		// minimum, so the cycle is skipped rather than left pending unconfirmable
+		if weight < models.MinTransactionWeight {
+			purchase.Status = models.PlannedPurchaseSkipped
+		}
+	}
+
+	created, err := s.planRepo.CreatePurchase(purchase)
+	if err != nil || !created {
+		return err
+	}
+
+	if purchase.Status == models.PlannedPurchaseSkipped {
+		weight, pricePerGram := *purchase.Weight, *purchase.PricePerGram
+		s.remind(plan.UserID, purchase.ID, func(language string) (string, string) {
+			if language == "id" {
+				return "Pembelian emas terencana dilewati",
+					fmt.Sprintf("Dengan harga emas Rp %s/g, rencana Anda untuk %s hanya cukup untuk %s g, di bawah minimum %s g, sehingga pembelian dilewati. Naikkan jumlah rencana Anda agar pembelian berikutnya tercatat.",
+						utils.FormatNumber(pricePerGram, 0, language), utils.FormatDate(dueDate, language),
+						utils.FormatNumber(weight, 3, language), utils.FormatNumber(models.MinTransactionWeight, 1, language))
+			}
+			return "Planned gold purchase skipped",
+				fmt.Sprintf("At the gold price of Rp %s/g, your plan for %s buys only %s g, below the minimum of %s g, so the purchase was skipped. Raise the plan's amount for the next purchases to be recorded.",
+					utils.FormatNumber(pricePerGram, 0, language), dueDate.Format("2 January 2006"),
+					utils.FormatNumber(weight, 3, language), utils.FormatNumber(models.MinTransactionWeight, 1, language))
+		})
+		return nil
+	}
+
+	if plan.AutoPost && purchase.Weight != nil {
+		_, err := s.ConfirmPurchase(purchase.ID, plan.UserID, &models.ConfirmPlannedPurchaseRequest{})
+		if err == nil {
+			return nil
+		}
+		// Leave it pending so the user can correct and confirm it
+		log.Printf("Error auto-posting planned purchase %s: %v", purchase.ID, err)
+	}
+
+	s.remind(plan.UserID, purchase.ID, func(language string) (string, string) {
+		if language == "id" {
+			return "Pembelian emas terencana jatuh tempo",
+				fmt.Sprintf("Pembelian emas terencana Anda untuk %s sudah jatuh tempo. Konfirmasi di aplikasi setelah Anda membelinya.", utils.FormatDate(dueDate, language))
+		}
+		return "Planned gold purchase due",
+			fmt.Sprintf("Your planned gold purchase for %s is due. Confirm it in the app once you have bought it.", dueDate.Format("2 January 2006"))
+	})
+
+	return nil
+}
+
+func (s *RecurringPlanService) flagMissedPurchases(now time.Time) {
+	purchases, err := s.planRepo.FindPendingDueBefore(now.AddDate(0, 0, -plannedPurchaseGraceDays))
+	if err != nil {
+		log.Printf("Error loading overdue planned purchases: %v", err)
+		return
+	}
+
+	for _, purchase := range purchases {
+		if err := s.planRepo.UpdatePurchaseStatus(purchase.ID, models.PlannedPurchaseMissed, nil); err != nil {
+			log.Printf("Error marking planned purchase %s as missed: %v", purchase.ID, err)
+			continue
+		}
+
+		dueDate := purchase.DueDate
+		s.remind(purchase.UserID, purchase.ID, func(language string) (string, string) {
+			if language == "id" {
+				return "Anda melewatkan pembelian emas terencana",
+					fmt.Sprintf("Pembelian emas terencana Anda untuk %s belum dikonfirmasi. Anda masih bisa mengonfirmasinya jika sudah membeli, atau melewatinya.", utils.FormatDate(dueDate, language))
+			}
+			return "You missed a planned gold purchase",
+				fmt.Sprintf("Your planned gold purchase for %s has not been confirmed. You can still confirm it if you bought it, or skip it.", dueDate.Format("2 January 2006"))
+		})
+		if err := s.planRepo.MarkPurchaseReminded(purchase.ID); err != nil {
+			log.Printf("Error marking planned purchase %s as reminded: %v", purchase.ID, err)
+		}
+	}
+}
+
+// remind emails the reminder in English and notifies the user of it in the
+// notification center and on their devices, in their language
+func (s *RecurringPlanService) remind(userID, purchaseID string, text func(language string) (subject, body string)) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return
+	}
+
+	if s.notificationService.Preference(userID, models.NotificationPlannedPurchase).Email {
+		subject, body := text("en")
+		if err := s.emailService.Send(user.Email, subject, "Hi "+user.FullName+",\n\n"+body+"\n"); err != nil {
+			log.Printf("Error sending reminder to user %s: %v", userID, err)
+		}
+	}
+
+	s.notificationService.Notify(Notice{
+		UserID: userID,
+		Type:   models.NotificationPlannedPurchase,
+		Data:   map[string]string{"planned_purchase_id": purchaseID},
+		Text:   text,
+	})
+}
+
+// checkMinimumWeight rejects a plan whose purchases would weigh less than the minimum
+// transaction weight. Rupiah amounts are checked at the latest stored gold price; the
+// purchases of a plan created before any price is stored are checked as they fall due.
+func (s *RecurringPlanService) checkMinimumWeight(plan *models.RecurringPlan) error {
+	if plan.AmountType == models.PlanAmountGrams {
+		if plan.Amount < models.MinTransactionWeight {
+			return fmt.Errorf("amount must be at least %g grams", models.MinTransactionWeight)
+		}
+		return nil
+	}
+
+	source := ""
+	if plan.PriceSource != nil {
+		source = *plan.PriceSource
+	}
+	price, err := s.goldPriceRepo.FindOnOrBefore(today(), source)
+	if err != nil {
+		return nil
+	}
+	if plannedWeight(plan, price.PricePerGram) < models.MinTransactionWeight {
+		minimum := math.Ceil(models.MinTransactionWeight * price.PricePerGram)
+		return fmt.Errorf("amount buys less than %g grams at the current gold price of Rp %.0f/g, plan at least Rp %.0f",
+			models.MinTransactionWeight, price.PricePerGram, minimum)
+	}
+	return nil
+}
+
+// plannedWeight returns the weight a purchase of the plan buys at the price per gram
+func plannedWeight(plan *models.RecurringPlan, pricePerGram float64) float64 {
+	if plan.AmountType != models.PlanAmountRupiah {
+		return plan.Amount
+	}
+	// Round down so the purchase never exceeds the planned budget
+	weight, err := decimal.NewFromFloat(plan.Amount).Div(decimal.NewFromFloat(pricePerGram))
+	if err != nil {
+		return 0
+	}
+	return weight.Floor(decimal.WeightPlaces).Float64()
+}
+
+// plannedTotalPrice returns weight × price per gram, rounded as transactions store it
+func plannedTotalPrice(weight, pricePerGram float64) (float64, error) {
+	total, err := decimal.NewFromFloat(weight).Round(decimal.WeightPlaces).Mul(decimal.NewFromFloat(pricePerGram).Round(decimal.MoneyPlaces))
+	if err != nil {
+		return 0, err
+	}
+	return total.Round(decimal.MoneyPlaces).Float64(), nil
+}
+
+// firstDueDateFrom returns the first occurrence of the plan on or after the given date,
+// or nil when the plan has ended
+func firstDueDateFrom(plan *models.RecurringPlan, from time.Time) *time.Time {
+	due := plan.StartDate
+	for due.Before(from) {
+		next := nextDueDate(plan, due)
+		if next == nil {
+			return nil
+		}
+		due = *next
+	}
+
+	if plan.EndDate != nil && due.After(*plan.EndDate) {
+		return nil
+	}
+	return &due
+}
+
+// nextDueDate returns the occurrence after current, or nil when it is past the end date.
+// Monthly plans keep the start date's day, clamped to the length of shorter months.
+func nextDueDate(plan *models.RecurringPlan, current time.Time) *time.Time {
+	var next time.Time
+	switch plan.Frequency {
+	case models.PlanFrequencyWeekly:
+		next = current.AddDate(0, 0, 7)
+	default:
+		months := (current.Year()-plan.StartDate.Year())*12 + int(current.Month()-plan.StartDate.Month()) + 1
+		next = addMonthsClamped(plan.StartDate, months)
+	}
+
+	if plan.EndDate != nil && next.After(*plan.EndDate) {
+		return nil
+	}
+	return &next
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+func parseOptionalDate(value *string, name string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return nil, errors.New("invalid " + name + " format, use YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
+// today returns the current date at midnight UTC, matching how DATE columns are scanned
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
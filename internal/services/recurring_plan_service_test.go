@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"nabung-emas-api/internal/models"
+)
+
+func TestPlannedWeight(t *testing.T) {
+	tests := []struct {
+		name         string
+		amountType   string
+		amount       float64
+		pricePerGram float64
+		want         float64
+	}{
+		// Rounded down, so Rp 100.000 never buys more than its budget
+		{"rupiah below the minimum", models.PlanAmountRupiah, 100000, 1500000, 0.066},
+		{"rupiah at the minimum", models.PlanAmountRupiah, 150000, 1500000, 0.1},
+		{"rupiah rounded down", models.PlanAmountRupiah, 1000000, 1045000, 0.956},
+		// 2010000 / 1000000 is 2.0099999... as floats and floored to 2.009
+		{"rupiah exact", models.PlanAmountRupiah, 2010000, 1000000, 2.01},
+		{"grams", models.PlanAmountGrams, 0.5, 1500000, 0.5},
+	}
+
+	for _, tt := range tests {
+		plan := &models.RecurringPlan{AmountType: tt.amountType, Amount: tt.amount}
+		if got := plannedWeight(plan, tt.pricePerGram); got != tt.want {
+			t.Errorf("%s: plannedWeight = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlannedTotalPrice(t *testing.T) {
+	tests := []struct {
+		weight, pricePerGram, want float64
+	}{
+		{0.956, 1045000, 999020},
+		{0.1, 1500000, 150000},
+		// 0.3 × 1.050.000,55 as exact decimals, not floats
+		{0.3, 1050000.55, 315000.17},
+	}
+
+	for _, tt := range tests {
+		got, err := plannedTotalPrice(tt.weight, tt.pricePerGram)
+		if err != nil {
+			t.Errorf("plannedTotalPrice(%v, %v): %v", tt.weight, tt.pricePerGram, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("plannedTotalPrice(%v, %v) = %v, want %v", tt.weight, tt.pricePerGram, got, tt.want)
+		}
+	}
+}
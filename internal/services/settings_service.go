@@ -28,6 +28,9 @@ func (s *SettingsService) Get(userID string) (*models.SettingsResponse, error) {
 			Push:        settings.PushNotifications,
 			PriceAlerts: settings.PriceAlerts,
 		},
+		Zakat: models.ZakatSettingsResponse{
+			NisabGrams: settings.ZakatNisabGrams,
+		},
 	}, nil
 }
 
@@ -44,6 +47,9 @@ func (s *SettingsService) Update(userID string, req *models.UpdateSettingsReques
 	if req.Theme != nil {
 		settings.Theme = *req.Theme
 	}
+	if req.Currency != nil {
+		settings.Currency = *req.Currency
+	}
 	if req.Notifications != nil {
 		if req.Notifications.Email != nil {
 			settings.EmailNotifications = *req.Notifications.Email
@@ -56,6 +62,10 @@ func (s *SettingsService) Update(userID string, req *models.UpdateSettingsReques
 		}
 	}
 
+	if req.Zakat != nil && req.Zakat.NisabGrams != nil {
+		settings.ZakatNisabGrams = *req.Zakat.NisabGrams
+	}
+
 	if err := s.repo.Update(settings); err != nil {
 		return nil, err
 	}
@@ -69,5 +79,8 @@ func (s *SettingsService) Update(userID string, req *models.UpdateSettingsReques
 			Push:        settings.PushNotifications,
 			PriceAlerts: settings.PriceAlerts,
 		},
+		Zakat: models.ZakatSettingsResponse{
+			NisabGrams: settings.ZakatNisabGrams,
+		},
 	}, nil
 }
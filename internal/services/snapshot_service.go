@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+// maxBackfillDays bounds a single backfill to about ten years of snapshots
+const maxBackfillDays = 3660
+
+type SnapshotService struct {
+	snapshotRepo        *repositories.SnapshotRepository
+	goldPriceRepo       *repositories.GoldPriceRepository
+	exchangeRateService *ExchangeRateService
+}
+
+func NewSnapshotService(
+	snapshotRepo *repositories.SnapshotRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+	exchangeRateService *ExchangeRateService,
+) *SnapshotService {
+	return &SnapshotService{
+		snapshotRepo:        snapshotRepo,
+		goldPriceRepo:       goldPriceRepo,
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// TakeSnapshot records every portfolio and pocket as of the end of date, valued at the
+// latest gold price stored in the week up to that day. It reports whether a price was
+// found; without one, weights and cost are still recorded.
+func (s *SnapshotService) TakeSnapshot(date time.Time) (int, bool, error) {
+	var goldPrice *decimal.Decimal
+	if price, err := s.goldPriceRepo.FindOnOrBefore(date, ""); err == nil && date.Sub(price.Date) <= valuationPriceMaxAge {
+		value := decimal.NewFromFloat(price.PricePerGram)
+		goldPrice = &value
+	}
+
+	stored, err := s.snapshotRepo.ReplaceForDate(date, goldPrice)
+	return stored, goldPrice != nil, err
+}
+
+// Backfill recomputes the snapshots of every day between from and to, e.g. after
+// importing old transactions or gold prices
+func (s *SnapshotService) Backfill(from, to time.Time) (*models.SnapshotBackfillResult, error) {
+	if to.Before(from) {
+		return nil, errors.New("end date cannot be before start date")
+	}
+	if to.After(today()) {
+		return nil, errors.New("end date cannot be in the future")
+	}
+	if to.Sub(from) > maxBackfillDays*24*time.Hour {
+		return nil, errors.New("date range cannot exceed 10 years")
+	}
+
+	result := &models.SnapshotBackfillResult{From: &from, To: &to}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		stored, priced, err := s.TakeSnapshot(date)
+		if err != nil {
+			return nil, err
+		}
+		result.Days++
+		result.Snapshots += stored
+		if !priced {
+			result.DaysWithoutPrice++
+		}
+	}
+
+	return result, nil
+}
+
+// StartScheduler starts a background goroutine that records the snapshots of each
+// finished day. Days missed while the server was down are caught up on the next run.
+func (s *SnapshotService) StartScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			yesterday := today().AddDate(0, 0, -1)
+
+			from := yesterday
+			latest, err := s.snapshotRepo.FindLatestDate()
+			if err != nil {
+				log.Printf("Error finding latest portfolio snapshot: %v", err)
+				continue
+			}
+			if latest != nil {
+				from = latest.AddDate(0, 0, 1)
+			}
+			if from.After(yesterday) {
+				continue
+			}
+
+			result, err := s.Backfill(from, yesterday)
+			if err != nil {
+				log.Printf("Error recording portfolio snapshots: %v", err)
+			} else {
+				log.Printf("Successfully recorded %d portfolio snapshots for %d days", result.Snapshots, result.Days)
+			}
+		}
+	}()
+}
+
+// DisplayCurrency resolves the currency of the value history, checking its rates reach
+// back to the user's first snapshot
+func (s *SnapshotService) DisplayCurrency(userID, requested string) (string, error) {
+	firstDate, err := s.snapshotRepo.FindFirstDate(userID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.exchangeRateService.DisplayCurrencySince(userID, requested, firstDate)
+}
+
+// GetValueHistory returns the snapshots of the portfolio, or of one pocket, per day,
+// week or month. Amounts are converted into currency at the rate of the snapshot day.
+func (s *SnapshotService) GetValueHistory(userID, currency string, pocketID, startDate, endDate *string, interval string) (*models.ValueHistory, error) {
+	if interval == "" {
+		interval = "day"
+	}
+	if interval != "day" && interval != "week" && interval != "month" {
+		return nil, errors.New("interval must be day, week or month")
+	}
+	for _, date := range []*string{startDate, endDate} {
+		if date == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", *date); err != nil {
+			return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+	}
+
+	snapshots, err := s.snapshotRepo.FindHistory(userID, pocketID, startDate, endDate, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := s.exchangeRateService.NewConverter(currency)
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		for _, amount := range []*decimal.Decimal{&snapshot.TotalCost, snapshot.GoldPrice, snapshot.MarketValue} {
+			if amount == nil {
+				continue
+			}
+			converted, err := converter.Convert(*amount, snapshot.Date)
+			if err != nil {
+				return nil, err
+			}
+			*amount = converted
+		}
+
+		if snapshot.MarketValue != nil {
+			profitLoss := snapshot.MarketValue.Sub(snapshot.TotalCost)
+			snapshot.ProfitLoss = &profitLoss
+		}
+	}
+
+	return &models.ValueHistory{
+		Currency:  currency,
+		PocketID:  pocketID,
+		Interval:  interval,
+		Snapshots: snapshots,
+	}, nil
+}
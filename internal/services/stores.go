@@ -0,0 +1,70 @@
+package services
+
+import (
+	"time"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+// Services that are tested without a database hold their repositories through the
+// interfaces below rather than the repository structs. Each is named after the
+// repository it stands for, lists only the methods the services call, and is
+// satisfied by that repository, as the assertions at the end check. Constructors
+// still take the repository structs; tests assign a fake to the field.
+type (
+	userStore interface {
+		FindDeletedBefore(before time.Time) ([]models.User, error)
+		Delete(id string) error
+	}
+
+	transactionStore interface {
+		GetReceiptImagesByUser(userID string) ([]string, error)
+	}
+
+	exportStore interface {
+		GetFileKeysByUser(userID string) ([]string, error)
+	}
+
+	deviceStore interface {
+		Upsert(device *models.PushDevice) error
+		FindByUser(userID string) ([]models.PushDevice, error)
+		Delete(id, userID string) error
+		Prune(id string) error
+		MarkUsed(id string) error
+	}
+
+	notificationStore interface {
+		Create(notification *models.Notification) error
+		FindPage(userID string, beforeTime *time.Time, beforeID *string, unreadOnly bool, limit int) ([]models.Notification, error)
+		CountUnread(userID string) (int, error)
+		MarkRead(id, userID string) error
+		MarkAllRead(userID string) (int, error)
+		FindPreferences(userID string) (map[string]models.NotificationPreference, error)
+		UpsertPreferences(userID string, preferences []models.NotificationPreference) error
+	}
+
+	settingsStore interface {
+		FindByUserID(userID string) (*models.UserSettings, error)
+	}
+
+	streamEventStore interface {
+		Create(event *models.StreamEvent) error
+		FindByID(id int64) (*models.StreamEvent, error)
+		FindAfter(afterID int64, limit int) ([]models.StreamEvent, error)
+		FindForUserAfter(userID string, afterID int64, limit int) ([]models.StreamEvent, error)
+		FindLastID() (int64, error)
+		FindFirstID() (*int64, error)
+		DeleteBefore(before time.Time) (int64, error)
+	}
+)
+
+var (
+	_ userStore         = (*repositories.UserRepository)(nil)
+	_ transactionStore  = (*repositories.TransactionRepository)(nil)
+	_ exportStore       = (*repositories.DataExportRepository)(nil)
+	_ deviceStore       = (*repositories.DeviceRepository)(nil)
+	_ notificationStore = (*repositories.NotificationRepository)(nil)
+	_ settingsStore     = (*repositories.SettingsRepository)(nil)
+	_ streamEventStore  = (*repositories.StreamEventRepository)(nil)
+)
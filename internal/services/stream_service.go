@@ -0,0 +1,344 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+const (
+	streamChannel = "stream_events"
+	// streamEventRetention is how long after an event a client can still resume from it
+	streamEventRetention = 24 * time.Hour
+	// streamReplayLimit caps the events replayed to a resuming client; clients further
+	// behind reload their state instead
+	streamReplayLimit = 500
+	// streamBufferSize is how many events a client may fall behind before it is
+	// disconnected, to resume once it catches up
+	streamBufferSize = 64
+)
+
+// StreamSubscription receives the events of a user while the user's client is
+// connected. Events is closed when the client falls too far behind.
+type StreamSubscription struct {
+	UserID string
+	Events chan models.StreamEvent
+}
+
+// StreamService sends live gold prices, portfolio values and notifications to the
+// connected clients. Events are stored and announced through Postgres, so a client
+// receives them whichever API instance it is connected to.
+type StreamService struct {
+	eventRepo           streamEventStore
+	analyticsRepo       *repositories.AnalyticsRepository
+	goldPriceRepo       *repositories.GoldPriceRepository
+	exchangeRateService *ExchangeRateService
+
+	mu          sync.Mutex
+	subscribers map[*StreamSubscription]bool
+	// lastID is the newest event forwarded, to catch up after the listener reconnects
+	lastID int64
+}
+
+func NewStreamService(
+	eventRepo *repositories.StreamEventRepository,
+	analyticsRepo *repositories.AnalyticsRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+	exchangeRateService *ExchangeRateService,
+) *StreamService {
+	return &StreamService{
+		eventRepo:           eventRepo,
+		analyticsRepo:       analyticsRepo,
+		goldPriceRepo:       goldPriceRepo,
+		exchangeRateService: exchangeRateService,
+		subscribers:         make(map[*StreamSubscription]bool),
+	}
+}
+
+// Subscribe starts receiving the events of the user, until Unsubscribe
+func (s *StreamService) Subscribe(userID string) *StreamSubscription {
+	subscription := &StreamSubscription{
+		UserID: userID,
+		Events: make(chan models.StreamEvent, streamBufferSize),
+	}
+
+	s.mu.Lock()
+	s.subscribers[subscription] = true
+	s.mu.Unlock()
+
+	return subscription
+}
+
+func (s *StreamService) Unsubscribe(subscription *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscribers[subscription] {
+		delete(s.subscribers, subscription)
+		close(subscription.Events)
+	}
+}
+
+// Replay returns the events of the user stored after the event lastID, oldest first.
+// complete is false when some of them are no longer kept, or too many to replay.
+func (s *StreamService) Replay(userID string, lastID int64) (events []models.StreamEvent, complete bool, err error) {
+	firstID, err := s.eventRepo.FindFirstID()
+	if err != nil {
+		return nil, false, err
+	}
+	// Without any event kept, those after lastID may have been removed as well
+	if (firstID == nil && lastID > 0) || (firstID != nil && *firstID > lastID+1) {
+		return nil, false, nil
+	}
+
+	events, err = s.eventRepo.FindForUserAfter(userID, lastID, streamReplayLimit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(events) > streamReplayLimit {
+		return nil, false, nil
+	}
+
+	return events, true, nil
+}
+
+// PortfolioEvent returns the current value of the user's portfolio as an event that
+// is not stored
+func (s *StreamService) PortfolioEvent(userID string) (*models.StreamEvent, error) {
+	portfolio, err := s.Portfolio(userID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(portfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StreamEvent{UserID: &userID, Type: models.StreamEventPortfolio, Data: data, CreatedAt: time.Now()}, nil
+}
+
+// Portfolio values the user's holdings at the newest gold price stored in the past
+// week, in the user's display currency
+func (s *StreamService) Portfolio(userID string) (*models.PortfolioUpdate, error) {
+	currency, err := s.exchangeRateService.DisplayCurrency(userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.analyticsRepo.GetPortfolioSummary(userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	portfolio := &models.PortfolioUpdate{
+		Currency:        currency,
+		TotalWeight:     summary.TotalWeight,
+		TotalFineWeight: summary.TotalFineWeight,
+		TotalCost:       summary.TotalCost,
+		UpdatedAt:       now,
+	}
+
+	price, err := s.goldPriceRepo.FindOnOrBefore(now, "")
+	if err != nil || now.Sub(price.Date) > valuationPriceMaxAge {
+		return portfolio, nil
+	}
+	portfolio.GoldPrice = price
+
+	// Gold prices are quoted for fine gold, in rupiah
+	currentValue, err := summary.TotalFineWeight.Mul(decimal.NewFromFloat(price.PricePerGram))
+	if err != nil {
+		return nil, err
+	}
+	currentValue, err = s.exchangeRateService.NewConverter(currency).Convert(currentValue.Round(decimal.MoneyPlaces), now)
+	if err != nil {
+		return nil, err
+	}
+	profitLoss := currentValue.Sub(summary.TotalCost)
+	portfolio.CurrentValue = &currentValue
+	portfolio.ProfitLoss = &profitLoss
+	if summary.TotalCost.IsPositive() {
+		profitLossPercentage := profitLoss.Ratio(summary.TotalCost) * 100
+		portfolio.ProfitLossPercentage = &profitLossPercentage
+	}
+
+	return portfolio, nil
+}
+
+// PublishGoldPrice announces a new latest gold price to everyone. Connected clients
+// also receive their portfolio valued at it.
+func (s *StreamService) PublishGoldPrice(price models.GoldPrice) {
+	s.publish(nil, models.StreamEventGoldPrice, price)
+}
+
+// PublishPortfolio announces the new value of the user's portfolio after its holdings
+// changed
+func (s *StreamService) PublishPortfolio(userID string) {
+	portfolio, err := s.Portfolio(userID)
+	if err != nil {
+		log.Printf("Error valuing portfolio of user %s for the event stream: %v", userID, err)
+		return
+	}
+	s.publish(&userID, models.StreamEventPortfolio, portfolio)
+}
+
+// PublishNotification announces a notification added to the user's notification center
+func (s *StreamService) PublishNotification(notification models.Notification, unreadCount int) {
+	s.publish(&notification.UserID, models.StreamEventNotification, models.NotificationUpdate{
+		Notification: notification,
+		UnreadCount:  unreadCount,
+	})
+}
+
+// publish stores the event; the listener of every instance forwards it from there
+func (s *StreamService) publish(userID *string, eventType string, data interface{}) {
+	content, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding %s stream event: %v", eventType, err)
+		return
+	}
+
+	event := &models.StreamEvent{UserID: userID, Type: eventType, Data: content}
+	if err := s.eventRepo.Create(event); err != nil {
+		log.Printf("Error storing %s stream event: %v", eventType, err)
+	}
+}
+
+// StartListener forwards the events stored by every API instance to the clients
+// connected to this one. It listens on a connection of its own, which reconnects when
+// lost, and then catches up on the events stored meanwhile.
+func (s *StreamService) StartListener(databaseURL string) error {
+	lastID, err := s.eventRepo.FindLastID()
+	if err != nil {
+		return err
+	}
+	s.lastID = lastID
+
+	listener := pq.NewListener(databaseURL, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Error on stream event listener: %v", err)
+		}
+	})
+	if err := listener.Listen(streamChannel); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-listener.Notify:
+				// A nil notification follows a reconnect
+				if notification == nil {
+					s.catchUp()
+					continue
+				}
+				id, err := strconv.ParseInt(notification.Extra, 10, 64)
+				if err != nil {
+					log.Printf("Error reading stream event id %q: %v", notification.Extra, err)
+					continue
+				}
+				event, err := s.eventRepo.FindByID(id)
+				if err != nil {
+					// Removed already, with the user who received it
+					continue
+				}
+				s.forward(*event)
+			case <-time.After(90 * time.Second):
+				// Detects a lost connection while no events are announced
+				go listener.Ping()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *StreamService) catchUp() {
+	for {
+		events, err := s.eventRepo.FindAfter(s.lastID, streamReplayLimit)
+		if err != nil {
+			log.Printf("Error loading missed stream events: %v", err)
+			return
+		}
+		for _, event := range events {
+			s.forward(event)
+		}
+		if len(events) < streamReplayLimit {
+			return
+		}
+	}
+}
+
+func (s *StreamService) forward(event models.StreamEvent) {
+	if event.ID > s.lastID {
+		s.lastID = event.ID
+	}
+
+	s.dispatch(event)
+
+	if event.Type == models.StreamEventGoldPrice {
+		go s.revalue()
+	}
+}
+
+// dispatch sends the event to the clients it is for. A client that stopped reading is
+// disconnected rather than holding up the others.
+func (s *StreamService) dispatch(event models.StreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscription := range s.subscribers {
+		if event.UserID != nil && *event.UserID != subscription.UserID {
+			continue
+		}
+		select {
+		case subscription.Events <- event:
+		default:
+			delete(s.subscribers, subscription)
+			close(subscription.Events)
+		}
+	}
+}
+
+// revalue sends the connected users their portfolio valued at a new gold price
+func (s *StreamService) revalue() {
+	s.mu.Lock()
+	users := make(map[string]bool)
+	for subscription := range s.subscribers {
+		users[subscription.UserID] = true
+	}
+	s.mu.Unlock()
+
+	for userID := range users {
+		event, err := s.PortfolioEvent(userID)
+		if err != nil {
+			log.Printf("Error valuing portfolio of user %s for the event stream: %v", userID, err)
+			continue
+		}
+		s.dispatch(*event)
+	}
+}
+
+// StartCleanup starts a background goroutine that periodically removes the events
+// clients can no longer resume from
+func (s *StreamService) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			count, err := s.eventRepo.DeleteBefore(time.Now().Add(-streamEventRetention))
+			if err != nil {
+				log.Printf("Error removing old stream events: %v", err)
+			} else if count > 0 {
+				log.Printf("Successfully removed %d old stream events", count)
+			}
+		}
+	}()
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/models"
+)
+
+// fakeStreamEvents keeps stream events in memory, oldest first
+type fakeStreamEvents struct {
+	events []models.StreamEvent
+}
+
+// add stores events with the given ids, for the user or, with an empty user, everyone
+func (f *fakeStreamEvents) add(userID string, ids ...int64) {
+	for _, id := range ids {
+		event := models.StreamEvent{ID: id, Type: models.StreamEventNotification, Data: []byte("{}")}
+		if userID != "" {
+			event.UserID = stringPtr(userID)
+		}
+		f.events = append(f.events, event)
+	}
+}
+
+func (f *fakeStreamEvents) Create(event *models.StreamEvent) error {
+	lastID, _ := f.FindLastID()
+	event.ID = lastID + 1
+	f.events = append(f.events, *event)
+	return nil
+}
+
+func (f *fakeStreamEvents) FindByID(id int64) (*models.StreamEvent, error) {
+	for _, event := range f.events {
+		if event.ID == id {
+			return &event, nil
+		}
+	}
+	return nil, errors.New("stream event not found")
+}
+
+func (f *fakeStreamEvents) FindAfter(afterID int64, limit int) ([]models.StreamEvent, error) {
+	return f.find(func(event models.StreamEvent) bool { return event.ID > afterID }, limit), nil
+}
+
+func (f *fakeStreamEvents) FindForUserAfter(userID string, afterID int64, limit int) ([]models.StreamEvent, error) {
+	return f.find(func(event models.StreamEvent) bool {
+		return event.ID > afterID && (event.UserID == nil || *event.UserID == userID)
+	}, limit), nil
+}
+
+func (f *fakeStreamEvents) find(match func(models.StreamEvent) bool, limit int) []models.StreamEvent {
+	var events []models.StreamEvent
+	for _, event := range f.events {
+		if len(events) == limit {
+			break
+		}
+		if match(event) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (f *fakeStreamEvents) FindLastID() (int64, error) {
+	if len(f.events) == 0 {
+		return 0, nil
+	}
+	return f.events[len(f.events)-1].ID, nil
+}
+
+func (f *fakeStreamEvents) FindFirstID() (*int64, error) {
+	if len(f.events) == 0 {
+		return nil, nil
+	}
+	return &f.events[0].ID, nil
+}
+
+func (f *fakeStreamEvents) DeleteBefore(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestStreamService(store *fakeStreamEvents) *StreamService {
+	service := NewStreamService(nil, nil, nil, nil)
+	service.eventRepo = store
+	return service
+}
+
+func eventIDs(events []models.StreamEvent) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStreamReplay(t *testing.T) {
+	// Events 1 to 4 were removed already
+	kept := &fakeStreamEvents{}
+	kept.add("u1", 5, 6)
+	kept.add("u2", 7)
+	kept.add("", 8)
+	kept.add("u1", 9)
+
+	tests := []struct {
+		name         string
+		store        *fakeStreamEvents
+		lastID       int64
+		wantIDs      []int64
+		wantComplete bool
+	}{
+		{"from the oldest kept", kept, 4, []int64{5, 6, 8, 9}, true},
+		{"from the middle", kept, 6, []int64{8, 9}, true},
+		{"up to date", kept, 9, []int64{}, true},
+		{"from a removed event", kept, 3, nil, false},
+		{"new client", kept, 0, nil, false},
+		{"nothing stored yet", &fakeStreamEvents{}, 0, []int64{}, true},
+		// Everything was removed, including what followed the client's last event
+		{"everything removed", &fakeStreamEvents{}, 3, nil, false},
+	}
+
+	for _, tt := range tests {
+		events, complete, err := newTestStreamService(tt.store).Replay("u1", tt.lastID)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if complete != tt.wantComplete {
+			t.Errorf("%s: complete = %v, want %v", tt.name, complete, tt.wantComplete)
+		}
+		if tt.wantComplete && !equalIDs(eventIDs(events), tt.wantIDs) {
+			t.Errorf("%s: replayed %v, want %v", tt.name, eventIDs(events), tt.wantIDs)
+		}
+		if !tt.wantComplete && len(events) != 0 {
+			t.Errorf("%s: replayed %v of an incomplete replay", tt.name, eventIDs(events))
+		}
+	}
+}
+
+func TestStreamReplayLimit(t *testing.T) {
+	store := &fakeStreamEvents{}
+	for id := int64(1); id <= streamReplayLimit; id++ {
+		store.add("u1", id)
+	}
+	service := newTestStreamService(store)
+
+	events, complete, err := service.Replay("u1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete || len(events) != streamReplayLimit {
+		t.Errorf("replayed %d events, complete %v; want all %d", len(events), complete, streamReplayLimit)
+	}
+
+	// A client further behind reloads its state instead
+	store.add("", streamReplayLimit+1)
+	events, complete, err = service.Replay("u1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if complete || len(events) != 0 {
+		t.Errorf("replayed %d events, complete %v; want an incomplete replay", len(events), complete)
+	}
+}
+
+// received drains the events already sent to the subscription
+func received(subscription *StreamSubscription) (ids []int64, open bool) {
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return ids, false
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids, true
+		}
+	}
+}
+
+func TestStreamDispatch(t *testing.T) {
+	service := newTestStreamService(&fakeStreamEvents{})
+	first := service.Subscribe("u1")
+	second := service.Subscribe("u1")
+	other := service.Subscribe("u2")
+
+	service.dispatch(models.StreamEvent{ID: 1, UserID: stringPtr("u1")})
+	service.dispatch(models.StreamEvent{ID: 2})
+	service.dispatch(models.StreamEvent{ID: 3, UserID: stringPtr("u2")})
+
+	for _, tt := range []struct {
+		name         string
+		subscription *StreamSubscription
+		want         []int64
+	}{
+		{"first client of u1", first, []int64{1, 2}},
+		{"second client of u1", second, []int64{1, 2}},
+		{"client of u2", other, []int64{2, 3}},
+	} {
+		if ids, open := received(tt.subscription); !open || !equalIDs(ids, tt.want) {
+			t.Errorf("%s received %v (open %v), want %v", tt.name, ids, open, tt.want)
+		}
+	}
+
+	service.Unsubscribe(second)
+	if _, open := received(second); open {
+		t.Error("events of an unsubscribed client were not closed")
+	}
+	service.dispatch(models.StreamEvent{ID: 4, UserID: stringPtr("u1")})
+	if ids, _ := received(first); !equalIDs(ids, []int64{4}) {
+		t.Errorf("remaining client received %v, want [4]", ids)
+	}
+	// Unsubscribing twice is harmless
+	service.Unsubscribe(second)
+}
+
+func TestStreamDispatchDisconnectsSlowClients(t *testing.T) {
+	service := newTestStreamService(&fakeStreamEvents{})
+	slow := service.Subscribe("u1")
+	reading := service.Subscribe("u2")
+
+	for id := int64(1); id <= streamBufferSize+1; id++ {
+		service.dispatch(models.StreamEvent{ID: id, UserID: stringPtr("u1")})
+	}
+
+	// The buffered events are kept, so the client resumes after the last of them
+	ids, open := received(slow)
+	if open || len(ids) != streamBufferSize || ids[len(ids)-1] != streamBufferSize {
+		t.Errorf("slow client received %d events (open %v), want the first %d and then closed", len(ids), open, streamBufferSize)
+	}
+
+	// The other client keeps receiving events
+	service.dispatch(models.StreamEvent{ID: 100})
+	if ids, open := received(reading); !open || !equalIDs(ids, []int64{100}) {
+		t.Errorf("other client received %v (open %v), want [100]", ids, open)
+	}
+	if len(service.subscribers) != 1 {
+		t.Errorf("%d subscribers left, want 1", len(service.subscribers))
+	}
+	// The handler still unsubscribes the disconnected client
+	service.Unsubscribe(slow)
+}
+
+func TestStreamCatchUp(t *testing.T) {
+	store := &fakeStreamEvents{}
+	for id := int64(1); id <= 2*streamReplayLimit+3; id++ {
+		store.add("u1", id)
+	}
+	service := newTestStreamService(store)
+	service.lastID = streamReplayLimit - 10
+	subscription := &StreamSubscription{UserID: "u1", Events: make(chan models.StreamEvent, 2*streamReplayLimit)}
+	service.subscribers[subscription] = true
+
+	// The listener missed every event after its last one, across several pages
+	service.catchUp()
+
+	ids, _ := received(subscription)
+	if len(ids) != streamReplayLimit+13 || ids[0] != streamReplayLimit-9 || ids[len(ids)-1] != 2*streamReplayLimit+3 {
+		t.Errorf("forwarded %d events from %v, want %d to %d", len(ids), ids[:1], streamReplayLimit-9, 2*streamReplayLimit+3)
+	}
+	if service.lastID != 2*streamReplayLimit+3 {
+		t.Errorf("lastID = %d, want %d", service.lastID, 2*streamReplayLimit+3)
+	}
+}
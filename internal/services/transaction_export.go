@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/utils"
+	"strings"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+var transactionExportHeaders = map[string][]string{
+	"en": {"Date", "Pocket", "Brand", "Weight (g)", "Price per Gram ({currency})", "Total Price ({currency})", "Fees ({currency})", "Total Cost ({currency})", "Description"},
+	"id": {"Tanggal", "Kantong", "Merek", "Berat (g)", "Harga per Gram ({currency})", "Total Harga ({currency})", "Biaya ({currency})", "Total Biaya ({currency})", "Keterangan"},
+}
+
+// TransactionExport is an export whose settings have been loaded and checked, so
+// that a failure is reported before the response starts
+type TransactionExport struct {
+	format    string
+	lang      string
+	headers   []string
+	converter *CurrencyConverter
+}
+
+// PrepareExport loads the user's language and currency settings for an export as CSV
+// or XLSX, and checks that the amounts of every transaction can be converted into the
+// user's currency
+func (s *TransactionService) PrepareExport(userID, format string) (*TransactionExport, error) {
+	if format != ExportFormatCSV && format != ExportFormatXLSX {
+		return nil, errors.New("unsupported export format")
+	}
+
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	firstDate, err := s.transactionRepo.FindFirstDate(userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.exchangeRateService.DisplayCurrencySince(userID, "", firstDate); err != nil {
+		return nil, err
+	}
+
+	lang := settings.Language
+	if _, ok := transactionExportHeaders[lang]; !ok {
+		lang = "en"
+	}
+
+	return &TransactionExport{
+		format:    format,
+		lang:      lang,
+		headers:   localizedExportHeaders(lang, utils.CurrencySymbol(settings.Currency)),
+		converter: s.exchangeRateService.NewConverter(settings.Currency),
+	}, nil
+}
+
+// Export streams the user's transactions matching the filters to w. Headers and
+// number/date formatting follow the settings loaded by PrepareExport.
+func (s *TransactionService) Export(userID string, export *TransactionExport, pocketID, brand, startDate, endDate *string, w io.Writer) error {
+	if export.format == ExportFormatXLSX {
+		return s.exportXLSX(userID, pocketID, brand, startDate, endDate, export.lang, export.headers, export.converter, w)
+	}
+	return s.exportCSV(userID, pocketID, brand, startDate, endDate, export.lang, export.headers, export.converter, w)
+}
+
+func (s *TransactionService) exportCSV(userID string, pocketID, brand, startDate, endDate *string, lang string, headers []string, converter *CurrencyConverter, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	// Spreadsheet apps in locales with a decimal comma expect ";" as the separator
+	if lang == "id" {
+		cw.Comma = ';'
+	}
+
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	err := s.transactionRepo.Stream(userID, pocketID, brand, startDate, endDate, func(t *models.Transaction) error {
+		if err := converter.ConvertTransaction(t); err != nil {
+			return err
+		}
+		return cw.Write([]string{
+			utils.FormatDate(t.TransactionDate, lang),
+			t.Pocket.Name,
+			t.Brand,
+			utils.FormatDecimal(t.Weight, 3, lang),
+			utils.FormatDecimal(t.PricePerGram, 2, lang),
+			utils.FormatDecimal(t.TotalPrice, 2, lang),
+			utils.FormatDecimal(t.TotalFees, 2, lang),
+			utils.FormatDecimal(t.TotalCost, 2, lang),
+			stringValue(t.Description),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s *TransactionService) exportXLSX(userID string, pocketID, brand, startDate, endDate *string, lang string, headers []string, converter *CurrencyConverter, w io.Writer) error {
+	dateFormat := "yyyy-mm-dd"
+	if lang == "id" {
+		dateFormat = "dd/mm/yyyy"
+	}
+	moneyFormat := `"` + utils.CurrencySymbol(converter.Currency) + ` "#,##0.00`
+
+	const (
+		dateStyle = utils.XLSXStyleCustom + iota
+		weightStyle
+		moneyStyle
+	)
+
+	sheetName := "Transactions"
+	if lang == "id" {
+		sheetName = "Transaksi"
+	}
+
+	xw, err := utils.NewXLSXWriter(w, sheetName, []string{dateFormat, "#,##0.000", moneyFormat})
+	if err != nil {
+		return err
+	}
+
+	headerCells := make([]utils.XLSXCell, len(headers))
+	for i, header := range headers {
+		headerCells[i] = utils.XLSXCell{Value: header, Style: utils.XLSXStyleHeader}
+	}
+	if err := xw.WriteRow(headerCells); err != nil {
+		return err
+	}
+
+	err = s.transactionRepo.Stream(userID, pocketID, brand, startDate, endDate, func(t *models.Transaction) error {
+		if err := converter.ConvertTransaction(t); err != nil {
+			return err
+		}
+		return xw.WriteRow([]utils.XLSXCell{
+			{Value: t.TransactionDate, Style: dateStyle},
+			{Value: t.Pocket.Name},
+			{Value: t.Brand},
+			{Value: t.Weight, Style: weightStyle},
+			{Value: t.PricePerGram, Style: moneyStyle},
+			{Value: t.TotalPrice, Style: moneyStyle},
+			{Value: t.TotalFees, Style: moneyStyle},
+			{Value: t.TotalCost, Style: moneyStyle},
+			{Value: stringValue(t.Description)},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return xw.Close()
+}
+
+func localizedExportHeaders(lang, currencySymbol string) []string {
+	template := transactionExportHeaders[lang]
+	headers := make([]string, len(template))
+	for i, header := range template {
+		headers[i] = strings.ReplaceAll(header, "{currency}", currencySymbol)
+	}
+	return headers
+}
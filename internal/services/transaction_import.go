@@ -0,0 +1,483 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/importers"
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportRows limits the number of data rows accepted in a single CSV import
+const MaxImportRows = 5000
+
+// importRow is a parsed import line before the shared validation rules are applied
+type importRow struct {
+	number int
+	req    *models.CreateTransactionRequest
+	errors map[string][]string
+}
+
+// Import parses a CSV file of transactions and validates each row with the same
+// rules as Create. Unless it is a dry run and only when every row is valid,
+// all transactions are stored in a single database transaction.
+func (s *TransactionService) Import(userID string, r io.Reader, opts *models.TransactionImportOptions) (*models.TransactionImportResult, error) {
+	records, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, errors.New("CSV file has no data rows")
+	}
+	if len(records)-1 > MaxImportRows {
+		return nil, fmt.Errorf("CSV file has more than %d rows", MaxImportRows)
+	}
+
+	columns, err := resolveImportColumns(records[0], &opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	dateFormat := opts.DateFormat
+	if dateFormat == "" {
+		dateFormat = "2006-01-02"
+	}
+
+	// Pockets are targeted by name, case-insensitively
+	pockets, err := s.pocketRepo.FindAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	pocketIDs := make(map[string]string, len(pockets))
+	for _, p := range pockets {
+		pocketIDs[strings.ToLower(p.Name)] = p.ID
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		// Row numbers match the spreadsheet, where the header is row 1
+		req, rowErrors := parseImportRow(record, columns, pocketIDs, dateFormat, opts.DecimalSeparator)
+		rows = append(rows, importRow{number: i + 2, req: req, errors: rowErrors})
+	}
+
+	return s.processImport(userID, rows, opts.DryRun, opts.SkipDuplicates)
+}
+
+// processImport validates parsed rows, detects duplicates against existing and
+// earlier rows, and stores the result in a single database transaction
+func (s *TransactionService) processImport(userID string, rows []importRow, dryRun, skipDuplicates bool) (*models.TransactionImportResult, error) {
+	existing, err := s.transactionRepo.FindAllByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for i := range existing {
+		seen[duplicateKey(&existing[i])] = true
+	}
+
+	result := &models.TransactionImportResult{
+		DryRun:        dryRun,
+		TotalRows:     len(rows),
+		DuplicateRows: []int{},
+		Errors:        []models.TransactionImportRowError{},
+	}
+
+	var transactions []*models.Transaction
+	for _, row := range rows {
+		if len(row.errors) > 0 {
+			result.Errors = append(result.Errors, models.TransactionImportRowError{Row: row.number, Errors: row.errors})
+			continue
+		}
+
+		if err := s.validator.Validate(row.req); err != nil {
+			result.Errors = append(result.Errors, models.TransactionImportRowError{Row: row.number, Errors: utils.GetValidationErrors(err)})
+			continue
+		}
+
+		transaction, err := s.newTransactionFromRequest(userID, row.req)
+		if err != nil {
+			result.Errors = append(result.Errors, models.TransactionImportRowError{
+				Row:    row.number,
+				Errors: map[string][]string{"row": {err.Error()}},
+			})
+			continue
+		}
+
+		key := duplicateKey(transaction)
+		if seen[key] {
+			result.DuplicateRows = append(result.DuplicateRows, row.number)
+			if !skipDuplicates {
+				result.Errors = append(result.Errors, models.TransactionImportRowError{
+					Row:    row.number,
+					Errors: map[string][]string{"row": {"duplicate of an existing or earlier transaction"}},
+				})
+			}
+			continue
+		}
+		seen[key] = true
+
+		transactions = append(transactions, transaction)
+	}
+
+	result.ValidRows = len(transactions)
+
+	if dryRun {
+		for _, t := range transactions {
+			result.Preview = append(result.Preview, *t)
+		}
+		return result, nil
+	}
+
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if err := s.transactionRepo.CreateBatch(transactions); err != nil {
+		return nil, err
+	}
+	result.ImportedRows = len(transactions)
+
+	checked := make(map[string]bool)
+	for _, t := range transactions {
+		if !checked[t.PocketID] {
+			checked[t.PocketID] = true
+			s.goalService.CheckReached(t.PocketID, userID)
+		}
+	}
+	go s.streamService.PublishPortfolio(userID)
+
+	return result, nil
+}
+
+func parseImportRow(record []string, columns map[string]int, pocketIDs map[string]string, dateFormat, decimalSeparator string) (*models.CreateTransactionRequest, map[string][]string) {
+	rowErrors := make(map[string][]string)
+	value := func(field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+	number := func(field string) float64 {
+		raw := value(field)
+		if raw == "" {
+			return 0
+		}
+		n, err := utils.ParseNumber(raw, decimalSeparator)
+		if err != nil {
+			rowErrors[field] = append(rowErrors[field], field+" must be a number")
+		}
+		return n
+	}
+
+	req := &models.CreateTransactionRequest{
+		Brand:        value("brand"),
+		Weight:       number("weight"),
+		PricePerGram: number("price_per_gram"),
+		TotalPrice:   number("total_price"),
+	}
+	if description := value("description"); description != "" {
+		req.Description = &description
+	}
+
+	pocketName := value("pocket")
+	if pocketID, ok := pocketIDs[strings.ToLower(pocketName)]; ok {
+		req.PocketID = pocketID
+	} else {
+		rowErrors["pocket"] = append(rowErrors["pocket"], fmt.Sprintf("pocket %q not found", pocketName))
+	}
+
+	if raw := value("transaction_date"); raw != "" {
+		date, err := time.Parse(dateFormat, raw)
+		if err != nil {
+			rowErrors["transaction_date"] = append(rowErrors["transaction_date"], "transaction_date does not match format "+dateFormat)
+		} else {
+			req.TransactionDate = date.Format("2006-01-02")
+		}
+	}
+
+	return req, rowErrors
+}
+
+// readImportCSV reads all records, detecting whether "," or ";" is the separator
+func readImportCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimPrefix(string(data), "\uFEFF")
+	firstLine := content
+	if i := strings.IndexAny(content, "\r\n"); i >= 0 {
+		firstLine = content[:i]
+	}
+
+	cr := csv.NewReader(strings.NewReader(content))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+
+	return records, nil
+}
+
+// resolveImportColumns maps each transaction field to its column index in the header
+func resolveImportColumns(header []string, mapping *models.TransactionImportMapping) (map[string]int, error) {
+	fields := map[string]string{
+		"transaction_date": mapping.TransactionDate,
+		"pocket":           mapping.Pocket,
+		"brand":            mapping.Brand,
+		"weight":           mapping.Weight,
+		"price_per_gram":   mapping.PricePerGram,
+		"total_price":      mapping.TotalPrice,
+		"description":      mapping.Description,
+	}
+
+	headerIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		headerIndex[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int)
+	var missing []string
+	for field, column := range fields {
+		if column == "" {
+			column = field
+		}
+
+		idx, ok := headerIndex[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			if field != "description" {
+				missing = append(missing, column)
+			}
+			continue
+		}
+		columns[field] = idx
+	}
+
+	if len(missing) > 0 {
+		return nil, errors.New("CSV file is missing columns: " + strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+func duplicateKey(t *models.Transaction) string {
+	return strings.Join([]string{
+		t.PocketID,
+		t.TransactionDate.Format("2006-01-02"),
+		strings.ToLower(t.Brand),
+		t.Weight.StringFixed(3),
+		t.TotalPrice.StringFixed(2),
+	}, "|")
+}
+
+// ImportPlatform imports the gold purchases of a digital gold platform statement.
+// Without a pocket ID the purchases go to a pocket named after the platform,
+// which is created (as an Investment pocket) when it does not exist yet. Top-ups
+// below the minimum transaction weight are combined with the purchases after them.
+// Weights are recorded to the milligram like other transactions, so the four decimal
+// weights of Pegadaian statements are rounded to three, by at most 0.0005 g per purchase.
+func (s *TransactionService) ImportPlatform(userID string, importer importers.Importer, pocketID string, r io.Reader, dryRun, skipDuplicates bool) (*models.TransactionImportResult, error) {
+	entries, err := importer.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > MaxImportRows {
+		return nil, fmt.Errorf("statement has more than %d purchases", MaxImportRows)
+	}
+
+	if pocketID != "" {
+		if _, err := s.pocketRepo.FindByID(pocketID, userID); err != nil {
+			return nil, errors.New("pocket not found")
+		}
+	} else {
+		pocketID, err = s.platformPocketID(userID, importer.Name(), dryRun)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	purchases, err := combineTopUps(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, 0, len(purchases))
+	for _, purchase := range purchases {
+		description := "Imported from " + importer.Name()
+		if purchase.topUps > 1 {
+			description += fmt.Sprintf(", %d top-ups", purchase.topUps)
+		}
+		if len(purchase.references) > 0 {
+			description += " (" + strings.Join(purchase.references, ", ") + ")"
+		}
+
+		rows = append(rows, importRow{
+			number: purchase.Line,
+			req: &models.CreateTransactionRequest{
+				PocketID:        pocketID,
+				TransactionDate: purchase.Date.Format("2006-01-02"),
+				Brand:           importer.Brand(),
+				Weight:          purchase.Weight.Float64(),
+				PricePerGram:    purchase.PricePerGram.Float64(),
+				// The amount paid is recorded as the statement lists it; the total price
+				// check allows for the platform's rounding
+				TotalPrice:  purchase.Amount.Float64(),
+				Description: &description,
+			},
+		})
+	}
+
+	return s.processImport(userID, rows, dryRun, skipDuplicates)
+}
+
+// platformPurchase is a statement entry, or consecutive top-ups combined into one
+type platformPurchase struct {
+	importers.Entry
+	topUps     int
+	references []string
+}
+
+// combineTopUps combines purchases below the minimum transaction weight, which
+// platforms sell as top-ups of a few hundredths of a gram, with the purchases after
+// them until their weight reaches it. The combined purchase is dated on and numbered
+// after its last top-up, and costs what the top-ups cost together. Top-ups at the end
+// of the statement that stay below the minimum are combined with the purchase before
+// them; a statement of only such top-ups cannot be imported.
+func combineTopUps(entries []importers.Entry) ([]platformPurchase, error) {
+	sorted := append([]importers.Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	minimum := decimal.NewFromFloat(models.MinTransactionWeight)
+
+	var purchases []platformPurchase
+	var group []importers.Entry
+	weight := decimal.Zero
+	for _, entry := range sorted {
+		if len(group) == 0 && !entry.Weight.LessThan(minimum) {
+			purchase := platformPurchase{Entry: entry, topUps: 1}
+			if entry.Reference != "" {
+				purchase.references = []string{entry.Reference}
+			}
+			purchases = append(purchases, purchase)
+			continue
+		}
+
+		group = append(group, entry)
+		weight = weight.Add(entry.Weight)
+		if weight.LessThan(minimum) {
+			continue
+		}
+
+		purchase, err := combinePurchase(platformPurchase{}, group)
+		if err != nil {
+			return nil, err
+		}
+		purchases = append(purchases, purchase)
+
+		group = nil
+		weight = decimal.Zero
+	}
+
+	if len(group) > 0 {
+		if len(purchases) == 0 {
+			return nil, fmt.Errorf("the statement's top-ups weigh %s g together, less than the minimum of %g g of a transaction; import a statement that covers more purchases",
+				weight, models.MinTransactionWeight)
+		}
+
+		last, err := combinePurchase(purchases[len(purchases)-1], group)
+		if err != nil {
+			return nil, err
+		}
+		purchases[len(purchases)-1] = last
+	}
+
+	return purchases, nil
+}
+
+// combinePurchase adds the top-ups to the purchase, which may be empty, into one
+// purchase dated on and numbered after the last top-up
+func combinePurchase(purchase platformPurchase, topUps []importers.Entry) (platformPurchase, error) {
+	weight, amount := purchase.Weight, purchase.Amount
+	references := purchase.references
+	for _, topUp := range topUps {
+		weight, amount = weight.Add(topUp.Weight), amount.Add(topUp.Amount)
+		if topUp.Reference != "" {
+			references = append(references, topUp.Reference)
+		}
+	}
+
+	pricePerGram, err := amount.Div(weight)
+	if err != nil {
+		return platformPurchase{}, err
+	}
+
+	last := topUps[len(topUps)-1]
+	return platformPurchase{
+		Entry: importers.Entry{
+			Line:         last.Line,
+			Date:         last.Date,
+			Weight:       weight,
+			PricePerGram: pricePerGram.Round(decimal.MoneyPlaces),
+			Amount:       amount,
+		},
+		topUps:     purchase.topUps + len(topUps),
+		references: references,
+	}, nil
+}
+
+func (s *TransactionService) platformPocketID(userID, name string, dryRun bool) (string, error) {
+	pockets, err := s.pocketRepo.FindAllByUser(userID)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range pockets {
+		if strings.EqualFold(p.Name, name) {
+			return p.ID, nil
+		}
+	}
+
+	// A dry run must not create the pocket; any valid ID works for validation
+	if dryRun {
+		return uuid.Nil.String(), nil
+	}
+
+	typePockets, err := s.typePocketRepo.FindAll()
+	if err != nil {
+		return "", err
+	}
+	if len(typePockets) == 0 {
+		return "", errors.New("no pocket types available")
+	}
+	typePocketID := typePockets[0].ID
+	for _, tp := range typePockets {
+		if tp.Name == "Investment" {
+			typePocketID = tp.ID
+		}
+	}
+
+	pocket := &models.Pocket{
+		UserID:       userID,
+		TypePocketID: typePocketID,
+		Name:         name,
+	}
+	if err := s.pocketRepo.Create(pocket); err != nil {
+		return "", err
+	}
+
+	return pocket.ID, nil
+}
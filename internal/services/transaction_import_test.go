@@ -0,0 +1,102 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+	"nabung-emas-api/internal/importers"
+)
+
+func mustDecimal(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestCombineTopUps(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	entry := func(line, d int, weight, amount, reference string) importers.Entry {
+		w, a := mustDecimal(t, weight), mustDecimal(t, amount)
+		price, err := a.Div(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return importers.Entry{Line: line, Date: day(d), Weight: w, PricePerGram: price.Round(decimal.MoneyPlaces), Amount: a, Reference: reference}
+	}
+
+	// Newest first, as some platforms list them
+	entries := []importers.Entry{
+		entry(2, 20, "0.030", "31500", "F"),
+		entry(3, 15, "0.500", "525000", "E"),
+		entry(4, 12, "0.040", "41800", "D"),
+		entry(5, 10, "0.050", "52250", "C"),
+		entry(6, 6, "0.030", "31350", ""),
+		entry(7, 5, "0.020", "20900", "A"),
+		entry(8, 3, "1.000", "1045000", "START"),
+	}
+
+	purchases, err := combineTopUps(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		line       int
+		date       time.Time
+		weight     string
+		amount     string
+		price      string
+		topUps     int
+		references []string
+	}{
+		{8, day(3), "1", "1045000", "1045000", 1, []string{"START"}},
+		// 0.02 + 0.03 + 0.05 reaches 0.1 g on the 10th
+		{5, day(10), "0.1", "104500", "1045000", 3, []string{"A", "C"}},
+		// A regular purchase after a top-up is combined with it, and so is the top-up
+		// at the end that stays below 0.1 g: 0.04 + 0.5 + 0.03
+		{2, day(20), "0.57", "598300", "1049649.12", 3, []string{"D", "E", "F"}},
+	}
+	if len(purchases) != len(want) {
+		t.Fatalf("got %d purchases, want %d: %+v", len(purchases), len(want), purchases)
+	}
+	for i, w := range want {
+		got := purchases[i]
+		if got.Line != w.line || !got.Date.Equal(w.date) || got.topUps != w.topUps {
+			t.Errorf("purchase %d: line %d, date %s, %d top-ups; want line %d, date %s, %d top-ups",
+				i, got.Line, got.Date, got.topUps, w.line, w.date, w.topUps)
+		}
+		if !got.Weight.Equal(mustDecimal(t, w.weight)) || !got.Amount.Equal(mustDecimal(t, w.amount)) {
+			t.Errorf("purchase %d: %s g for %s, want %s g for %s", i, got.Weight, got.Amount, w.weight, w.amount)
+		}
+		if !got.PricePerGram.Equal(mustDecimal(t, w.price)) {
+			t.Errorf("purchase %d: price per gram %s, want %s", i, got.PricePerGram, w.price)
+		}
+		if len(got.references) != len(w.references) {
+			t.Errorf("purchase %d: references %v, want %v", i, got.references, w.references)
+			continue
+		}
+		for j := range w.references {
+			if got.references[j] != w.references[j] {
+				t.Errorf("purchase %d: references %v, want %v", i, got.references, w.references)
+				break
+			}
+		}
+	}
+}
+
+func TestCombineTopUpsOnlyTopUps(t *testing.T) {
+	date := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	entries := []importers.Entry{
+		{Line: 2, Date: date.AddDate(0, 0, 2), Weight: mustDecimal(t, "0.0300"), Amount: mustDecimal(t, "31500"), Reference: "B"},
+		{Line: 3, Date: date, Weight: mustDecimal(t, "0.0250"), Amount: mustDecimal(t, "26250"), Reference: "A"},
+	}
+
+	if _, err := combineTopUps(entries); err == nil || !strings.Contains(err.Error(), "0.055 g") {
+		t.Errorf("combineTopUps error = %v, want one naming the 0.055 g of top-ups", err)
+	}
+}
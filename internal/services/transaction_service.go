@@ -2,20 +2,49 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"time"
+	"nabung-emas-api/internal/decimal"
 	"nabung-emas-api/internal/models"
 	"nabung-emas-api/internal/repositories"
+	"nabung-emas-api/internal/utils"
 )
 
 type TransactionService struct {
-	transactionRepo *repositories.TransactionRepository
-	pocketRepo      *repositories.PocketRepository
+	transactionRepo     *repositories.TransactionRepository
+	pocketRepo          *repositories.PocketRepository
+	typePocketRepo      *repositories.TypePocketRepository
+	settingsRepo        *repositories.SettingsRepository
+	pledgeRepo          *repositories.PledgeRepository
+	brandService        *BrandService
+	exchangeRateService *ExchangeRateService
+	goalService         *GoalService
+	streamService       *StreamService
+	validator           *utils.CustomValidator
 }
 
-func NewTransactionService(transactionRepo *repositories.TransactionRepository, pocketRepo *repositories.PocketRepository) *TransactionService {
+func NewTransactionService(
+	transactionRepo *repositories.TransactionRepository,
+	pocketRepo *repositories.PocketRepository,
+	typePocketRepo *repositories.TypePocketRepository,
+	settingsRepo *repositories.SettingsRepository,
+	pledgeRepo *repositories.PledgeRepository,
+	brandService *BrandService,
+	exchangeRateService *ExchangeRateService,
+	goalService *GoalService,
+	streamService *StreamService,
+) *TransactionService {
 	return &TransactionService{
-		transactionRepo: transactionRepo,
-		pocketRepo:      pocketRepo,
+		transactionRepo:     transactionRepo,
+		pocketRepo:          pocketRepo,
+		typePocketRepo:      typePocketRepo,
+		settingsRepo:        settingsRepo,
+		pledgeRepo:          pledgeRepo,
+		brandService:        brandService,
+		exchangeRateService: exchangeRateService,
+		goalService:         goalService,
+		streamService:       streamService,
+		validator:           utils.NewValidator(),
 	}
 }
 
@@ -34,6 +63,24 @@ func (s *TransactionService) GetByID(id, userID string) (*models.Transaction, er
 	return s.transactionRepo.FindByID(id, userID)
 }
 
+// ToDisplayCurrency converts the amounts of the transactions into the requested
+// currency, or the user's currency setting, at the rate of each transaction date
+func (s *TransactionService) ToDisplayCurrency(userID, currency string, transactions []models.Transaction) error {
+	currency, err := s.exchangeRateService.DisplayCurrency(userID, currency)
+	if err != nil {
+		return err
+	}
+
+	converter := s.exchangeRateService.NewConverter(currency)
+	for i := range transactions {
+		if err := converter.ConvertTransaction(&transactions[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *TransactionService) Create(userID string, req *models.CreateTransactionRequest) (*models.Transaction, error) {
 	// Validate pocket belongs to user
 	_, err := s.pocketRepo.FindByID(req.PocketID, userID)
@@ -41,10 +88,45 @@ func (s *TransactionService) Create(userID string, req *models.CreateTransaction
 		return nil, errors.New("pocket not found")
 	}
 
-	// Validate total price matches weight * price_per_gram
-	expectedTotal := req.Weight * req.PricePerGram
-	if req.TotalPrice != expectedTotal {
-		return nil, errors.New("total price must equal weight * price_per_gram")
+	transaction, err := s.newTransactionFromRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transactionRepo.Create(transaction); err != nil {
+		return nil, err
+	}
+
+	s.created(transaction)
+
+	return transaction, nil
+}
+
+// created follows up on a new transaction of the user: a goal it reaches and the
+// portfolio value on the user's connected clients
+func (s *TransactionService) created(transaction *models.Transaction) {
+	s.goalService.CheckReached(transaction.PocketID, transaction.UserID)
+	go s.streamService.PublishPortfolio(transaction.UserID)
+}
+
+// newTransactionFromRequest applies the business rules for new transactions
+// that are not covered by the request's validate tags
+func (s *TransactionService) newTransactionFromRequest(userID string, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	weight := decimal.NewFromFloat(req.Weight).Round(decimal.WeightPlaces)
+	pricePerGram := decimal.NewFromFloat(req.PricePerGram).Round(decimal.MoneyPlaces)
+	totalPrice := decimal.NewFromFloat(req.TotalPrice).Round(decimal.MoneyPlaces)
+	if err := checkTotalPrice(weight, pricePerGram, totalPrice); err != nil {
+		return nil, err
+	}
+
+	purity, err := purityFromRequest(req.Karat, req.Fineness)
+	if err != nil {
+		return nil, err
+	}
+
+	brand, err := s.brandService.Resolve(req.Brand)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse transaction date
@@ -62,19 +144,67 @@ func (s *TransactionService) Create(userID string, req *models.CreateTransaction
 		UserID:          userID,
 		PocketID:        req.PocketID,
 		TransactionDate: transactionDate,
-		Brand:           req.Brand,
-		Weight:          req.Weight,
-		PricePerGram:    req.PricePerGram,
-		TotalPrice:      req.TotalPrice,
+		Brand:           brand.Name,
+		BrandID:         brand.ID,
+		Weight:          weight,
+		Purity:          purity,
+		PricePerGram:    pricePerGram,
+		TotalPrice:      totalPrice,
 		Description:     req.Description,
 		ReceiptImage:    req.ReceiptImage,
 	}
+	setFees(transaction, req.Fees)
 
-	if err := s.transactionRepo.Create(transaction); err != nil {
-		return nil, err
+	return transaction, nil
+}
+
+// setFees replaces the fee lines and keeps TotalFees and TotalCost in step with them
+func setFees(transaction *models.Transaction, fees []models.TransactionFeeRequest) {
+	transaction.Fees = make([]models.TransactionFee, 0, len(fees))
+	transaction.TotalFees = decimal.Zero
+	for _, fee := range fees {
+		amount := decimal.NewFromFloat(fee.Amount).Round(decimal.MoneyPlaces)
+		transaction.Fees = append(transaction.Fees, models.TransactionFee{
+			FeeType:     fee.FeeType,
+			Amount:      amount,
+			Description: fee.Description,
+		})
+		transaction.TotalFees = transaction.TotalFees.Add(amount)
 	}
+	transaction.TotalCost = transaction.TotalPrice.Add(transaction.TotalFees)
+}
 
-	return transaction, nil
+// totalPriceTolerance absorbs dealer and admin fee rounding: receipts are often
+// rounded to the nearest thousand rupiah, so a total within Rp 1.000 of the rounded
+// weight × price per gram is accepted as entered.
+var totalPriceTolerance = decimal.NewFromInt(1000)
+
+// checkTotalPrice validates the total paid against weight × price per gram
+func checkTotalPrice(weight, pricePerGram, totalPrice decimal.Decimal) error {
+	expected, err := weight.Mul(pricePerGram)
+	if err != nil {
+		return err
+	}
+	expected = expected.Round(decimal.MoneyPlaces)
+	if totalPrice.Sub(expected).Abs().GreaterThan(totalPriceTolerance) {
+		return fmt.Errorf("total price must equal weight * price_per_gram (%s) within Rp %s", expected.StringFixed(2), totalPriceTolerance)
+	}
+	return nil
+}
+
+// purityFromRequest converts a karat (24K scale) or fineness (parts per thousand,
+// e.g. 999.9 or 750) into a purity fraction. Gold without either is fine gold.
+func purityFromRequest(karat, fineness *float64) (decimal.Decimal, error) {
+	switch {
+	case karat != nil && fineness != nil:
+		return decimal.Zero, errors.New("specify either karat or fineness, not both")
+	case karat != nil:
+		return decimal.NewFromFloat(*karat).DivInt(24)
+	case fineness != nil:
+		return decimal.NewFromFloat(*fineness).DivInt(1000)
+	default:
+		return decimal.NewFromInt(1), nil
+	}
 }
 
 func (s *TransactionService) Update(id, userID string, req *models.UpdateTransactionRequest) (*models.Transaction, error) {
@@ -95,35 +225,103 @@ func (s *TransactionService) Update(id, userID string, req *models.UpdateTransac
 		transaction.TransactionDate = transactionDate
 	}
 	if req.Brand != "" {
-		transaction.Brand = req.Brand
+		brand, err := s.brandService.Resolve(req.Brand)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Brand = brand.Name
+		transaction.BrandID = brand.ID
 	}
 	if req.Weight > 0 {
-		transaction.Weight = req.Weight
+		weight := decimal.NewFromFloat(req.Weight).Round(decimal.WeightPlaces)
+		if err := s.checkPledged(transaction, weight); err != nil {
+			return nil, err
+		}
+		transaction.Weight = weight
+	}
+	if req.Karat != nil || req.Fineness != nil {
+		purity, err := purityFromRequest(req.Karat, req.Fineness)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Purity = purity
 	}
 	if req.PricePerGram > 0 {
-		transaction.PricePerGram = req.PricePerGram
+		transaction.PricePerGram = decimal.NewFromFloat(req.PricePerGram).Round(decimal.MoneyPlaces)
 	}
 	if req.TotalPrice > 0 {
-		// Validate total price
-		expectedTotal := transaction.Weight * transaction.PricePerGram
-		if req.TotalPrice != expectedTotal {
-			return nil, errors.New("total price must equal weight * price_per_gram")
+		totalPrice := decimal.NewFromFloat(req.TotalPrice).Round(decimal.MoneyPlaces)
+		if err := checkTotalPrice(transaction.Weight, transaction.PricePerGram, totalPrice); err != nil {
+			return nil, err
 		}
-		transaction.TotalPrice = req.TotalPrice
+		transaction.TotalPrice = totalPrice
 	}
 	if req.Description != nil {
 		transaction.Description = req.Description
 	}
+	replaceFees := req.Fees != nil
+	if replaceFees {
+		setFees(transaction, req.Fees)
+	}
 
-	if err := s.transactionRepo.Update(transaction); err != nil {
+	if err := s.transactionRepo.Update(transaction, replaceFees); err != nil {
 		return nil, err
 	}
 
+	s.goalService.CheckReached(transaction.PocketID, userID)
+	go s.streamService.PublishPortfolio(userID)
+
 	return transaction, nil
 }
 
 func (s *TransactionService) Delete(id, userID string) error {
-	return s.transactionRepo.Delete(id, userID)
+	transaction, err := s.transactionRepo.FindByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPledged(transaction, decimal.Zero); err != nil {
+		return err
+	}
+
+	if err := s.transactionRepo.Delete(id, userID); err != nil {
+		return err
+	}
+
+	// Removing a purchase can leave a reached goal unmet again
+	s.goalService.CheckReached(transaction.PocketID, userID)
+	go s.streamService.PublishPortfolio(userID)
+
+	return nil
+}
+
+// checkPledged rejects reducing the weight of a transaction, or deleting it with weight
+// zero, below the gold pledged on it or below the gold pledged on its pocket
+func (s *TransactionService) checkPledged(transaction *models.Transaction, weight decimal.Decimal) error {
+	if !weight.LessThan(transaction.Weight) {
+		return nil
+	}
+
+	transactionPledged, err := s.pledgeRepo.GetPledgedWeight(transaction.PocketID, &transaction.ID, "")
+	if err != nil {
+		return err
+	}
+	if weight.LessThan(transactionPledged) {
+		return fmt.Errorf("%s g of this transaction is pledged, redeem the pledge first", transactionPledged.StringFixed(decimal.WeightPlaces))
+	}
+
+	pocket, err := s.pocketRepo.FindByID(transaction.PocketID, transaction.UserID)
+	if err != nil {
+		return err
+	}
+	pocketPledged, err := s.pledgeRepo.GetPledgedWeight(transaction.PocketID, nil, "")
+	if err != nil {
+		return err
+	}
+	if pocket.AggregateTotalWeight.Sub(transaction.Weight).Add(weight).LessThan(pocketPledged) {
+		return fmt.Errorf("%s g of this pocket is pledged, redeem the pledge first", pocketPledged.StringFixed(decimal.WeightPlaces))
+	}
+
+	return nil
 }
 
 func (s *TransactionService) UpdateReceipt(id, userID, receiptURL string) error {
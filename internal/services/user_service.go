@@ -2,17 +2,25 @@ package services
 
 import (
 	"errors"
+	"nabung-emas-api/internal/config"
 	"nabung-emas-api/internal/models"
 	"nabung-emas-api/internal/repositories"
 	"nabung-emas-api/internal/utils"
+	"time"
 )
 
 type UserService struct {
-	userRepo *repositories.UserRepository
+	userRepo           *repositories.UserRepository
+	tokenBlacklistRepo *repositories.TokenBlacklistRepository
+	config             *config.Config
 }
 
-func NewUserService(userRepo *repositories.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo *repositories.UserRepository, tokenBlacklistRepo *repositories.TokenBlacklistRepository, cfg *config.Config) *UserService {
+	return &UserService{
+		userRepo:           userRepo,
+		tokenBlacklistRepo: tokenBlacklistRepo,
+		config:             cfg,
+	}
 }
 
 func (s *UserService) GetProfile(userID string) (*models.User, *models.UserStats, error) {
@@ -84,3 +92,34 @@ func (s *UserService) UpdateAvatar(userID, avatarURL string) error {
 	user.Avatar = &avatarURL
 	return s.userRepo.Update(user)
 }
+
+// DeleteAccount soft-deletes the user's account after verifying the password.
+// Logging in again before the grace period ends restores the account; after that
+// the purge job removes all data. Returns the time the account will be purged.
+func (s *UserService) DeleteAccount(userID, accessToken string, req *models.DeleteAccountRequest) (*time.Time, error) {
+	user, err := s.userRepo.FindByIDWithPassword(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
+		return nil, errors.New("password is incorrect")
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SoftDelete(userID, now); err != nil {
+		return nil, err
+	}
+
+	// Revoke the token used for this request
+	expiresAt := now.Add(s.config.JWTExpiry)
+	if claims, err := utils.ValidateToken(accessToken, s.config.JWTSecret); err == nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.tokenBlacklistRepo.Add(accessToken, userID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	purgeAt := now.Add(s.config.AccountDeletionGracePeriod)
+	return &purgeAt, nil
+}
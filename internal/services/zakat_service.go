@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"nabung-emas-api/internal/models"
+	"nabung-emas-api/internal/repositories"
+)
+
+type ZakatService struct {
+	zakatRepo     *repositories.ZakatRepository
+	settingsRepo  *repositories.SettingsRepository
+	goldPriceRepo *repositories.GoldPriceRepository
+}
+
+func NewZakatService(
+	zakatRepo *repositories.ZakatRepository,
+	settingsRepo *repositories.SettingsRepository,
+	goldPriceRepo *repositories.GoldPriceRepository,
+) *ZakatService {
+	return &ZakatService{
+		zakatRepo:     zakatRepo,
+		settingsRepo:  settingsRepo,
+		goldPriceRepo: goldPriceRepo,
+	}
+}
+
+// GetReport calculates zakat due on the given date. Each purchase is a lot with its own
+// haul; only lots held for a full lunar year outside exempt (jewelry-for-use) pockets
+// count, and zakat is due when those lots together reach the user's nisab.
+// Gold is valued at the stored price for the date unless goldPrice is given.
+func (s *ZakatService) GetReport(userID string, asOf *string, goldPrice *float64) (*models.ZakatReport, error) {
+	date := today()
+	if asOf != nil && *asOf != "" {
+		parsed, err := time.Parse("2006-01-02", *asOf)
+		if err != nil {
+			return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+		date = parsed
+	}
+
+	settings, err := s.settingsRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	nisab := settings.ZakatNisabGrams
+	if nisab <= 0 {
+		nisab = models.DefaultZakatNisabGrams
+	}
+
+	report := &models.ZakatReport{
+		AsOf:       date,
+		NisabGrams: nisab,
+		HaulDays:   models.ZakatHaulDays,
+		Rate:       models.ZakatRate,
+		Lots:       []models.ZakatLot{},
+	}
+
+	if goldPrice != nil && *goldPrice > 0 {
+		report.GoldPrice = goldPrice
+	} else if price, err := s.goldPriceRepo.FindOnOrBefore(date, ""); err == nil {
+		report.GoldPrice = &price.PricePerGram
+		report.GoldPriceDate = &price.Date
+	}
+
+	lots, err := s.zakatRepo.FindLots(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lot := range lots {
+		lot.HaulCompleteDate = lot.TransactionDate.AddDate(0, 0, models.ZakatHaulDays)
+		lot.HaulComplete = !lot.HaulCompleteDate.After(date)
+
+		report.TotalWeight += lot.Weight
+		if lot.Exempt {
+			report.ExemptWeight += lot.Weight
+		} else if lot.HaulComplete {
+			report.EligibleWeight += lot.Weight
+		}
+
+		report.Lots = append(report.Lots, lot)
+	}
+
+	report.TotalWeight = roundTo(report.TotalWeight, 3)
+	report.ExemptWeight = roundTo(report.ExemptWeight, 3)
+	report.ZakatableWeight = roundTo(report.TotalWeight-report.ExemptWeight, 3)
+	report.EligibleWeight = roundTo(report.EligibleWeight, 3)
+	report.NisabReached = report.EligibleWeight >= nisab
+
+	if report.NisabReached {
+		report.ZakatDueWeight = roundTo(report.EligibleWeight*models.ZakatRate, 3)
+	}
+
+	if report.GoldPrice != nil {
+		eligibleValue := roundTo(report.EligibleWeight*(*report.GoldPrice), 2)
+		zakatDue := 0.0
+		if report.NisabReached {
+			zakatDue = roundTo(eligibleValue*models.ZakatRate, 2)
+		}
+		report.EligibleValue = &eligibleValue
+		report.ZakatDue = &zakatDue
+	}
+
+	history, err := s.zakatRepo.GetYearlyHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	report.History = history
+	for _, year := range history {
+		if year.Year == date.Year() {
+			report.PaidThisYear = year.TotalPaid
+		}
+	}
+
+	return report, nil
+}
+
+func (s *ZakatService) GetPayments(userID string) ([]models.ZakatPayment, error) {
+	return s.zakatRepo.FindPayments(userID)
+}
+
+func (s *ZakatService) CreatePayment(userID string, req *models.CreateZakatPaymentRequest) (*models.ZakatPayment, error) {
+	paidAt, err := time.Parse("2006-01-02", req.PaidAt)
+	if err != nil {
+		return nil, errors.New("invalid paid date format, use YYYY-MM-DD")
+	}
+	if paidAt.After(today()) {
+		return nil, errors.New("paid date cannot be in the future")
+	}
+
+	payment := &models.ZakatPayment{
+		UserID: userID,
+		PaidAt: paidAt,
+		Amount: req.Amount,
+		Weight: req.Weight,
+		Notes:  req.Notes,
+	}
+
+	if err := s.zakatRepo.CreatePayment(payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (s *ZakatService) DeletePayment(id, userID string) error {
+	return s.zakatRepo.DeletePayment(id, userID)
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores uploaded files (avatars, receipts, exports) on the local filesystem.
+// Files are addressed by a relative key such as "receipts/<id>.jpg".
+type LocalStorage struct {
+	basePath string
+}
+
+func NewLocalStorage(basePath string) *LocalStorage {
+	return &LocalStorage{basePath: basePath}
+}
+
+// Path resolves a storage key to an absolute path inside the storage root
+func (s *LocalStorage) Path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(s.basePath, cleaned), nil
+}
+
+// IsLocalKey reports whether a stored reference points into this storage
+// rather than to an external URL
+func (s *LocalStorage) IsLocalKey(key string) bool {
+	return key != "" && !strings.Contains(key, "://")
+}
+
+// Save writes the content of r under the given key, creating parent directories as needed
+func (s *LocalStorage) Save(key string, r io.Reader) error {
+	f, err := s.Create(key)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// Create creates (or truncates) the file stored under the given key for writing
+func (s *LocalStorage) Create(key string) (*os.File, error) {
+	path, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return os.Create(path)
+}
+
+// Open opens the file stored under the given key
+func (s *LocalStorage) Open(key string) (*os.File, error) {
+	path, err := s.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes the file stored under the given key. Missing files are not an error.
+func (s *LocalStorage) Delete(key string) error {
+	if !s.IsLocalKey(key) {
+		return nil
+	}
+
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
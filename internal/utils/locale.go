@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// FormatNumber formats a number with thousands separators for the given language.
+// Indonesian uses "." for thousands and "," for decimals; English the reverse.
+func FormatNumber(value float64, decimals int, lang string) string {
+	return groupDigits(strconv.FormatFloat(value, 'f', decimals, 64), lang)
+}
+
+// FormatDecimal is FormatNumber for exact decimal amounts
+func FormatDecimal(value decimal.Decimal, decimals int, lang string) string {
+	return groupDigits(value.StringFixed(decimals), lang)
+}
+
+// groupDigits localizes a plain formatted number such as "-1234567.89"
+func groupDigits(formatted, lang string) string {
+	thousandsSep, decimalSep := ",", "."
+	if lang == "id" {
+		thousandsSep, decimalSep = ".", ","
+	}
+
+	negative := strings.HasPrefix(formatted, "-")
+	formatted = strings.TrimPrefix(formatted, "-")
+
+	intPart, fracPart := formatted, ""
+	if i := strings.IndexByte(formatted, '.'); i >= 0 {
+		intPart, fracPart = formatted[:i], formatted[i+1:]
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(thousandsSep)
+		}
+		b.WriteRune(digit)
+	}
+	if fracPart != "" {
+		b.WriteString(decimalSep)
+		b.WriteString(fracPart)
+	}
+
+	return b.String()
+}
+
+// FormatDate formats a date in the conventional short form of the given language
+func FormatDate(t time.Time, lang string) string {
+	if lang == "id" {
+		return t.Format("02/01/2006")
+	}
+	return t.Format("2006-01-02")
+}
+
+// CurrencySymbol returns the display symbol of an ISO 4217 currency code
+func CurrencySymbol(currency string) string {
+	switch strings.ToUpper(currency) {
+	case "IDR":
+		return "Rp"
+	case "USD":
+		return "$"
+	case "EUR":
+		return "€"
+	case "SGD":
+		return "S$"
+	case "MYR":
+		return "RM"
+	default:
+		return strings.ToUpper(currency)
+	}
+}
+
+// ParseNumber parses numbers written with thousands separators and an optional
+// "Rp" prefix, e.g. "Rp 1.234.567,89" with decimal separator ","
+func ParseNumber(raw, decimalSeparator string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 2 && strings.EqualFold(raw[:2], "rp") {
+		raw = raw[2:]
+	}
+	raw = strings.ReplaceAll(raw, " ", "")
+
+	if decimalSeparator == "," {
+		raw = strings.ReplaceAll(raw, ".", "")
+		raw = strings.ReplaceAll(raw, ",", ".")
+	} else {
+		raw = strings.ReplaceAll(raw, ",", "")
+	}
+
+	return strconv.ParseFloat(raw, 64)
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value    float64
+		decimals int
+		lang     string
+		want     string
+	}{
+		{1234567.891, 2, "id", "1.234.567,89"},
+		{1234567.891, 2, "en", "1,234,567.89"},
+		{-1045000, 0, "id", "-1.045.000"},
+		{999, 3, "en", "999.000"},
+		{0.5, 1, "id", "0,5"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatNumber(tt.value, tt.decimals, tt.lang); got != tt.want {
+			t.Errorf("FormatNumber(%v, %d, %s) = %s, want %s", tt.value, tt.decimals, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	value, err := decimal.Parse("-1299837.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FormatDecimal(value, 2, "id"); got != "-1.299.837,50" {
+		t.Errorf("FormatDecimal id = %s, want -1.299.837,50", got)
+	}
+	if got := FormatDecimal(value, 2, "en"); got != "-1,299,837.50" {
+		t.Errorf("FormatDecimal en = %s, want -1,299,837.50", got)
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	if got := FormatDate(date, "id"); got != "05/03/2024" {
+		t.Errorf("FormatDate id = %s, want 05/03/2024", got)
+	}
+	if got := FormatDate(date, "en"); got != "2024-03-05" {
+		t.Errorf("FormatDate en = %s, want 2024-03-05", got)
+	}
+}
+
+func TestCurrencySymbol(t *testing.T) {
+	tests := map[string]string{"IDR": "Rp", "usd": "$", "EUR": "€", "SGD": "S$", "MYR": "RM", "jpy": "JPY"}
+
+	for currency, want := range tests {
+		if got := CurrencySymbol(currency); got != want {
+			t.Errorf("CurrencySymbol(%s) = %s, want %s", currency, got, want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		raw              string
+		decimalSeparator string
+		want             float64
+	}{
+		{"Rp 1.234.567,89", ",", 1234567.89},
+		{"rp1.045.000", ",", 1045000},
+		{"1,234,567.89", ".", 1234567.89},
+		{" 0,5 ", ",", 0.5},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.raw, tt.decimalSeparator)
+		if err != nil {
+			t.Errorf("ParseNumber(%q): %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseNumber(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+
+	if _, err := ParseNumber("abc", ","); err == nil {
+		t.Error("ParseNumber(\"abc\") returned no error")
+	}
+}
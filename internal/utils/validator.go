@@ -11,9 +11,23 @@ type CustomValidator struct {
 	validator *validator.Validate
 }
 
+// brandLookup backs the "brand" validation tag, see SetBrandLookup
+var brandLookup func(value string) bool
+
+// SetBrandLookup sets how the "brand" validation tag checks that a value names
+// an active brand. Brands live in the database, so the lookup is wired up at startup.
+func SetBrandLookup(fn func(value string) bool) {
+	brandLookup = fn
+}
+
 func NewValidator() *CustomValidator {
+	v := validator.New()
+	v.RegisterValidation("brand", func(fl validator.FieldLevel) bool {
+		return brandLookup != nil && brandLookup(fl.Field().String())
+	})
+
 	return &CustomValidator{
-		validator: validator.New(),
+		validator: v,
 	}
 }
 
@@ -60,6 +74,8 @@ func getErrorMessage(e validator.FieldError) string {
 		return e.Field() + " must be less than or equal to " + e.Param()
 	case "gt":
 		return e.Field() + " must be greater than " + e.Param()
+	case "brand":
+		return e.Field() + " must be an available brand"
 	default:
 		return e.Field() + " is invalid"
 	}
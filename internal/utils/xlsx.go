@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// XLSX cell styles. Styles from XLSXStyleCustom onwards map to the number
+// formats passed to NewXLSXWriter, in order.
+const (
+	XLSXStyleDefault = 0
+	XLSXStyleHeader  = 1
+	XLSXStyleCustom  = 2
+)
+
+// XLSXCell is a single spreadsheet cell. Value may be a string, float64, int or time.Time.
+type XLSXCell struct {
+	Value interface{}
+	Style int
+}
+
+// XLSXWriter streams a single-sheet Office Open XML workbook row by row,
+// so large exports never have to be held in memory.
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	row   int
+}
+
+func NewXLSXWriter(w io.Writer, sheetName string, numberFormats []string) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles(numberFormats)},
+	}
+	for _, part := range parts {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{zw: zw, sheet: sheet}, nil
+}
+
+func (x *XLSXWriter) WriteRow(cells []XLSXCell) error {
+	x.row++
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<row r="%d">`, x.row)
+	for i, cell := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		switch v := cell.Value.(type) {
+		case nil:
+			continue
+		case float64:
+			fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.Style, strconv.FormatFloat(v, 'f', -1, 64))
+		case int:
+			fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, cell.Style, v)
+		case decimal.Decimal:
+			fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.Style, v.String())
+		case time.Time:
+			fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, cell.Style, xlsxDateSerial(v))
+		default:
+			fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, cell.Style, xmlEscape(fmt.Sprint(v)))
+		}
+	}
+	b.WriteString(`</row>`)
+
+	_, err := x.sheet.Write(b.Bytes())
+	return err
+}
+
+// Close finishes the sheet and the workbook archive. It does not close the underlying writer.
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// xlsxColumn converts a zero-based column index to its letter reference (0 -> A, 26 -> AA)
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxDateSerial converts a date to the spreadsheet day count since 1899-12-30
+func xlsxDateSerial(t time.Time) int {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(epoch).Hours() / 24)
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func xlsxStyles(numberFormats []string) string {
+	var numFmts, xfs bytes.Buffer
+	for i, format := range numberFormats {
+		fmt.Fprintf(&numFmts, `<numFmt numFmtId="%d" formatCode="%s"/>`, 164+i, xmlEscape(format))
+		fmt.Fprintf(&xfs, `<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`, 164+i)
+	}
+
+	return xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		fmt.Sprintf(`<numFmts count="%d">%s</numFmts>`, len(numberFormats), numFmts.String()) +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		fmt.Sprintf(`<cellXfs count="%d">`, XLSXStyleCustom+len(numberFormats)) +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		xfs.String() +
+		`</cellXfs></styleSheet>`
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
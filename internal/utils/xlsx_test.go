@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"nabung-emas-api/internal/decimal"
+)
+
+// readXLSXPart returns the content of a part of the workbook archive
+func readXLSXPart(t *testing.T, workbook []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	t.Fatalf("workbook has no part %s", name)
+	return ""
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	xw, err := NewXLSXWriter(&buf, "Transaksi & Co", []string{"dd/mm/yyyy", `"Rp "#,##0.00`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weight, err := decimal.Parse("1.235")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]XLSXCell{
+		{{Value: "Date", Style: XLSXStyleHeader}, {Value: "Weight", Style: XLSXStyleHeader}},
+		{
+			{Value: time.Date(2024, time.January, 1, 15, 0, 0, 0, time.UTC), Style: XLSXStyleCustom},
+			{Value: weight},
+			{Value: 1052500.5, Style: XLSXStyleCustom + 1},
+			{Value: 3},
+			{Value: nil},
+			{Value: "<Antam>"},
+		},
+	}
+	for _, row := range rows {
+		if err := xw.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		part := readXLSXPart(t, buf.Bytes(), name)
+		if err := xml.Unmarshal([]byte(part), new(interface{})); err != nil {
+			t.Errorf("%s is not well-formed XML: %v", name, err)
+		}
+	}
+
+	if workbook := readXLSXPart(t, buf.Bytes(), "xl/workbook.xml"); !strings.Contains(workbook, `name="Transaksi &amp; Co"`) {
+		t.Errorf("workbook does not name the sheet: %s", workbook)
+	}
+
+	sheet := readXLSXPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	wantCells := []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Date</t></is></c>`,
+		// 2024-01-01 is day 45292 counted from 1899-12-30; the time of day is dropped
+		`<c r="A2" s="2"><v>45292</v></c>`,
+		`<c r="B2" s="0"><v>1.235</v></c>`,
+		`<c r="C2" s="3"><v>1052500.5</v></c>`,
+		`<c r="D2" s="0"><v>3</v></c>`,
+		`<c r="F2" s="0" t="inlineStr"><is><t xml:space="preserve">&lt;Antam&gt;</t></is></c>`,
+	}
+	for _, cell := range wantCells {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("sheet is missing cell %s", cell)
+		}
+	}
+	if strings.Contains(sheet, `r="E2"`) {
+		t.Error("sheet has a cell for a nil value")
+	}
+
+	styles := readXLSXPart(t, buf.Bytes(), "xl/styles.xml")
+	if !strings.Contains(styles, `<numFmt numFmtId="165" formatCode="&#34;Rp &#34;#,##0.00"/>`) || !strings.Contains(styles, `<cellXfs count="4">`) {
+		t.Errorf("styles do not hold the number formats: %s", styles)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+
+	for i, want := range tests {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
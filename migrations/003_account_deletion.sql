@@ -0,0 +1,6 @@
+-- Soft delete for user accounts.
+-- Accounts stay recoverable (by logging in) until the grace period ends,
+-- after which the purge job deletes the row and all cascaded data.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Personal data export jobs
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    file_key TEXT,
+    file_size BIGINT,
+    error TEXT,
+    expires_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id);
+CREATE INDEX idx_data_exports_status ON data_exports(status);
+CREATE INDEX idx_data_exports_expires_at ON data_exports(expires_at);
+
+CREATE TRIGGER update_data_exports_updated_at BEFORE UPDATE ON data_exports
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,49 @@
+-- Recurring purchase plans (regular savings schedules per pocket)
+CREATE TABLE recurring_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pocket_id UUID NOT NULL REFERENCES pockets(id) ON DELETE CASCADE,
+    brand VARCHAR(50) NOT NULL,
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('weekly', 'monthly')),
+    amount_type VARCHAR(20) NOT NULL CHECK (amount_type IN ('rupiah', 'grams')),
+    amount DECIMAL(15, 3) NOT NULL CHECK (amount > 0),
+    price_source VARCHAR(50),
+    start_date DATE NOT NULL,
+    end_date DATE,
+    next_due_date DATE,
+    auto_post BOOLEAN DEFAULT FALSE,
+    active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_plan_period CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+-- Purchases generated by plans on each due date, waiting for confirmation
+CREATE TABLE planned_purchases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    plan_id UUID NOT NULL REFERENCES recurring_plans(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pocket_id UUID NOT NULL REFERENCES pockets(id) ON DELETE CASCADE,
+    due_date DATE NOT NULL,
+    weight DECIMAL(10, 3),
+    price_per_gram DECIMAL(15, 2),
+    total_price DECIMAL(15, 2),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'skipped', 'missed')),
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    reminded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_planned_purchase_per_due_date UNIQUE(plan_id, due_date)
+);
+
+CREATE INDEX idx_recurring_plans_user_id ON recurring_plans(user_id);
+CREATE INDEX idx_recurring_plans_next_due_date ON recurring_plans(next_due_date) WHERE active = TRUE;
+CREATE INDEX idx_planned_purchases_user_id ON planned_purchases(user_id);
+CREATE INDEX idx_planned_purchases_status ON planned_purchases(status, due_date);
+CREATE INDEX idx_gold_prices_source_date ON gold_prices(source, date);
+
+CREATE TRIGGER update_recurring_plans_updated_at BEFORE UPDATE ON recurring_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_planned_purchases_updated_at BEFORE UPDATE ON planned_purchases
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,5 @@
+-- Savings goals: deadline and rupiah target on pockets (target_weight already holds the grams target)
+ALTER TABLE pockets ADD COLUMN target_amount DECIMAL(15, 2);
+ALTER TABLE pockets ADD COLUMN target_date DATE;
+
+ALTER TABLE pockets ADD CONSTRAINT positive_target_amount CHECK (target_amount IS NULL OR target_amount > 0);
@@ -0,0 +1,21 @@
+-- Zakat: configurable nisab, jewelry-for-use exemption and history of zakat paid
+ALTER TABLE user_settings ADD COLUMN zakat_nisab_grams DECIMAL(10, 3) DEFAULT 85;
+
+-- Jewelry worn for personal use is exempt from zakat in the majority opinion
+ALTER TABLE pockets ADD COLUMN zakat_exempt BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE zakat_payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    paid_at DATE NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    weight DECIMAL(10, 3) CHECK (weight IS NULL OR weight > 0),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_zakat_payments_user_id ON zakat_payments(user_id, paid_at);
+
+CREATE TRIGGER update_zakat_payments_updated_at BEFORE UPDATE ON zakat_payments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
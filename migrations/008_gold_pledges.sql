@@ -0,0 +1,29 @@
+-- Gold pawn (gadai) and gold-backed loans: pledged gold stays owned but is not available
+CREATE TABLE gold_pledges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pocket_id UUID NOT NULL REFERENCES pockets(id) ON DELETE CASCADE,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    lender VARCHAR(100) NOT NULL,
+    weight DECIMAL(10, 3) NOT NULL CHECK (weight > 0),
+    loan_principal DECIMAL(15, 2) NOT NULL CHECK (loan_principal > 0),
+    fee_rate DECIMAL(6, 3) NOT NULL DEFAULT 0 CHECK (fee_rate >= 0),
+    fee_period_days INTEGER NOT NULL DEFAULT 15 CHECK (fee_period_days > 0),
+    pledged_at DATE NOT NULL,
+    due_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'redeemed')),
+    redeemed_at DATE,
+    redemption_amount DECIMAL(15, 2),
+    notes TEXT,
+    reminded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_pledge_period CHECK (due_date >= pledged_at)
+);
+
+CREATE INDEX idx_gold_pledges_user_id ON gold_pledges(user_id);
+CREATE INDEX idx_gold_pledges_pocket_id ON gold_pledges(pocket_id) WHERE status = 'active';
+CREATE INDEX idx_gold_pledges_due_date ON gold_pledges(due_date) WHERE status = 'active';
+
+CREATE TRIGGER update_gold_pledges_updated_at BEFORE UPDATE ON gold_pledges
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
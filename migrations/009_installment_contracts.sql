@@ -0,0 +1,47 @@
+-- Installment gold purchases (cicilan emas): the gold is owned only after the last payment
+CREATE TABLE installment_contracts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pocket_id UUID NOT NULL REFERENCES pockets(id) ON DELETE CASCADE,
+    provider VARCHAR(100) NOT NULL,
+    brand VARCHAR(50) NOT NULL,
+    weight DECIMAL(10, 3) NOT NULL CHECK (weight > 0),
+    price_per_gram DECIMAL(15, 2) NOT NULL CHECK (price_per_gram > 0),
+    down_payment DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (down_payment >= 0),
+    tenor_months INTEGER NOT NULL CHECK (tenor_months > 0),
+    margin_amount DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (margin_amount >= 0),
+    monthly_installment DECIMAL(15, 2) NOT NULL CHECK (monthly_installment > 0),
+    contract_date DATE NOT NULL,
+    first_due_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled')),
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Payment schedule, one row per month of the tenor
+CREATE TABLE installment_payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    contract_id UUID NOT NULL REFERENCES installment_contracts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    due_date DATE NOT NULL,
+    amount_due DECIMAL(15, 2) NOT NULL,
+    paid_amount DECIMAL(15, 2),
+    paid_at DATE,
+    reminded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_installment_sequence UNIQUE(contract_id, sequence)
+);
+
+CREATE INDEX idx_installment_contracts_user_id ON installment_contracts(user_id);
+CREATE INDEX idx_installment_payments_contract_id ON installment_payments(contract_id);
+CREATE INDEX idx_installment_payments_due_date ON installment_payments(due_date) WHERE paid_at IS NULL;
+
+CREATE TRIGGER update_installment_contracts_updated_at BEFORE UPDATE ON installment_contracts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_installment_payments_updated_at BEFORE UPDATE ON installment_payments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,24 @@
+-- Physical bar inventory: individual pieces with certificate serials and storage location
+CREATE TABLE inventory_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pocket_id UUID NOT NULL REFERENCES pockets(id) ON DELETE CASCADE,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    brand VARCHAR(50) NOT NULL,
+    serial_number VARCHAR(50),
+    piece_weight DECIMAL(10, 3) NOT NULL CHECK (piece_weight > 0),
+    packaging VARCHAR(50),
+    storage_location VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A certificate serial identifies one bar of a brand
+CREATE UNIQUE INDEX idx_inventory_items_serial ON inventory_items(user_id, brand, serial_number)
+    WHERE serial_number IS NOT NULL;
+CREATE INDEX idx_inventory_items_user_id ON inventory_items(user_id);
+CREATE INDEX idx_inventory_items_transaction_id ON inventory_items(transaction_id);
+
+CREATE TRIGGER update_inventory_items_updated_at BEFORE UPDATE ON inventory_items
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
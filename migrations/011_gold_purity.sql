@@ -0,0 +1,50 @@
+-- Gold purity: weight is the gross weight, fine_weight the pure gold content.
+-- Purity is stored as a fraction (1 = fine 24K gold, 0.75 = 18K / 750).
+ALTER TABLE transactions ADD COLUMN purity DECIMAL(5, 4) NOT NULL DEFAULT 1
+    CHECK (purity > 0 AND purity <= 1);
+ALTER TABLE transactions ADD COLUMN fine_weight DECIMAL(10, 3)
+    GENERATED ALWAYS AS (ROUND(weight * purity, 3)) STORED;
+
+ALTER TABLE pockets ADD COLUMN aggregate_fine_weight DECIMAL(10, 3) DEFAULT 0;
+
+-- Keep fine weight in the pocket aggregates
+CREATE OR REPLACE FUNCTION update_pocket_aggregates()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_pocket_id UUID;
+BEGIN
+    -- Determine which pocket to update
+    IF TG_OP = 'DELETE' THEN
+        v_pocket_id := OLD.pocket_id;
+    ELSE
+        v_pocket_id := NEW.pocket_id;
+    END IF;
+
+    -- Update pocket aggregates
+    UPDATE pockets
+    SET 
+        aggregate_total_weight = COALESCE((
+            SELECT SUM(weight) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        aggregate_fine_weight = COALESCE((
+            SELECT SUM(fine_weight) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        aggregate_total_price = COALESCE((
+            SELECT SUM(total_price) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = v_pocket_id;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    ELSE
+        RETURN NEW;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Backfill existing pockets
+UPDATE pockets p
+SET aggregate_fine_weight = COALESCE((
+    SELECT SUM(fine_weight) FROM transactions WHERE pocket_id = p.id
+), 0);
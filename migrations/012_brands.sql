@@ -0,0 +1,47 @@
+-- Gold brands, managed by admins instead of a hard-coded list
+CREATE TABLE brands (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL UNIQUE,
+    logo_url VARCHAR(500),
+    color VARCHAR(50),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_brands_updated_at BEFORE UPDATE ON brands
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO brands (name, color) VALUES
+    ('Antam', 'gold'),
+    ('UBS', 'red'),
+    ('Pegadaian', 'green'),
+    ('King Halim', 'blue'),
+    ('Custom', 'gray');
+
+-- Keep any brand already in use so existing records can be linked
+INSERT INTO brands (name)
+SELECT brand FROM transactions
+UNION SELECT brand FROM recurring_plans
+UNION SELECT brand FROM installment_contracts
+UNION SELECT brand FROM inventory_items
+ON CONFLICT (name) DO NOTHING;
+
+-- Link records to brands. The brand name column is kept for display and
+-- filtering and is renamed together with its brand.
+ALTER TABLE transactions ADD COLUMN brand_id UUID REFERENCES brands(id);
+ALTER TABLE recurring_plans ADD COLUMN brand_id UUID REFERENCES brands(id);
+ALTER TABLE installment_contracts ADD COLUMN brand_id UUID REFERENCES brands(id);
+ALTER TABLE inventory_items ADD COLUMN brand_id UUID REFERENCES brands(id);
+
+UPDATE transactions t SET brand_id = b.id FROM brands b WHERE b.name = t.brand;
+UPDATE recurring_plans rp SET brand_id = b.id FROM brands b WHERE b.name = rp.brand;
+UPDATE installment_contracts ic SET brand_id = b.id FROM brands b WHERE b.name = ic.brand;
+UPDATE inventory_items ii SET brand_id = b.id FROM brands b WHERE b.name = ii.brand;
+
+ALTER TABLE transactions ALTER COLUMN brand_id SET NOT NULL;
+ALTER TABLE recurring_plans ALTER COLUMN brand_id SET NOT NULL;
+ALTER TABLE installment_contracts ALTER COLUMN brand_id SET NOT NULL;
+ALTER TABLE inventory_items ALTER COLUMN brand_id SET NOT NULL;
+
+CREATE INDEX idx_transactions_brand_id ON transactions(brand_id);
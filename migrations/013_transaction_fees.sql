@@ -0,0 +1,66 @@
+-- Itemized fees paid on top of weight x price (PPh 22, printing, certificate, shipping).
+-- transactions.total_fees is kept by the application together with the fee lines,
+-- total_cost is the cost basis used for average price and profit/loss.
+CREATE TABLE transaction_fees (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    fee_type VARCHAR(20) NOT NULL
+        CHECK (fee_type IN ('pph22', 'printing', 'certificate', 'shipping', 'admin', 'other')),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transaction_fees_transaction_id ON transaction_fees(transaction_id);
+
+ALTER TABLE transactions ADD COLUMN total_fees DECIMAL(15, 2) NOT NULL DEFAULT 0
+    CHECK (total_fees >= 0);
+ALTER TABLE transactions ADD COLUMN total_cost DECIMAL(15, 2)
+    GENERATED ALWAYS AS (total_price + total_fees) STORED;
+
+ALTER TABLE pockets ADD COLUMN aggregate_total_cost DECIMAL(15, 2) DEFAULT 0;
+
+-- Keep total cost in the pocket aggregates
+CREATE OR REPLACE FUNCTION update_pocket_aggregates()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_pocket_id UUID;
+BEGIN
+    -- Determine which pocket to update
+    IF TG_OP = 'DELETE' THEN
+        v_pocket_id := OLD.pocket_id;
+    ELSE
+        v_pocket_id := NEW.pocket_id;
+    END IF;
+
+    -- Update pocket aggregates
+    UPDATE pockets
+    SET
+        aggregate_total_weight = COALESCE((
+            SELECT SUM(weight) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        aggregate_fine_weight = COALESCE((
+            SELECT SUM(fine_weight) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        aggregate_total_price = COALESCE((
+            SELECT SUM(total_price) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        aggregate_total_cost = COALESCE((
+            SELECT SUM(total_cost) FROM transactions WHERE pocket_id = v_pocket_id
+        ), 0),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = v_pocket_id;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    ELSE
+        RETURN NEW;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Backfill existing pockets
+UPDATE pockets p
+SET aggregate_total_cost = COALESCE((
+    SELECT SUM(total_cost) FROM transactions WHERE pocket_id = p.id
+), 0);
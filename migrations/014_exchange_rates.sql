@@ -0,0 +1,31 @@
+-- Daily exchange rates, quoted as rupiah per one unit of the foreign currency.
+-- Amounts are stored in rupiah and converted for display at the rate of their date.
+CREATE TABLE exchange_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    date DATE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    rate DECIMAL(15, 4) NOT NULL CHECK (rate > 0),
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_rate_per_date_currency UNIQUE(date, currency)
+);
+
+CREATE INDEX idx_exchange_rates_currency_date ON exchange_rates(currency, date DESC);
+
+-- Converts a rupiah amount at the latest rate on or before on_date. Returns NULL
+-- when no rate is stored yet, which callers check before converting.
+CREATE OR REPLACE FUNCTION idr_to_currency(amount NUMERIC, target VARCHAR, on_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN target = 'IDR' THEN amount
+        ELSE ROUND(amount / (
+            SELECT rate FROM exchange_rates
+            WHERE currency = target AND date <= on_date
+            ORDER BY date DESC
+            LIMIT 1
+        ), 2)
+    END
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE user_settings ADD CONSTRAINT user_settings_currency_check
+    CHECK (currency IN ('IDR', 'USD', 'EUR', 'SGD', 'MYR'));
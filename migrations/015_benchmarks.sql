@@ -0,0 +1,16 @@
+-- Benchmark series to compare gold savings against, imported from CSV files:
+--   bi_rate  Bank Indonesia policy rate in percent a year, effective from its date
+--   cpi      consumer price index
+--   lq45     LQ45 stock index close
+--   usd_idr  rupiah per US dollar
+CREATE TABLE benchmark_values (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    benchmark VARCHAR(20) NOT NULL CHECK (benchmark IN ('bi_rate', 'cpi', 'lq45', 'usd_idr')),
+    date DATE NOT NULL,
+    value DECIMAL(18, 4) NOT NULL CHECK (value >= 0),
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_benchmark_value_per_date UNIQUE(benchmark, date)
+);
+
+CREATE INDEX idx_benchmark_values_benchmark_date ON benchmark_values(benchmark, date);
@@ -0,0 +1,21 @@
+-- End-of-day snapshots of each portfolio and pocket, valued at the gold price stored
+-- for that day. Rows without a pocket are the totals of the user's portfolio. Gold
+-- price and market value are NULL on days without a recent stored price.
+CREATE TABLE portfolio_snapshots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pocket_id UUID REFERENCES pockets(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    weight DECIMAL(10, 3) NOT NULL,
+    fine_weight DECIMAL(10, 3) NOT NULL,
+    total_cost DECIMAL(15, 2) NOT NULL,
+    gold_price DECIMAL(15, 2),
+    market_value DECIMAL(15, 2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_portfolio_snapshots_portfolio_date
+    ON portfolio_snapshots(user_id, date) WHERE pocket_id IS NULL;
+CREATE UNIQUE INDEX idx_portfolio_snapshots_pocket_date
+    ON portfolio_snapshots(pocket_id, date) WHERE pocket_id IS NOT NULL;
+CREATE INDEX idx_portfolio_snapshots_date ON portfolio_snapshots(date);
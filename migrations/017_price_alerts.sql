@@ -0,0 +1,52 @@
+-- User-defined gold price alerts, evaluated whenever new gold prices are stored.
+--   price_above / price_below  threshold is a price per gram in rupiah
+--   daily_change               threshold is a change in percent from the previous price
+--   below_average_cost         price falls below the user's average cost per fine gram,
+--                              of one brand when brand_id is set
+-- An alert fires at most once per price date and not again within its cooldown.
+CREATE TABLE price_alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alert_type VARCHAR(30) NOT NULL
+        CHECK (alert_type IN ('price_above', 'price_below', 'daily_change', 'below_average_cost')),
+    price_source VARCHAR(50),
+    brand VARCHAR(50),
+    brand_id UUID REFERENCES brands(id),
+    threshold DECIMAL(15, 2) CHECK (threshold > 0),
+    notify_email BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_push BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    cooldown_hours INTEGER NOT NULL DEFAULT 24 CHECK (cooldown_hours BETWEEN 1 AND 720),
+    active BOOLEAN DEFAULT TRUE,
+    last_triggered_at TIMESTAMP,
+    last_price_date DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT price_alert_threshold_required
+        CHECK (alert_type = 'below_average_cost' OR threshold IS NOT NULL)
+);
+
+-- Every time an alert fired, with the channels it was delivered through
+CREATE TABLE price_alert_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    alert_id UUID NOT NULL REFERENCES price_alerts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alert_type VARCHAR(30) NOT NULL,
+    price_date DATE NOT NULL,
+    price_source VARCHAR(50) NOT NULL,
+    price_per_gram DECIMAL(15, 2) NOT NULL,
+    reference_value DECIMAL(15, 2),
+    change_percentage DECIMAL(8, 2),
+    message TEXT NOT NULL,
+    delivered_email BOOLEAN NOT NULL DEFAULT FALSE,
+    delivered_push BOOLEAN NOT NULL DEFAULT FALSE,
+    in_app BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_price_alerts_user_id ON price_alerts(user_id);
+CREATE INDEX idx_price_alerts_active ON price_alerts(price_source) WHERE active = TRUE;
+CREATE INDEX idx_price_alert_events_user_id ON price_alert_events(user_id, created_at DESC);
+
+CREATE TRIGGER update_price_alerts_updated_at BEFORE UPDATE ON price_alerts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
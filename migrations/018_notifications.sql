@@ -0,0 +1,34 @@
+-- In-app notification center, fed by the services that generate events: price alerts,
+-- reached savings goals and reminders. data holds the IDs a client needs to open the
+-- related record.
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL
+        CHECK (type IN ('price_alert', 'goal_reached', 'pledge_due', 'planned_purchase')),
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Newest first, with the ID breaking ties for cursor pagination
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Channels per notification type; types without a row use every channel
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL
+        CHECK (type IN ('price_alert', 'goal_reached', 'pledge_due', 'planned_purchase')),
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    push BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type)
+);
+
+-- When the pocket's savings goal was last seen reached, so it is announced once.
+-- Cleared when the goal is no longer met, e.g. after a sale or a higher target.
+ALTER TABLE pockets ADD COLUMN goal_reached_at TIMESTAMP;
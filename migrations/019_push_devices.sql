@@ -0,0 +1,25 @@
+-- Devices registered for push notifications. token is the FCM registration token,
+-- the APNs device token or the Web Push subscription endpoint; Web Push subscriptions
+-- also carry their p256dh and auth keys. A token belongs to the user who registered it
+-- last. Devices rejected by their provider are removed.
+CREATE TABLE push_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('fcm', 'apns', 'webpush')),
+    token TEXT NOT NULL,
+    p256dh VARCHAR(200),
+    auth VARCHAR(50),
+    language VARCHAR(5) CHECK (language IN ('en', 'id')),
+    name VARCHAR(100),
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT push_device_webpush_keys
+        CHECK (platform <> 'webpush' OR (p256dh IS NOT NULL AND auth IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX idx_push_devices_platform_token ON push_devices(platform, md5(token));
+CREATE INDEX idx_push_devices_user_id ON push_devices(user_id);
+
+CREATE TRIGGER update_push_devices_updated_at BEFORE UPDATE ON push_devices
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,25 @@
+-- Events sent to clients on the live event stream, kept for a day so clients that
+-- reconnect can resume from the last event they received. Events without a user go
+-- to everyone. Every insert is announced on the stream_events channel with the
+-- event's id, so each API instance can forward it to the clients connected to it.
+CREATE TABLE stream_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('gold_price', 'portfolio', 'notification')),
+    data JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stream_events_user_id ON stream_events(user_id, id);
+CREATE INDEX idx_stream_events_created_at ON stream_events(created_at);
+
+CREATE OR REPLACE FUNCTION notify_stream_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('stream_events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notify_stream_events AFTER INSERT ON stream_events
+    FOR EACH ROW EXECUTE FUNCTION notify_stream_event();
@@ -0,0 +1,8 @@
+-- Reminders of installment payments coming due, sent like the pledge reminders
+ALTER TABLE notifications DROP CONSTRAINT notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+    CHECK (type IN ('price_alert', 'goal_reached', 'pledge_due', 'planned_purchase', 'installment_due'));
+
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_type_check
+    CHECK (type IN ('price_alert', 'goal_reached', 'pledge_due', 'planned_purchase', 'installment_due'));